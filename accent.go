@@ -0,0 +1,92 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+)
+
+// AccentColor returns the body color of the monster for the provided hash, to
+// theme the surrounding UI to match the avatar. For other styles than
+// StyleMonster it is the most common color of the figure, and transparent
+// black for unknown styles.
+func AccentColor(hash []byte, opts ...Option) color.RGBA {
+	return AccentColors(hash, opts...)[0]
+}
+
+// AccentColors returns the body color of the monster for the provided hash,
+// followed by a darker shade and a lighter tint of it.
+func AccentColors(hash []byte, opts ...Option) []color.RGBA {
+	o := buildOptions(opts)
+	var c color.RGBA
+	if o.style() != StyleMonster {
+		c = styleColor(hash, o)
+		if c.A == 0 {
+			return []color.RGBA{c, c, c}
+		}
+	} else {
+		c = bodyColor(describeHash(hash, o), o, o.theme().pack())
+	}
+
+	h, s, l := rgbToHsl(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+	return []color.RGBA{c, hslColor(h, s, l*0.6), hslColor(h, s, l+(1-l)*0.5)}
+}
+
+// Helper to find the fill color of the body as rendered, the most common
+// opaque color that isn't part of the dark outline
+func bodyColor(d Descriptor, o Options, p pack) color.RGBA {
+	img, err := preparePart(d, o, "body", lightnessShift(d, o), p, 1, nil)
+	if err != nil {
+		return hslColor(d.Hue, d.Saturation, 0.5)
+	}
+
+	best := dominantColor(img)
+	if tone := o.tone(); tone != ToneNone {
+		px := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		px.SetRGBA(0, 0, best)
+		toneImage(px, tone, o.Duotone)
+		best = px.RGBAAt(0, 0)
+	}
+
+	return best
+}
+
+// Helper to find the most common color of the figure of a style other than
+// StyleMonster at the native size, transparent black for unknown styles
+func styleColor(hash []byte, o Options) color.RGBA {
+	figure, err := styleFigure(o.style())
+	if err != nil {
+		return color.RGBA{}
+	}
+	layer, err := styleLayer(hash, nativeSize, figure, o)
+	if err != nil {
+		return color.RGBA{}
+	}
+	defer putRGBA(layer)
+
+	return dominantColor(layer)
+}
+
+// Helper to find the most common opaque color of img that isn't part of a
+// dark outline
+func dominantColor(img *image.RGBA) color.RGBA {
+	counts := make(map[color.RGBA]int)
+	var best color.RGBA
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.RGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+		if c.A != 0xff || max(c.R, c.G, c.B) < 64 {
+			continue
+		}
+		counts[c]++
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+
+	return best
+}
+
+// Helper to convert an HSL color to opaque RGBA
+func hslColor(h, s, l float64) color.RGBA {
+	r, g, b := hslToRgb(h, s, l)
+	return color.RGBA{R: clampUint8(r * 255), G: clampUint8(g * 255), B: clampUint8(b * 255), A: 0xff}
+}
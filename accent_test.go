@@ -0,0 +1,74 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestAccentColor(t *testing.T) {
+	hash := []byte("accent-color")
+
+	c := AccentColor(hash)
+	if c.A != 0xff {
+		t.Fatalf("Expected an opaque color, got %v", c)
+	}
+
+	// The accent is the colorized body, so it has the descriptor's hue
+	d := Describe(hash)
+	h, _, _ := rgbToHsl(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+	if diff := math.Min(math.Abs(h-d.Hue), 1-math.Abs(h-d.Hue)); diff > 0.02 {
+		t.Errorf("Expected hue %.3f, got %.3f", d.Hue, h)
+	}
+}
+
+func TestAccentColors(t *testing.T) {
+	colors := AccentColors([]byte("accent-colors"))
+	if len(colors) != 3 {
+		t.Fatalf("Expected 3 colors, got %d", len(colors))
+	}
+
+	l0, l1, l2 := relativeLuminance(colors[0]), relativeLuminance(colors[1]), relativeLuminance(colors[2])
+	if !(l1 < l0 && l0 < l2) {
+		t.Errorf("Expected a darker shade and a lighter tint, got %v", colors)
+	}
+}
+
+func TestAccentColorGreyscale(t *testing.T) {
+	c := AccentColor([]byte("accent-grey"), WithGreyscale())
+	if c.R != c.G || c.G != c.B {
+		t.Errorf("Expected a grey accent, got %v", c)
+	}
+}
+
+func TestAccentColorOriginal(t *testing.T) {
+	// Body 7 is a red heart without colorization
+	d := Descriptor{Legs: 1, Hair: 1, Arms: 1, Body: 7, Eyes: 1, Mouth: 1, LegsHue: -1, ArmsHue: -1}
+	c := bodyColor(d, Options{}, pack{load: loadPart})
+	if c.R < 2*c.G || c.R < 2*c.B {
+		t.Errorf("Expected a red body, got %v", c)
+	}
+}
+
+func TestAccentColorStyles(t *testing.T) {
+	hash := []byte("accent-styles")
+
+	c := AccentColor(hash, WithStyle(StyleInitials))
+	if c.A != 0xff {
+		t.Fatalf("Expected an opaque color, got %v", c)
+	}
+	if c == AccentColor(hash) {
+		t.Errorf("Expected the color of the initials, got the monster's %v", c)
+	}
+
+	// The initials are drawn on a square of the accent color
+	img := New(hash, WithStyle(StyleInitials), WithSize(nativeSize)).(*image.RGBA)
+	if corner := img.RGBAAt(1, 1); corner != c {
+		t.Errorf("Expected the background %v, got %v", corner, c)
+	}
+
+	if c := AccentColor(hash, WithStyle("plush")); c != (color.RGBA{}) {
+		t.Errorf("Expected no color for an unknown style, got %v", c)
+	}
+}
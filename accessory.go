@@ -0,0 +1,63 @@
+package monsterid
+
+import (
+	"fmt"
+	"image"
+	"path"
+	"slices"
+)
+
+// Accessory is an item drawn over the monster, such as a hat or a badge.
+type Accessory string
+
+const (
+	AccessoryNone       Accessory = ""
+	AccessoryTopHat     Accessory = "top-hat"
+	AccessoryCap        Accessory = "cap"
+	AccessoryCrown      Accessory = "crown"
+	AccessoryPartyHat   Accessory = "party-hat"
+	AccessoryGlasses    Accessory = "glasses"
+	AccessorySunglasses Accessory = "sunglasses"
+	AccessoryBowTie     Accessory = "bow-tie"
+	AccessoryModerator  Accessory = "moderator" // badge, only drawn if set in Options.Accessory
+	AccessoryVerified   Accessory = "verified"  // badge, only drawn if set in Options.Accessory
+)
+
+// accessoryChance is the probability of a monster getting an accessory with
+// Options.Accessories.
+const accessoryChance = 0.25
+
+// randomAccessories can be selected by hash, badges carry a meaning and are
+// only drawn on request.
+var randomAccessories = []Accessory{
+	AccessoryTopHat, AccessoryCap, AccessoryCrown, AccessoryPartyHat,
+	AccessoryGlasses, AccessorySunglasses, AccessoryBowTie,
+}
+
+// Accessories returns all accessories.
+func Accessories() []Accessory {
+	return append(slices.Clone(randomAccessories), AccessoryModerator, AccessoryVerified)
+}
+
+// Helper to check that the accessory exists
+func (a Accessory) validate() error {
+	if a != AccessoryNone && !slices.Contains(Accessories(), a) {
+		return fmt.Errorf("monsterid: unknown accessory %q", a)
+	}
+
+	return nil
+}
+
+// Helper to load the image of an accessory, which is the same for all themes
+func loadAccessory(a Accessory, scale int) (*image.RGBA, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	img, err := loadEmbeddedPart(path.Join("parts", "accessories"), string(a)+".png", scale)
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: load accessory %s: %w", a, err)
+	}
+
+	return img, nil
+}
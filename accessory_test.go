@@ -0,0 +1,135 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAccessoryChangesImage(t *testing.T) {
+	hash := []byte("accessory-image")
+	plain := New(hash).(*image.RGBA)
+
+	for _, a := range Accessories() {
+		img, err := NewWithError(hash, WithAccessory(a))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if bytes.Equal(plain.Pix, img.(*image.RGBA).Pix) {
+			t.Errorf("Accessory %s did not change the image", a)
+		}
+	}
+}
+
+func TestRandomAccessories(t *testing.T) {
+	const n = 400
+	with := 0
+	for i := 0; i < n; i++ {
+		hash := []byte(fmt.Sprintf("accessory-%d", i))
+
+		d := Describe(hash, WithAccessories())
+		if d.Accessory == AccessoryNone {
+			continue
+		}
+		with++
+		if !slices.Contains(randomAccessories, d.Accessory) {
+			t.Fatalf("Unexpected random accessory %s", d.Accessory)
+		}
+
+		// Only the accessory is added to the monster
+		plain := Describe(hash)
+		d.Accessory = AccessoryNone
+		if d != plain {
+			t.Fatalf("Expected %+v, got %+v", plain, d)
+		}
+	}
+
+	if ratio := float64(with) / n; ratio < 0.15 || ratio > 0.35 {
+		t.Errorf("Expected about %g of monsters with an accessory, got %g", accessoryChance, ratio)
+	}
+}
+
+func TestForcedAccessory(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		hash := []byte(fmt.Sprintf("accessory-forced-%d", i))
+		d := Describe(hash, WithAccessories(), WithAccessory(AccessoryVerified))
+		if d.Accessory != AccessoryVerified {
+			t.Fatalf("Expected accessory %s, got %s", AccessoryVerified, d.Accessory)
+		}
+	}
+}
+
+func TestUnknownAccessory(t *testing.T) {
+	_, err := NewWithError([]byte("accessory-unknown"), WithAccessory("monocle"))
+	if err == nil || !strings.Contains(err.Error(), "monocle") {
+		t.Errorf("Expected an unknown accessory error, got %v", err)
+	}
+
+	d := Describe([]byte("accessory-unknown"))
+	d.Accessory = "monocle"
+	if err := d.validate(nil); err == nil {
+		t.Error("Expected an error for an unknown accessory")
+	}
+}
+
+func TestAccessoryNotMirrored(t *testing.T) {
+	// Find a mirrored monster
+	var hash []byte
+	for i := 0; hash == nil; i++ {
+		h := []byte(fmt.Sprintf("accessory-mirror-%d", i))
+		if Describe(h, WithAlgorithmVersion(V2)).Mirrored {
+			hash = h
+		}
+	}
+
+	badge, err := loadAccessory(AccessoryVerified, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The badge is opaque where drawn, so it shows unmirrored on top
+	img := New(hash, WithAlgorithmVersion(V2), WithAccessory(AccessoryVerified)).(*image.RGBA)
+	b := badge.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if badge.RGBAAt(x, y).A != 0xff {
+				continue
+			}
+			if got, want := img.RGBAAt(x, y), badge.RGBAAt(x, y); got != want {
+				t.Fatalf("Expected %v at %d,%d, got %v", want, x, y, got)
+			}
+		}
+	}
+}
+
+func TestAccessoryRoundTrip(t *testing.T) {
+	hash := []byte("accessory-round-trip")
+	o := []Option{WithAccessory(AccessoryCrown), WithMetadata()}
+
+	var buf bytes.Buffer
+	if err := EncodePNG(&buf, hash, o...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	d, err := ParsePNG(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d.Accessory != AccessoryCrown {
+		t.Errorf("Expected accessory %s, got %s", AccessoryCrown, d.Accessory)
+	}
+
+	if id := ID(hash, o...); !strings.HasSuffix(id, "-crown") {
+		t.Errorf("Expected an ID ending in -crown, got %s", id)
+	}
+
+	var svg bytes.Buffer
+	if err := EncodeSVG(&svg, hash, o...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(svg.String(), `<g id="accessory">`) {
+		t.Error("Expected the accessory in the SVG")
+	}
+}
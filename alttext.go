@@ -0,0 +1,103 @@
+package monsterid
+
+import (
+	"fmt"
+)
+
+// colorNames are plain names for twelve evenly spaced hues, starting at red.
+var colorNames = []string{
+	"red", "orange", "yellow", "lime", "green", "mint",
+	"teal", "sky blue", "blue", "purple", "magenta", "pink",
+}
+
+// bodyColors are the colors of the body artwork before colorization.
+var bodyColors = []string{
+	"lime", "olive", "light blue", "lavender", "pink",
+	"yellow", "red", "green", "brown", "blue",
+	"lavender", "red", "red", "red", "red",
+}
+
+// bodyShapes describe the outline of each body part, empty for plain blobs.
+var bodyShapes = []string{
+	"", "lumpy", "square", "fluffy", "triangular",
+	"round", "heart-shaped", "octagonal", "hourglass-shaped", "oval",
+	"X-shaped", "", "cross-shaped", "", "star-shaped",
+}
+
+// eyesDescriptions describe each eyes part.
+var eyesDescriptions = []string{
+	"two round eyes", "two mismatched eyes", "one red eye", "crossed-out eyes", "angry eyes",
+	"a winking eye", "a winking eye", "three eyes", "closed eyes", "spiral eyes",
+	"big oval eyes", "a red visor", "eyes on stalks", "a cluster of eyes", "four stacked eyes",
+}
+
+// hairDescriptions describe each hair part.
+var hairDescriptions = []string{
+	"spiky hair", "two antennae", "curly hair", "an antenna", "zigzag hair",
+}
+
+// mouthDescriptions describe each mouth part.
+var mouthDescriptions = []string{
+	"a smile", "a small round mouth", "its tongue out", "pink lips", "fangs",
+	"an open mouth", "a beak", "jagged teeth", "a crooked mouth", "a flat mouth",
+}
+
+// AltText returns a short description of the monster for the provided hash,
+// such as "green round monster with three eyes, curly hair and fangs", to use
+// as the alt attribute of the image. Other themes than ThemeClassic only get
+// their color described, and other styles than StyleMonster are named, as in
+// "identicon avatar" or "avatar with the initials AL".
+func AltText(hash []byte, opts ...Option) string {
+	o := buildOptions(opts)
+	if s := o.style(); s != StyleMonster {
+		if letters := initialsOf(o.Initials); s == StyleInitials && letters != "" {
+			return "avatar with the initials " + letters
+		}
+		return string(s) + " avatar"
+	}
+
+	d := describeHash(hash, o)
+
+	// The descriptions below are of the classic parts
+	if o.theme() != ThemeClassic {
+		if c := bodyColorName(d, o); c != "" {
+			return c + " monster"
+		}
+		return "monster"
+	}
+
+	subject := "monster"
+	if shape := bodyShapes[(d.Body-1)%len(bodyShapes)]; shape != "" {
+		subject = shape + " " + subject
+	}
+	if c := bodyColorName(d, o); c != "" {
+		subject = c + " " + subject
+	}
+
+	return fmt.Sprintf("%s with %s, %s and %s", subject,
+		eyesDescriptions[(d.Eyes-1)%len(eyesDescriptions)],
+		hairDescriptions[(d.Hair-1)%len(hairDescriptions)],
+		mouthDescriptions[(d.Mouth-1)%len(mouthDescriptions)],
+	)
+}
+
+// Helper to name the color of the body as rendered, empty for duotone
+func bodyColorName(d Descriptor, o Options) string {
+	switch o.tone() {
+	case ToneGreyscale:
+		return "grey"
+	case ToneSepia:
+		return "sepia"
+	case ToneDuotone:
+		return ""
+	}
+
+	if !o.Artistic {
+		if o.theme() != ThemeClassic {
+			return ""
+		}
+		return bodyColors[(d.Body-1)%len(bodyColors)]
+	}
+
+	return colorNames[hueIndex(d.Hue, len(colorNames))]
+}
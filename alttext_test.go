@@ -0,0 +1,79 @@
+package monsterid
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestAltText(t *testing.T) {
+	hash := []byte("alt-text")
+
+	text := AltText(hash)
+	if text != AltText(hash) {
+		t.Fatal("AltText is not deterministic")
+	}
+
+	d := Describe(hash)
+	for _, want := range []string{
+		colorNames[hueIndex(d.Hue, len(colorNames))],
+		eyesDescriptions[d.Eyes-1],
+		hairDescriptions[d.Hair-1],
+		mouthDescriptions[d.Mouth-1],
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q to mention %q", text, want)
+		}
+	}
+}
+
+func TestAltTextColor(t *testing.T) {
+	hash := []byte("alt-text-color")
+
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{[]Option{WithGreyscale()}, "grey "},
+		{[]Option{WithSepia()}, "sepia "},
+		{[]Option{WithArtistic(false)}, bodyColors[Describe(hash).Body-1] + " "},
+	}
+
+	for _, test := range tests {
+		if got := AltText(hash, test.opts...); !strings.HasPrefix(got, test.want) {
+			t.Errorf("Expected %q to start with %q", got, test.want)
+		}
+	}
+
+	duotone := AltText(hash, WithDuotone(color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}))
+	if shape := bodyShapes[Describe(hash).Body-1]; !strings.HasPrefix(duotone, strings.TrimSpace(shape+" monster")) {
+		t.Errorf("Expected no color for duotone, got %q", duotone)
+	}
+}
+
+func TestAltTextTables(t *testing.T) {
+	counts := ThemeClassic.pack().counts
+	if len(bodyColors) != counts["body"] || len(bodyShapes) != counts["body"] || len(eyesDescriptions) != counts["eyes"] ||
+		len(hairDescriptions) != counts["hair"] || len(mouthDescriptions) != counts["mouth"] {
+		t.Error("Description tables don't match the number of parts")
+	}
+}
+
+func TestAltTextStyles(t *testing.T) {
+	hash := []byte("alt-text-styles")
+
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{[]Option{WithStyle(StyleIdenticon)}, "identicon avatar"},
+		{[]Option{WithStyle(StyleInitials)}, "initials avatar"},
+		{[]Option{WithStyle(StyleInitials), WithInitials("Ada Lovelace")}, "avatar with the initials AL"},
+	}
+
+	for _, test := range tests {
+		if got := AltText(hash, test.opts...); got != test.want {
+			t.Errorf("Expected %q, got %q", test.want, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"io"
+)
+
+// Animate creates a short looping animation of the monster for the provided
+// hash, in which it blinks and moves its mouth by briefly swapping in other
+// eyes and mouth parts picked from the hash. Other styles than StyleMonster
+// have no parts to swap, so they return an error.
+func Animate(hash []byte, opts ...Option) (*gif.GIF, error) {
+	o := buildOptions(opts)
+	if o.style() != StyleMonster {
+		return nil, fmt.Errorf("monsterid: style %s can't be animated", o.style())
+	}
+	if err := o.checkVersion(); err != nil {
+		return nil, err
+	}
+
+	return animate(hash, o, o.theme().pack())
+}
+
+// Helper to create the animation of the monster for hash with the parts of p
+func animate(hash []byte, o Options, p pack) (*gif.GIF, error) {
+	colors := o.Colors
+	o.Output, o.Colors = OutputRGBA, 0
+
+	type frame struct {
+		d     Descriptor
+		delay int // in 100ths of a second
+	}
+
+	// The alternate parts continue the random stream after the selection,
+	// and packs with a single eyes or mouth part can't blink or talk
+	r := newRand(hash, o)
+	d := describe(r, o, p)
+	frames := []frame{{d, 200}}
+	if eyes := p.counts.count("eyes"); eyes > 1 {
+		blink := d
+		blink.Eyes = (d.Eyes+r.IntN(eyes-1))%eyes + 1
+		frames = append(frames, frame{blink, 15}, frame{d, 100})
+	}
+	if mouth := p.counts.count("mouth"); mouth > 1 {
+		talk := d
+		talk.Mouth = (d.Mouth+r.IntN(mouth-1))%mouth + 1
+		frames = append(frames, frame{talk, 30})
+	}
+
+	anim := &gif.GIF{}
+	for _, f := range frames {
+		img, err := newImage(context.Background(), f.d, o, p)
+		if err != nil {
+			return nil, err
+		}
+		anim.Image = append(anim.Image, palettedGIF(img.(*image.RGBA), colors))
+		anim.Delay = append(anim.Delay, f.delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+
+	return anim, nil
+}
+
+// AnimatedGIF returns the animation created by Animate encoded as GIF.
+func AnimatedGIF(hash []byte, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeAnimatedGIF(buf, hash, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeAnimatedGIF writes the animation created by Animate to w as GIF.
+func EncodeAnimatedGIF(w io.Writer, hash []byte, opts ...Option) error {
+	anim, err := Animate(hash, opts...)
+	if err != nil {
+		return err
+	}
+
+	return gif.EncodeAll(w, anim)
+}
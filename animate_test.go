@@ -0,0 +1,79 @@
+package monsterid
+
+import (
+	"bytes"
+	"image/gif"
+	"strings"
+	"testing"
+)
+
+func TestAnimate(t *testing.T) {
+	anim, err := Animate([]byte("animate-test"), WithSize(64))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(anim.Image) < 2 || len(anim.Image) != len(anim.Delay) {
+		t.Fatalf("Expected several frames with delays, got %d frames and %d delays", len(anim.Image), len(anim.Delay))
+	}
+	if anim.LoopCount != 0 {
+		t.Errorf("Expected an endless loop, got loop count %d", anim.LoopCount)
+	}
+
+	// The blink frame differs from the first frame
+	differs := false
+	b := anim.Image[0].Bounds()
+	for y := b.Min.Y; y < b.Max.Y && !differs; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if anim.Image[0].At(x, y) != anim.Image[1].At(x, y) {
+				differs = true
+				break
+			}
+		}
+	}
+	if !differs {
+		t.Error("Blink frame is identical to the first frame")
+	}
+}
+
+func TestAnimateIsDeterministic(t *testing.T) {
+	hash := []byte("animate-deterministic")
+
+	data1, err := AnimatedGIF(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data2, err := AnimatedGIF(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(data1, data2) {
+		t.Error("Same hash produced different animations")
+	}
+
+	if _, err := gif.DecodeAll(bytes.NewReader(data1)); err != nil {
+		t.Errorf("Failed to decode animated GIF: %v", err)
+	}
+}
+
+func TestAnimateStyle(t *testing.T) {
+	if _, err := Animate([]byte("animate"), WithStyle(StyleWavatar)); err == nil || !strings.Contains(err.Error(), "wavatar") {
+		t.Errorf("Expected an error for a style without parts, got %v", err)
+	}
+}
+
+func TestAnimateSinglePart(t *testing.T) {
+	g, err := NewGeneratorFromFS(testPack(t, 1))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// A pack with one eyes and one mouth part can neither blink nor talk
+	anim, err := animate([]byte("animate-single"), buildOptions([]Option{WithSize(32)}), g.pack())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(anim.Image) != 1 || len(anim.Delay) != 1 {
+		t.Errorf("Expected a single frame, got %d", len(anim.Image))
+	}
+}
@@ -0,0 +1,62 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Helper to draw the background color and image into rect of dst
+func drawBackground(dst draw.Image, rect image.Rectangle, o Options) {
+	// A transparent background leaves dst untouched and an opaque one
+	// replaces it, which is a copy of the color into the pixels of an RGBA
+	if rgba, ok := dst.(*image.RGBA); ok && o.Background.A == 0xff {
+		fillRGBA(rgba, rect, o.Background)
+	} else if o.Background.A > 0 {
+		draw.Draw(dst, rect, &image.Uniform{C: o.Background}, image.Point{}, draw.Over)
+	}
+
+	bg := o.BackgroundImage
+	if bg == nil || bg.Bounds().Empty() {
+		return
+	}
+
+	if o.TileBackground {
+		// Repeat the image at its own size from the top-left corner
+		b := bg.Bounds()
+		for y := rect.Min.Y; y < rect.Max.Y; y += b.Dy() {
+			for x := rect.Min.X; x < rect.Max.X; x += b.Dx() {
+				tile := image.Rect(x, y, x+b.Dx(), y+b.Dy()).Intersect(rect)
+				draw.Draw(dst, tile, bg, b.Min, draw.Over)
+			}
+		}
+		return
+	}
+
+	// Stretch the image over the whole avatar
+	src, ok := bg.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(bg.Bounds())
+		draw.Draw(src, src.Bounds(), bg, bg.Bounds().Min, draw.Src)
+	}
+	if src.Bounds().Dx() != rect.Dx() || src.Bounds().Dy() != rect.Dy() {
+		src = resizeImage(src, rect.Dx(), rect.Dy(), o.Filter.kernel())
+	}
+	draw.Draw(dst, rect, src, src.Bounds().Min, draw.Over)
+}
+
+// Helper to fill rect of dst with c, copying the first row into the others
+func fillRGBA(dst *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	rect = rect.Intersect(dst.Rect)
+	if rect.Empty() {
+		return
+	}
+
+	first := dst.Pix[dst.PixOffset(rect.Min.X, rect.Min.Y):dst.PixOffset(rect.Max.X, rect.Min.Y)]
+	for i := 0; i < len(first); i += 4 {
+		first[i], first[i+1], first[i+2], first[i+3] = c.R, c.G, c.B, c.A
+	}
+	for y := rect.Min.Y + 1; y < rect.Max.Y; y++ {
+		copy(dst.Pix[dst.PixOffset(rect.Min.X, y):], first)
+	}
+}
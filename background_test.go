@@ -0,0 +1,96 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestBackgroundImageIsStretched(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	// Left half red, right half blue
+	bg := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(bg, image.Rect(0, 0, 5, 10), &image.Uniform{C: red}, image.Point{}, draw.Src)
+	draw.Draw(bg, image.Rect(5, 0, 10, 10), &image.Uniform{C: blue}, image.Point{}, draw.Src)
+
+	img := New([]byte("background-image"), WithBackgroundImage(bg), WithSize(200)).(*image.RGBA)
+
+	if c := img.RGBAAt(0, 0); c != red {
+		t.Errorf("Expected red top-left corner, got %v", c)
+	}
+	if c := img.RGBAAt(199, 0); c != blue {
+		t.Errorf("Expected blue top-right corner, got %v", c)
+	}
+}
+
+func TestBackgroundPatternIsTiled(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	// 2x1 tile: red pixel then blue pixel
+	tile := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	tile.SetRGBA(0, 0, red)
+	tile.SetRGBA(1, 0, blue)
+
+	img := New([]byte("background-pattern"), WithBackgroundPattern(tile)).(*image.RGBA)
+
+	for x := 0; x < 6; x++ {
+		want := red
+		if x%2 == 1 {
+			want = blue
+		}
+		if c := img.RGBAAt(x, 0); c != want {
+			t.Errorf("Pixel (%d,0): expected %v, got %v", x, want, c)
+		}
+	}
+}
+
+func TestBackgroundImageOverColor(t *testing.T) {
+	bg := image.NewRGBA(image.Rect(0, 0, 4, 4)) // fully transparent
+	img := New([]byte("background-over"), WithBackgroundImage(bg)).(*image.RGBA)
+
+	if c := img.RGBAAt(0, 0); c != DefaultOptions().Background {
+		t.Errorf("Transparent background image hid the background color: %v", c)
+	}
+}
+
+func TestFillRGBAMatchesDraw(t *testing.T) {
+	c := color.RGBA{R: 12, G: 34, B: 56, A: 255}
+	rects := []image.Rectangle{
+		image.Rect(0, 0, 8, 6),
+		image.Rect(2, 1, 5, 4),
+		image.Rect(-3, 4, 20, 10),
+		image.Rect(9, 9, 12, 12),
+	}
+
+	for _, r := range rects {
+		want := image.NewRGBA(image.Rect(0, 0, 8, 6))
+		want.Pix[0] = 1
+		got := cloneImage(want)
+
+		draw.Draw(want, r, &image.Uniform{C: c}, image.Point{}, draw.Over)
+		fillRGBA(got, r, c)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("Expected filling %v to match draw.Draw", r)
+		}
+	}
+
+	// Sub-images are filled within their bounds only
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	fillRGBA(img.SubImage(image.Rect(1, 1, 3, 3)).(*image.RGBA), image.Rect(0, 0, 4, 4), c)
+	if img.RGBAAt(0, 0) != (color.RGBA{}) || img.RGBAAt(3, 2) != (color.RGBA{}) || img.RGBAAt(2, 2) != c {
+		t.Error("Expected only the sub-image to be filled")
+	}
+}
+
+func BenchmarkDrawBackground(b *testing.B) {
+	img := image.NewRGBA(image.Rect(0, 0, 512, 512))
+	o := DefaultOptions()
+	for i := 0; i < b.N; i++ {
+		drawBackground(img, img.Bounds(), o)
+	}
+}
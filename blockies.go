@@ -0,0 +1,91 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"unicode/utf16"
+)
+
+// blockiesCells is the number of cells across a blockie.
+const blockiesCells = 8
+
+// blockiesSource is the xorshift generator of Ethereum blockies, seeded from
+// the text of an address. Uint64 returns the 32-bit state word blockies
+// divides by 2^31 for its random numbers.
+type blockiesSource [4]int32
+
+// Helper to seed a blockiesSource from an identifier, such as an Ethereum
+// address, in lower case as wallets do
+func newBlockiesSource(hash []byte) rand.Source {
+	var s blockiesSource
+	// Characters are mixed in as the UTF-16 code units of JavaScript
+	for i, c := range utf16.Encode([]rune(strings.ToLower(string(hash)))) {
+		s[i%4] = s[i%4]<<5 - s[i%4] + int32(c)
+	}
+
+	return &s
+}
+
+// Uint64 advances the generator and returns its new state word.
+func (s *blockiesSource) Uint64() uint64 {
+	t := s[0] ^ s[0]<<11
+	s[0], s[1], s[2] = s[1], s[2], s[3]
+	s[3] = s[3] ^ s[3]>>19 ^ t ^ t>>8
+
+	return uint64(uint32(s[3]))
+}
+
+// Helper to get the next random number of blockies, the state word divided
+// by 2^31. The arithmetic shifts keep its sign bit clear, so it is in [0, 1).
+func blockiesRand(r *rand.Rand) float64 {
+	return float64(r.Uint64()) / (1 << 31)
+}
+
+// Helper to pick a color like blockies, honoring a greyscale tone
+func blockiesColor(r *rand.Rand, o Options) color.RGBA {
+	h := math.Floor(blockiesRand(r)*360) / 360
+	s := (blockiesRand(r)*60 + 40) / 100
+	l := (blockiesRand(r) + blockiesRand(r) + blockiesRand(r) + blockiesRand(r)) * 25 / 100
+	if o.tone() == ToneGreyscale {
+		s = 0
+	}
+
+	return hslColor(h, s, l)
+}
+
+// Helper to draw an Ethereum blockie, 8x8 cells mirrored around the middle
+// in a background, a foreground and a spot color. It draws the same blockie
+// as the blockies library for the same address, as long as the random
+// source is a blockiesSource.
+func drawBlockies(img *image.RGBA, r *rand.Rand, o Options) error {
+	fg := blockiesColor(r, o)
+	bg := blockiesColor(r, o)
+	spot := blockiesColor(r, o)
+
+	size := img.Rect.Dx()
+	edge := func(i int) int {
+		return size * i / blockiesCells
+	}
+
+	half := (blockiesCells + 1) / 2
+	for y := 0; y < blockiesCells; y++ {
+		for x := 0; x < half; x++ {
+			// Cells of 0 are background, 1 foreground and 2 spots
+			c := spot
+			switch math.Floor(blockiesRand(r) * 2.3) {
+			case 0:
+				c = bg
+			case 1:
+				c = fg
+			}
+			for _, col := range []int{x, blockiesCells - 1 - x} {
+				fillRGBA(img, image.Rect(edge(col), edge(y), edge(col+1), edge(y+1)), c)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"net/http"
+	"testing"
+)
+
+func TestBlockies(t *testing.T) {
+	// Generated by the blockies library for the lowercase addresses
+	tests := []struct {
+		address      string
+		fg, bg, spot color.RGBA
+		cells        string
+	}{
+		{
+			"0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+			color.RGBA{250, 173, 21, 0xff}, color.RGBA{231, 237, 51, 0xff}, color.RGBA{115, 105, 248, 0xff},
+			"1000000100000000110000111211112101011010021221200200002010211201",
+		},
+		{
+			"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			color.RGBA{19, 65, 174, 0xff}, color.RGBA{14, 254, 174, 0xff}, color.RGBA{65, 109, 153, 0xff},
+			"2211112201022010011001100011110000000000012112100012210010100101",
+		},
+	}
+
+	for _, test := range tests {
+		img := New([]byte(test.address), WithStyle(StyleBlockies), WithSize(64), WithPadding(0)).(*image.RGBA)
+		colors := map[byte]color.RGBA{'0': test.bg, '1': test.fg, '2': test.spot}
+		for i, cell := range []byte(test.cells) {
+			x, y := i%blockiesCells*8+4, i/blockiesCells*8+4
+			if c := img.RGBAAt(x, y); c != colors[cell] {
+				t.Fatalf("Expected %v at cell %d of %s, got %v", colors[cell], i, test.address, c)
+			}
+		}
+	}
+}
+
+func TestBlockiesSource(t *testing.T) {
+	// The seed is the address in lower case
+	a := rand.New(newBlockiesSource([]byte("0xABC")))
+	b := rand.New(newBlockiesSource([]byte("0xabc")))
+	for range 8 {
+		v := blockiesRand(a)
+		if v != blockiesRand(b) {
+			t.Fatal("Expected the same numbers for both cases of an address")
+		}
+		if v < 0 || v >= 1 {
+			t.Fatalf("Expected numbers in [0, 1), got %v", v)
+		}
+	}
+
+	// HashFunc doesn't change blockies
+	img := New([]byte("0xabc"), WithStyle(StyleBlockies)).(*image.RGBA)
+	hashed := New([]byte("0xabc"), WithStyle(StyleBlockies), WithHashFunc(func([]byte) uint64 { return 1 })).(*image.RGBA)
+	if img.RGBAAt(60, 60) != hashed.RGBAAt(60, 60) || img.RGBAAt(4, 4) != hashed.RGBAAt(4, 4) {
+		t.Error("Expected blockies to be seeded from the address")
+	}
+}
+
+func TestHandlerBlockies(t *testing.T) {
+	h := Handler(HandlerConfig{Params: []string{ParamStyle}})
+	const address = "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+	rec := serve(h, http.MethodGet, "/"+address+"?style=blockies&s=64")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the blockie to be served, got %d: %s", rec.Code, rec.Body)
+	}
+
+	want := new(bytes.Buffer)
+	if err := Render(want, []byte(address), FormatPNG, WithStyle(StyleBlockies), WithSize(64)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+		t.Error("Expected the handler to serve the blockie of the address")
+	}
+}
@@ -0,0 +1,104 @@
+package monsterid
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// BMP creates a monsterid image based on the provided hash and returns it
+// encoded as BMP.
+func BMP(hash []byte, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeBMP(buf, hash, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeBMP creates a monsterid image based on the provided hash and writes
+// it to w as an uncompressed BMP, 24-bit if opaque and 32-bit with alpha otherwise.
+func EncodeBMP(w io.Writer, hash []byte, opts ...Option) error {
+	return Render(w, hash, FormatBMP, opts...)
+}
+
+// bmpHeader is the BITMAPFILEHEADER followed by a BITMAPINFOHEADER.
+type bmpHeader struct {
+	Signature  [2]byte
+	FileSize   uint32
+	Reserved   uint32
+	PixOffset  uint32
+	HeaderSize uint32
+	Width      int32
+	Height     int32
+	Planes     uint16
+	BitCount   uint16
+	Compress   uint32
+	ImageSize  uint32
+	XPerMeter  int32
+	YPerMeter  int32
+	ColorsUsed uint32
+	Important  uint32
+}
+
+// Helper to write img as a bottom-up BMP
+func writeBMP(w io.Writer, img *image.NRGBA) error {
+	b := img.Bounds()
+	bpp := 3
+	if !img.Opaque() {
+		bpp = 4
+	}
+	stride := (b.Dx()*bpp + 3) &^ 3 // rows are padded to 4 bytes
+
+	const headerSize = 14 + 40
+	h := bmpHeader{
+		Signature:  [2]byte{'B', 'M'},
+		FileSize:   uint32(headerSize + stride*b.Dy()),
+		PixOffset:  headerSize,
+		HeaderSize: 40,
+		Width:      int32(b.Dx()),
+		Height:     int32(b.Dy()),
+		Planes:     1,
+		BitCount:   uint16(bpp * 8),
+		ImageSize:  uint32(stride * b.Dy()),
+		XPerMeter:  2835, // 72 DPI
+		YPerMeter:  2835,
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, h); err != nil {
+		return err
+	}
+
+	row := make([]byte, stride)
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			i := (x - b.Min.X) * bpp
+			row[i+0], row[i+1], row[i+2] = c.B, c.G, c.R
+			if bpp == 4 {
+				row[i+3] = c.A
+			}
+		}
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Helper to convert an image to non-premultiplied RGBA
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok {
+		return n
+	}
+
+	dst := image.NewNRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,57 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBMP(t *testing.T) {
+	hash := []byte("bmp-test")
+
+	data, err := BMP(hash, WithSize(31))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(data[:2]) != "BM" {
+		t.Fatalf("Missing BMP signature: %q", data[:2])
+	}
+	if got := binary.LittleEndian.Uint32(data[2:]); int(got) != len(data) {
+		t.Errorf("File size is %d, want %d", got, len(data))
+	}
+	if w, h := int32(binary.LittleEndian.Uint32(data[18:])), int32(binary.LittleEndian.Uint32(data[22:])); w != 31 || h != 31 {
+		t.Errorf("Dimensions are %dx%d, want 31x31", w, h)
+	}
+
+	// The default background is opaque, rows are 31*3 bytes padded to 96
+	if bpp := binary.LittleEndian.Uint16(data[28:]); bpp != 24 {
+		t.Errorf("Bit count is %d, want 24", bpp)
+	}
+	if len(data) != 54+96*31 {
+		t.Errorf("File is %d bytes, want %d", len(data), 54+96*31)
+	}
+}
+
+func TestWriteBMPAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 10, G: 20, B: 30, A: 40})
+
+	buf := new(bytes.Buffer)
+	if err := writeBMP(buf, img); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data := buf.Bytes()
+
+	if bpp := binary.LittleEndian.Uint16(data[28:]); bpp != 32 {
+		t.Fatalf("Bit count is %d, want 32", bpp)
+	}
+
+	// Rows are stored bottom-up, so the top-left pixel is in the second row
+	px := data[54+8 : 54+12]
+	if px[0] != 30 || px[1] != 20 || px[2] != 10 || px[3] != 40 {
+		t.Errorf("Top-left pixel is %v, want BGRA [30 20 10 40]", px)
+	}
+}
@@ -0,0 +1,120 @@
+package monsterid
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss is returned by Cache.Get for keys that aren't cached.
+var ErrCacheMiss = errors.New("monsterid: cache miss")
+
+// Cache stores encoded avatars by key, such as MemoryCache, FileCache or a
+// client of Redis or memcached shared by several servers. Handler, Pregenerate
+// and the batch command of cmd/monsterid can use one. It must be safe for
+// concurrent use. Data passed to Set and returned by Get must not be
+// modified.
+type Cache interface {
+	// Get returns the data cached for key, or ErrCacheMiss.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set caches data for key for ttl, or until evicted if ttl is zero.
+	Set(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// Delete removes key from the cache, if it is there.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCache is a Cache in memory holding at most a budget of bytes, evicting
+// the least recently used entries.
+type MemoryCache struct {
+	mu      sync.Mutex
+	budget  int64                    // maximum size of the cached entries in bytes
+	size    int64                    // current size of the cached entries in bytes
+	entries map[string]*list.Element // cached entries by key
+	order   *list.List               // entries from most to least recently used
+	now     func() time.Time         // current time, replaced by tests
+}
+
+// memoryCacheEntry is an entry of a MemoryCache.
+type memoryCacheEntry struct {
+	key     string
+	data    []byte
+	expires time.Time // zero if it doesn't expire
+}
+
+// NewMemoryCache creates a MemoryCache holding at most maxBytes of keys and
+// data.
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	return &MemoryCache{budget: maxBytes, entries: make(map[string]*list.Element), order: list.New(), now: time.Now}
+}
+
+// Get returns the data cached for key, or ErrCacheMiss.
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	entry := e.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(e)
+		return nil, ErrCacheMiss
+	}
+	c.order.MoveToFront(e)
+
+	return entry.data, nil
+}
+
+// Set caches data for key for ttl, or until evicted if ttl is zero. Entries
+// larger than the whole budget aren't cached.
+func (c *MemoryCache) Set(_ context.Context, key string, data []byte, ttl time.Duration) error {
+	entry := &memoryCacheEntry{key: key, data: data}
+	if ttl > 0 {
+		entry.expires = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	size := entry.size()
+	if size > c.budget {
+		return nil
+	}
+	for c.size+size > c.budget {
+		c.remove(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.size += size
+
+	return nil
+}
+
+// Delete removes key from the cache, if it is there.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+
+	return nil
+}
+
+// Helper to remove an entry, the lock must be held
+func (c *MemoryCache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*memoryCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size()
+}
+
+// Helper to get the bytes an entry takes in the cache
+func (e *memoryCacheEntry) size() int64 {
+	return int64(len(e.key) + len(e.data))
+}
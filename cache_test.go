@@ -0,0 +1,140 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Helper to check the Get, Set and Delete of a cache, moving the clock of the
+// cache with advance
+func testCache(t *testing.T, c Cache, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected %v, got %v", ErrCacheMiss, err)
+	}
+
+	if err := c.Set(ctx, "forever", []byte("data"), 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.Set(ctx, "hour", []byte("old"), time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.Set(ctx, "hour", []byte("new"), time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for key, want := range map[string]string{"forever": "data", "hour": "new"} {
+		if data, err := c.Get(ctx, key); err != nil || string(data) != want {
+			t.Errorf("Expected %s to be %q, got %q and %v", key, want, data, err)
+		}
+	}
+
+	// Entries expire after their TTL
+	advance(time.Hour)
+	if _, err := c.Get(ctx, "hour"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected an expired entry to miss, got %v", err)
+	}
+	if _, err := c.Get(ctx, "forever"); err != nil {
+		t.Errorf("Expected an entry without a TTL to stay, got %v", err)
+	}
+
+	if err := c.Delete(ctx, "forever"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.Get(ctx, "forever"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected a deleted entry to miss, got %v", err)
+	}
+	if err := c.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Expected deleting a missing entry to succeed, got %v", err)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(1 << 10)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	testCache(t, c, func(d time.Duration) { now = now.Add(d) })
+
+	if c.size != 0 || c.order.Len() != 0 {
+		t.Errorf("Expected an empty cache, got %d entries of %d bytes", c.order.Len(), c.size)
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// Room for two entries of a 1 byte key and 3 bytes of data
+	ctx := context.Background()
+	c := NewMemoryCache(8)
+	for _, key := range []string{"a", "b", "a", "c"} {
+		if _, err := c.Get(ctx, key); err != nil {
+			c.Set(ctx, key, []byte(key+key+key), 0)
+		}
+	}
+
+	for key, cached := range map[string]bool{"a": true, "b": false, "c": true} {
+		data, err := c.Get(ctx, key)
+		if (err == nil) != cached {
+			t.Errorf("Expected %s to be cached: %v, got %v", key, cached, err)
+		}
+		if err == nil && string(data) != key+key+key {
+			t.Errorf("Expected %s to be %q, got %q", key, key+key+key, data)
+		}
+	}
+	if c.size != 8 || c.order.Len() != 2 {
+		t.Errorf("Expected 2 entries of 8 bytes cached, got %d of %d bytes", c.order.Len(), c.size)
+	}
+
+	c.Set(ctx, "large", make([]byte, 8), 0)
+	if _, err := c.Get(ctx, "large"); err == nil {
+		t.Error("Expected an entry over the budget not to be cached")
+	}
+}
+
+func TestHandlerCache(t *testing.T) {
+	files, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	caches := []struct {
+		name string
+		cfg  func(g *Generator) HandlerConfig
+	}{
+		{"bytes", func(g *Generator) HandlerConfig { return HandlerConfig{Generator: g, CacheBytes: 1 << 20} }},
+		{"files", func(g *Generator) HandlerConfig { return HandlerConfig{Generator: g, Cache: files} }},
+	}
+	uncached := Handler(HandlerConfig{})
+
+	for _, c := range caches {
+		g, err := NewGenerator()
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		h := Handler(c.cfg(g))
+
+		for i := 0; i < 3; i++ {
+			for _, target := range []string{"/alice", "/alice?s=64", "/bob?format=svg", "/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=blank"} {
+				rec := serve(h, http.MethodGet, target)
+				want := serve(uncached, http.MethodGet, target)
+				if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), want.Body.Bytes()) {
+					t.Fatalf("Expected %s to serve the avatar from %s, got %d", target, c.name, rec.Code)
+				}
+				if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(rec.Body.Len()) {
+					t.Errorf("Expected a Content-Length of %d, got %s", rec.Body.Len(), got)
+				}
+				if rec.Header().Get("Content-Type") != want.Header().Get("Content-Type") {
+					t.Errorf("Expected %s to be %s, got %s", target, want.Header().Get("Content-Type"), rec.Header().Get("Content-Type"))
+				}
+			}
+		}
+
+		// Each monster is only rendered once
+		if n := g.Stats().Monsters; n != 3 {
+			t.Errorf("Expected 3 monsters rendered with %s, got %d", c.name, n)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package monsterid
+
+import (
+	"image"
+	"math/rand/v2"
+)
+
+// catDir is the embedded directory of the cat parts, drawn by gen_parts.go.
+const catDir = "parts/cat"
+
+// catFur are the categories of cat parts tinted with the fur color, in
+// drawing order so the ears go behind the face.
+var catFur = []figurePart{
+	{"ears", 6, true},
+	{"face", 4, true},
+	{"pattern", 7, true},
+}
+
+// catEyes are the eyes of cats, tinted with the eye color.
+var catEyes = []figurePart{
+	{"eyes", 6, true},
+}
+
+// catDetails are the whiskers, left as drawn, and the accessories of cats,
+// tinted with the accessory color.
+var catDetails = []figurePart{
+	{"whiskers", 4, false},
+	{"accessory", 6, true},
+}
+
+// Helper to draw a cat, with ears, face and fur pattern in one fur color,
+// eyes in another, whiskers and an accessory such as a collar in a third,
+// picked from the embedded parts
+func drawCat(img *image.RGBA, r *rand.Rand, o Options) error {
+	fur := figureColor(r, o, 0.7)
+	eyes := figureColor(r, o, 0.6)
+	accessory := figureColor(r, o, 0.5)
+
+	if err := drawFigureParts(img, r, o, catDir, catFur, fur); err != nil {
+		return err
+	}
+	if err := drawFigureParts(img, r, o, catDir, catEyes, eyes); err != nil {
+		return err
+	}
+
+	return drawFigureParts(img, r, o, catDir, catDetails, accessory)
+}
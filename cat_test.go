@@ -0,0 +1,38 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCat(t *testing.T) {
+	opts := []Option{WithStyle(StyleCat), WithTransparentBackground()}
+	img := New([]byte("alice"), opts...).(*image.RGBA)
+	if !bytes.Equal(img.Pix, New([]byte("alice"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected the same cat for the same hash")
+	}
+	if bytes.Equal(img.Pix, New([]byte("bob"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected cats to differ by hash")
+	}
+
+	// The face is in the middle, the corners stay transparent
+	if img.RGBAAt(0, 0).A != 0 {
+		t.Errorf("Expected a transparent corner, got %v", img.RGBAAt(0, 0))
+	}
+	if c := img.RGBAAt(nativeSize/2, nativeSize/2); c.A != 0xff {
+		t.Errorf("Expected the face in the middle, got %v", c)
+	}
+}
+
+func TestCatGreyscale(t *testing.T) {
+	for _, hash := range []string{"alice", "bob", "carol", "dave"} {
+		img := New([]byte(hash), WithStyle(StyleCat), WithGreyscale()).(*image.RGBA)
+		for i := 0; i < len(img.Pix); i += 4 {
+			if c := (color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}); c.R != c.G || c.G != c.B {
+				t.Fatalf("Expected a grey cat for %s, got %v", hash, c)
+			}
+		}
+	}
+}
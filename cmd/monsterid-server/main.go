@@ -0,0 +1,323 @@
+// Command monsterid-server serves monsterid avatars over HTTP, for running
+// next to a service as a sidecar.
+//
+// Avatars are served at /{hash} and at the Gravatar compatible
+// /avatar/{hash}, metrics at /metrics and a health check at /healthz:
+//
+//	monsterid-server -addr :8080 -max-size 512 -cache-bytes 67108864
+//
+// Settings are read from flags, and from a config file of the same names
+// with -config, a flat YAML file such as:
+//
+//	addr: ":8080"
+//	theme: robot
+//	params: theme,bg,shape,grey
+//	cache-dir: /var/cache/monsterid
+//
+// Flags take precedence over the config file. The signing secret is read from
+// the MONSTERID_SECRET environment variable, so it stays out of the process
+// list. The server shuts down gracefully on SIGINT and SIGTERM.
+//
+// With -gravatar, /avatar/{hash} serves the real Gravatar avatar of a hash
+// when there is one and the monster otherwise, as forum software expects.
+//
+// With -libravatar, the server is the federated Libravatar host of a domain:
+// it answers /avatar/{hash} at the root of the server with any prefix but
+// /avatar, and checks at startup where the _avatars-sec._tcp and _avatars._tcp
+// SRV records of the domain send Libravatar clients. Publish records such as
+//
+//	_avatars-sec._tcp.example.com. IN SRV 0 0 443 avatars.example.com.
+//
+// pointing at the server, behind a TLS proxy for _avatars-sec.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/weavatar/monsterid"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "monsterid-server:", err)
+		os.Exit(1)
+	}
+}
+
+// config is the settings of the server.
+type config struct {
+	addr            string
+	prefix          string
+	metrics         string
+	pack            string
+	theme           string
+	format          string
+	params          string
+	maxSize         int
+	maxAge          time.Duration
+	cacheBytes      int64
+	cacheDir        string
+	maxRenders      int
+	renderTimeout   time.Duration
+	shutdownTimeout time.Duration
+	libravatar      string
+	gravatar        string
+	gravatarTimeout time.Duration
+	secret          string
+}
+
+// Helper to read the settings from the command line arguments and the config
+// file they name
+func parseConfig(args []string, stderr io.Writer) (config, error) {
+	var c config
+	fs := flag.NewFlagSet("monsterid-server", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFile := fs.String("config", "", "read settings from a flat YAML `file`, overridden by flags")
+	fs.StringVar(&c.addr, "addr", ":8080", "listen on this `address`")
+	fs.StringVar(&c.prefix, "prefix", "/", "serve avatars under this `path`")
+	fs.StringVar(&c.metrics, "metrics", "/metrics", "serve Prometheus metrics at this `path`, none if empty")
+	fs.StringVar(&c.pack, "pack", "", "render with the part pack in this `directory` instead of the embedded parts")
+	fs.StringVar(&c.theme, "theme", "", "built-in `theme` of the parts, such as robot")
+	fs.StringVar(&c.format, "format", "png", "`format` without a format parameter")
+	fs.StringVar(&c.params, "params", "", "comma-separated query `parameters` allowed to style avatars, such as theme,style,name,bg,shape,grey")
+	fs.IntVar(&c.maxSize, "max-size", monsterid.DefaultMaxSize, "largest avatar size in `pixels`")
+	fs.DurationVar(&c.maxAge, "max-age", 0, "how long browsers and CDNs cache avatars (a year if zero)")
+	fs.Int64Var(&c.cacheBytes, "cache-bytes", 0, "cache up to this many `bytes` of avatars in memory")
+	fs.StringVar(&c.cacheDir, "cache-dir", "", "cache avatars as files in this `directory` instead")
+	fs.IntVar(&c.maxRenders, "max-renders", 0, "render at most this many avatars at the same time (unlimited if zero)")
+	fs.DurationVar(&c.renderTimeout, "render-timeout", 0, "longest wait and render of an avatar (unlimited if zero)")
+	fs.DurationVar(&c.shutdownTimeout, "shutdown-timeout", 10*time.Second, "how long requests may finish on shutdown")
+	fs.StringVar(&c.libravatar, "libravatar", "", "serve as the federated Libravatar host of this `domain`")
+	fs.StringVar(&c.gravatar, "gravatar", "", "serve real avatars from this Gravatar `URL` at /avatar/, such as https://secure.gravatar.com/avatar")
+	fs.DurationVar(&c.gravatarTimeout, "gravatar-timeout", 0, "longest check of -gravatar for a real avatar (2s if zero)")
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+	if fs.NArg() > 0 {
+		return config{}, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	if *configFile != "" {
+		// Flags on the command line win over the file
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		settings, err := readConfigFile(*configFile)
+		if err != nil {
+			return config{}, err
+		}
+		for _, s := range settings {
+			if s.name == "config" || fs.Lookup(s.name) == nil {
+				return config{}, fmt.Errorf("%s:%d: unknown setting %q", *configFile, s.line, s.name)
+			}
+			if set[s.name] {
+				continue
+			}
+			if err := fs.Set(s.name, s.value); err != nil {
+				return config{}, fmt.Errorf("%s:%d: %s: %w", *configFile, s.line, s.name, err)
+			}
+		}
+	}
+	c.secret = os.Getenv("MONSTERID_SECRET")
+
+	return c, nil
+}
+
+// setting is a line of a config file.
+type setting struct {
+	name, value string
+	line        int
+}
+
+// Helper to read the key: value lines of a flat YAML file, skipping comments
+// and blank lines
+func readConfigFile(file string) ([]setting, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var settings []setting
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected name: value", file, n)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		settings = append(settings, setting{name: strings.TrimSpace(name), value: value, line: n})
+	}
+
+	return settings, scanner.Err()
+}
+
+// Helper to create the handler of all endpoints of the server
+func (c config) handler() (http.Handler, error) {
+	cfg := monsterid.HandlerConfig{
+		Format:        monsterid.Format(c.format),
+		MaxSize:       c.maxSize,
+		MaxAge:        c.maxAge,
+		CacheBytes:    c.cacheBytes,
+		MaxRenders:    c.maxRenders,
+		RenderTimeout: c.renderTimeout,
+		Secret:        []byte(c.secret),
+
+		GravatarURL:     c.gravatar,
+		GravatarTimeout: c.gravatarTimeout,
+	}
+	if c.libravatar != "" && c.secret != "" {
+		return nil, errors.New("libravatar clients can't sign URLs, unset MONSTERID_SECRET")
+	}
+	if cfg.Format.ContentType() == "" {
+		return nil, fmt.Errorf("unknown format %q", c.format)
+	}
+	if c.theme != "" {
+		if !slices.Contains(monsterid.Themes(), monsterid.Theme(c.theme)) {
+			return nil, fmt.Errorf("unknown theme %q", c.theme)
+		}
+		cfg.Options = append(cfg.Options, monsterid.WithTheme(monsterid.Theme(c.theme)))
+	}
+	if c.params != "" {
+		cfg.Params = strings.Split(c.params, ",")
+		if err := monsterid.ValidateParams(cfg.Params); err != nil {
+			return nil, err
+		}
+	}
+	if c.pack != "" {
+		if c.theme != "" || slices.Contains(cfg.Params, monsterid.ParamTheme) {
+			return nil, errors.New("themes select embedded parts and can't be combined with -pack")
+		}
+		g, err := monsterid.NewGeneratorFromFS(os.DirFS(c.pack))
+		if err != nil {
+			return nil, err
+		}
+		cfg.Generator = g
+	}
+	if c.cacheDir != "" {
+		cache, err := monsterid.NewFileCache(c.cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Cache = cache
+	}
+
+	mux := http.NewServeMux()
+	if c.metrics != "" {
+		cfg.Collector = monsterid.NewCollector()
+		mux.Handle("GET "+c.metrics, cfg.Collector)
+	}
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	avatars := monsterid.Handler(cfg)
+	prefix := "/" + strings.Trim(c.prefix, "/")
+	if c.libravatar != "" && prefix == "/avatar" {
+		return nil, errors.New("libravatar clients request /avatar/{hash}, which -prefix /avatar serves as /{hash}")
+	}
+	if prefix == "/" {
+		mux.Handle("/", avatars)
+	} else {
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, avatars))
+	}
+	if c.libravatar != "" && prefix != "/" {
+		// Libravatar clients request /avatar/{hash} at the root of the host
+		mux.Handle("/avatar/", avatars)
+	}
+
+	return mux, nil
+}
+
+// Helper to run the server until it is interrupted
+func run(args []string, stderr io.Writer) error {
+	c, err := parseConfig(args, stderr)
+	if err != nil {
+		return err
+	}
+	h, err := c.handler()
+	if err != nil {
+		return err
+	}
+
+	if c.libravatar != "" {
+		if base, err := libravatarBase(c.libravatar, net.LookupSRV); err != nil {
+			fmt.Fprintf(stderr, "monsterid-server: libravatar: %v\n", err)
+		} else {
+			fmt.Fprintf(stderr, "monsterid-server: libravatar clients of %s request %s/avatar/\n", c.libravatar, base)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: c.addr, Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+	fmt.Fprintf(stderr, "monsterid-server: listening on %s\n", c.addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Let requests in progress finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Helper to find the base URL Libravatar clients use for the avatars of
+// domain, the way they look it up: the _avatars-sec._tcp SRV record for
+// HTTPS, else the _avatars._tcp record for HTTP. Both are sorted by priority
+// and weight by lookup, so the first one wins. Without records, clients fall
+// back to libravatar.org, which is reported as an error.
+func libravatarBase(domain string, lookup func(service, proto, name string) (string, []*net.SRV, error)) (string, error) {
+	for _, service := range []struct{ name, scheme, port string }{{"avatars-sec", "https", "443"}, {"avatars", "http", "80"}} {
+		_, addrs, err := lookup(service.name, "tcp", domain)
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		// A target of "." means the service is explicitly unavailable
+		target := strings.TrimSuffix(addrs[0].Target, ".")
+		if target == "" {
+			continue
+		}
+		host := target
+		if port := strconv.Itoa(int(addrs[0].Port)); port != service.port {
+			host = net.JoinHostPort(target, port)
+		}
+		return service.scheme + "://" + host, nil
+	}
+
+	return "", fmt.Errorf("no _avatars-sec._tcp or _avatars._tcp SRV records for %s, clients use libravatar.org", domain)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	data := `# monsterid-server
+addr: "127.0.0.1:9000"
+theme: robot   # boxy
+max-size: 256
+render-timeout: 2s
+
+params: theme,grey
+`
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c, err := parseConfig([]string{"-config", file, "-max-size", "512"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.addr != "127.0.0.1:9000" || c.theme != "robot" || c.params != "theme,grey" || c.renderTimeout != 2*time.Second {
+		t.Errorf("Expected the settings of the file, got %+v", c)
+	}
+	if c.maxSize != 512 {
+		t.Errorf("Expected flags to win over the file, got %d", c.maxSize)
+	}
+	if c.format != "png" || c.metrics != "/metrics" {
+		t.Errorf("Expected the defaults of other settings, got %+v", c)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"colour: red\n", `unknown setting "colour"`},
+		{"max-size: large\n", "max-size"},
+		{"addr\n", "expected name: value"},
+	}
+
+	for _, test := range tests {
+		file := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(file, []byte(test.data), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := parseConfig([]string{"-config", file}, io.Discard); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Expected an error with %q for %q, got %v", test.want, test.data, err)
+		}
+	}
+
+	if _, err := parseConfig([]string{"-config", filepath.Join(dir, "missing.yaml")}, io.Discard); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+	if _, err := parseConfig([]string{"extra"}, io.Discard); err == nil {
+		t.Error("Expected an error for unexpected arguments")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c, err := parseConfig([]string{"-prefix", "/avatars/", "-params", "grey", "-cache-dir", t.TempDir()}, io.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	h, err := c.handler()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		{"/avatars/alice?s=32&grey=1", http.StatusOK, "\x89PNG"},
+		{"/avatars/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=404", http.StatusNotFound, ""},
+		{"/alice", http.StatusNotFound, ""},
+		{"/healthz", http.StatusOK, "ok"},
+		{"/metrics", http.StatusOK, "# HELP monsterid_requests_total"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.target, nil))
+		if rec.Code != test.status || !strings.HasPrefix(rec.Body.String(), test.body) {
+			t.Errorf("Expected %s to be %d with %q, got %d with %.20q", test.target, test.status, test.body, rec.Code, rec.Body)
+		}
+	}
+
+	for _, args := range [][]string{{"-format", "jpeg"}, {"-theme", "space"}, {"-params", "size"}, {"-pack", t.TempDir()}} {
+		c, err := parseConfig(args, io.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := c.handler(); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+func TestLibravatar(t *testing.T) {
+	c, err := parseConfig([]string{"-prefix", "/avatars/", "-libravatar", "example.com"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	h, err := c.handler()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	const sha256 = "b4c9a289323b21a01c3e940f150eb9b8c542587f1abfd8f0e1cc1ffc5e475514"
+	for _, target := range []string{"/avatar/" + sha256 + "?s=32", "/avatars/avatar/" + sha256 + "?s=32"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "\x89PNG") {
+			t.Errorf("Expected %s to be served, got %d", target, rec.Code)
+		}
+	}
+
+	c.secret = "secret"
+	if _, err := c.handler(); err == nil {
+		t.Error("Expected an error for a secret with libravatar")
+	}
+
+	// The root prefix serves /avatar/ already, /avatar would take it over
+	for _, test := range []struct {
+		prefix string
+		ok     bool
+	}{{"/", true}, {"/avatar/", false}, {"avatar", false}, {"/avatar/v1", true}} {
+		c, err := parseConfig([]string{"-prefix", test.prefix, "-libravatar", "example.com"}, io.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		h, err := c.handler()
+		if (err == nil) != test.ok {
+			t.Errorf("Expected ok %v for -prefix %s, got %v", test.ok, test.prefix, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/avatar/"+sha256+"?s=32", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected /avatar/ to be served with -prefix %s, got %d", test.prefix, rec.Code)
+		}
+	}
+}
+
+func TestLibravatarBase(t *testing.T) {
+	tests := []struct {
+		records map[string][]*net.SRV
+		want    string
+	}{
+		{map[string][]*net.SRV{
+			"avatars-sec": {{Target: "avatars.example.com.", Port: 443}, {Target: "backup.example.com.", Port: 443}},
+			"avatars":     {{Target: "plain.example.com.", Port: 80}},
+		}, "https://avatars.example.com"},
+		{map[string][]*net.SRV{"avatars-sec": {{Target: "avatars.example.com.", Port: 8443}}}, "https://avatars.example.com:8443"},
+		{map[string][]*net.SRV{"avatars": {{Target: "avatars.example.com.", Port: 80}}}, "http://avatars.example.com"},
+		{map[string][]*net.SRV{"avatars-sec": {{Target: ".", Port: 0}}, "avatars": {{Target: "avatars.example.com.", Port: 8080}}}, "http://avatars.example.com:8080"},
+		{map[string][]*net.SRV{}, ""},
+	}
+
+	for _, test := range tests {
+		lookup := func(service, proto, name string) (string, []*net.SRV, error) {
+			if proto != "tcp" || name != "example.com" {
+				t.Fatalf("Unexpected lookup of %s %s %s", service, proto, name)
+			}
+			if addrs, ok := test.records[service]; ok {
+				return "", addrs, nil
+			}
+			return "", nil, errors.New("no such host")
+		}
+
+		base, err := libravatarBase("example.com", lookup)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("Expected an error without records, got %q", base)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if base != test.want {
+			t.Errorf("Expected %q, got %q", test.want, base)
+		}
+	}
+}
+
+func TestGravatar(t *testing.T) {
+	gravatar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		io.WriteString(w, "jpeg")
+	}))
+	defer gravatar.Close()
+
+	c, err := parseConfig([]string{"-gravatar", gravatar.URL, "-gravatar-timeout", "1s"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	h, err := c.handler()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "jpeg" {
+		t.Errorf("Expected the real avatar, got %d with %.20q", rec.Code, rec.Body)
+	}
+}
+
+func TestHandlerPackThemes(t *testing.T) {
+	pack := t.TempDir()
+	for _, args := range [][]string{{"-pack", pack, "-theme", "robot"}, {"-pack", pack, "-params", "grey,theme"}} {
+		c, err := parseConfig(args, io.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := c.handler(); err == nil || !strings.Contains(err.Error(), "-pack") {
+			t.Errorf("Expected themes to be rejected with -pack for %q, got %v", args, err)
+		}
+	}
+}
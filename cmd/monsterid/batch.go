@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/weavatar/monsterid"
+)
+
+// batchJob is an identifier read by monsterid batch.
+type batchJob struct {
+	line  int
+	ident string
+}
+
+// batchCounts are the progress of monsterid batch.
+type batchCounts struct {
+	written, skipped, failed atomic.Int64
+}
+
+// Helper to run monsterid batch, writing the avatars of the identifiers in a
+// file or stdin to a directory
+func runBatch(args []string, stdout, stderr io.Writer) error {
+	var s style
+	fs := flag.NewFlagSet("monsterid batch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	in := fs.String("in", "-", "read identifiers from this CSV `file`, one per line or in a column, stdin if -")
+	column := fs.Int("column", 1, "read identifiers from this `column` of the CSV file, starting at 1")
+	header := fs.Bool("header", false, "skip the first line of the CSV file")
+	ids := fs.Bool("id", false, "use identifiers as they are instead of hashing them as email addresses")
+	outDir := fs.String("out-dir", "", "write the avatars to this `directory`, named by hash and format")
+	workers := fs.Int("workers", runtime.NumCPU(), "render this many avatars at the same time")
+	skipExisting := fs.Bool("skip-existing", false, "keep avatars already in the directory, to resume a batch")
+	progress := fs.Duration("progress", 2*time.Second, "report progress at this `interval`, never if zero")
+	cacheDir := fs.String("cache-dir", "", "keep rendered avatars in this `directory` and copy them from it in later batches")
+	s.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if *outDir == "" {
+		return errors.New("batch needs -out-dir")
+	}
+	if *column < 1 {
+		return fmt.Errorf("invalid column %d", *column)
+	}
+	if *workers < 1 {
+		return fmt.Errorf("invalid workers %d", *workers)
+	}
+
+	opts, err := s.options()
+	if err != nil {
+		return err
+	}
+	format, err := s.formatFor("")
+	if err != nil {
+		return err
+	}
+	algo, err := s.algorithm()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	// Every worker renders with the same cached parts of the theme
+	g, err := monsterid.NewThemeGenerator(cmp.Or(monsterid.Theme(s.theme), monsterid.ThemeClassic))
+	if err != nil {
+		return err
+	}
+
+	var cache monsterid.Cache
+	if *cacheDir != "" {
+		if cache, err = monsterid.NewFileCache(*cacheDir); err != nil {
+			return err
+		}
+	}
+	prefix := batchCachePrefix(fs)
+
+	r := io.Reader(os.Stdin)
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var counts batchCounts
+	var mu sync.Mutex // serializes the reports on stderr
+	report := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(stderr, "monsterid: "+format+"\n", args...)
+	}
+
+	jobs := make(chan batchJob, *workers)
+	var wg sync.WaitGroup
+	for range *workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				name, hash, err := batchName(job.ident, *ids, algo)
+				if err != nil {
+					report("line %d: %v", job.line, err)
+					counts.failed.Add(1)
+					continue
+				}
+				path := filepath.Join(*outDir, name+"."+string(format))
+				if *skipExisting {
+					if _, err := os.Stat(path); err == nil {
+						counts.skipped.Add(1)
+						continue
+					}
+				}
+				err = writeFile(path, func(w io.Writer) error {
+					render := func(w io.Writer) error {
+						return g.Render(w, hash, format, opts...)
+					}
+					if cache == nil {
+						return render(w)
+					}
+					return writeCached(w, cache, prefix+name+"."+string(format), render)
+				})
+				if err != nil {
+					report("line %d: %v", job.line, err)
+					counts.failed.Add(1)
+					continue
+				}
+				counts.written.Add(1)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	if *progress > 0 {
+		go func() {
+			ticker := time.NewTicker(*progress)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					report("%s", counts.String())
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	err = readIdentifiers(r, *column, *header, jobs)
+	close(jobs)
+	wg.Wait()
+	close(done)
+	report("%s", counts.String())
+	if err != nil {
+		return err
+	}
+	if n := counts.failed.Load(); n > 0 {
+		return fmt.Errorf("%d avatars failed", n)
+	}
+
+	return nil
+}
+
+// Helper to get the prefix of the cache keys of a batch, a digest of the
+// style flags set so batches drawing other avatars don't share them
+func batchCachePrefix(fs *flag.FlagSet) string {
+	var styleFlags flag.FlagSet
+	new(style).register(&styleFlags)
+
+	h := sha256.New()
+	fs.Visit(func(f *flag.Flag) {
+		if styleFlags.Lookup(f.Name) != nil {
+			fmt.Fprintf(h, "%s=%q\n", f.Name, f.Value)
+		}
+	})
+
+	return "batch/" + hex.EncodeToString(h.Sum(nil)[:8]) + "/"
+}
+
+// Helper to write the avatar cached under key to w, rendering it with render
+// and caching it if it isn't cached
+func writeCached(w io.Writer, cache monsterid.Cache, key string, render func(io.Writer) error) error {
+	ctx := context.Background()
+	// Failures are treated as misses
+	if data, err := cache.Get(ctx, key); err == nil {
+		_, err = w.Write(data)
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := render(buf); err != nil {
+		return err
+	}
+	// A failure only costs rendering again
+	cache.Set(ctx, key, buf.Bytes(), 0)
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// Helper to stream the identifiers in a column of a CSV file to jobs,
+// skipping empty values
+func readIdentifiers(r io.Reader, column int, header bool, jobs chan<- batchJob) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.TrimLeadingSpace = true
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first && header {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		if column > len(record) {
+			return fmt.Errorf("line %d: no column %d", line, column)
+		}
+		if record[column-1] == "" {
+			continue
+		}
+		jobs <- batchJob{line: line, ident: record[column-1]}
+	}
+}
+
+// Helper to get the file name and hash of the avatar of an identifier, the
+// hex hash of an email address or the escaped identifier
+func batchName(ident string, id bool, algo monsterid.HashAlgorithm) (string, []byte, error) {
+	if !id {
+		hash := monsterid.HashString(monsterid.NormalizeEmail(ident), algo)
+		return hash, []byte(hash), nil
+	}
+	name := url.PathEscape(ident)
+	if name == "." || name == ".." {
+		return "", nil, fmt.Errorf("invalid file name %q", ident)
+	}
+
+	return name, []byte(ident), nil
+}
+
+func (c *batchCounts) String() string {
+	return fmt.Sprintf("%d avatars written, %d skipped, %d failed", c.written.Load(), c.skipped.Load(), c.failed.Load())
+}
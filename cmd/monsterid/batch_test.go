@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/weavatar/monsterid"
+)
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "users.csv")
+	data := "id,email\n1, Alice@Example.com\n2,\n3,\"bob@example.com\"\n"
+	if err := os.WriteFile(in, []byte(data), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out := filepath.Join(dir, "avatars")
+	stderr := new(bytes.Buffer)
+	args := []string{"batch", "--in", in, "--column", "2", "--header", "--out-dir", out, "--format", "svg", "--size", "64", "--workers", "3"}
+	if err := run(args, io.Discard, stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "2 avatars written, 0 skipped, 0 failed") {
+		t.Errorf("Expected a summary of the batch, got %q", stderr)
+	}
+
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		hash := monsterid.HashString(email, monsterid.MD5)
+		got, err := os.ReadFile(filepath.Join(out, hash+".svg"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := new(bytes.Buffer)
+		if err := monsterid.Render(want, []byte(hash), monsterid.FormatSVG, monsterid.WithSize(64)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, want.Bytes()) {
+			t.Errorf("Expected the avatar of %s", email)
+		}
+	}
+
+	// Resuming keeps what was written
+	stderr.Reset()
+	if err := run(append(args, "-skip-existing"), io.Discard, stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "0 avatars written, 2 skipped, 0 failed") {
+		t.Errorf("Expected existing avatars to be skipped, got %q", stderr)
+	}
+}
+
+func TestBatchIdentifiers(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "users.txt")
+	if err := os.WriteFile(in, []byte("alice\nbob/smith\n..\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stderr := new(bytes.Buffer)
+	err := runBatch([]string{"-in", in, "-id", "-out-dir", dir, "-progress", "0"}, io.Discard, stderr)
+	if err == nil || !strings.Contains(stderr.String(), "line 3:") {
+		t.Errorf("Expected line 3 to fail, got %v: %q", err, stderr)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "alice.png"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, render(t, nil)) {
+		t.Error("Expected the avatar of the identifier as it is")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bob%2Fsmith.png")); err != nil {
+		t.Errorf("Expected the identifier to be escaped in the file name: %v", err)
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "users.csv")
+	if err := os.WriteFile(in, []byte("alice@example.com\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"-in", in},
+		{"-in", in, "-out-dir", dir, "-format", "webp"},
+		{"-in", in, "-out-dir", dir, "-column", "0"},
+		{"-in", in, "-out-dir", dir, "-column", "2"},
+		{"-in", in, "-out-dir", dir, "-workers", "0"},
+		{"-in", filepath.Join(dir, "missing.csv"), "-out-dir", dir},
+	} {
+		if err := runBatch(args, io.Discard, io.Discard); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+
+	err := runBatch([]string{"-in", in, "-out-dir", dir, "-format", "webp"}, io.Discard, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "webp is not supported") {
+		t.Errorf("Expected WebP to be explained, got %v", err)
+	}
+}
+
+func TestBatchTheme(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "users.txt")
+	if err := os.WriteFile(in, []byte("alice\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	render := func(theme string) []byte {
+		out := filepath.Join(dir, "avatars-"+theme)
+		args := []string{"batch", "-in", in, "-id", "-out-dir", out, "-size", "64"}
+		if theme != "" {
+			args = append(args, "-theme", theme)
+		}
+		if err := run(args, io.Discard, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(out, "alice.png"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return data
+	}
+
+	robot := render("robot")
+	if bytes.Equal(robot, render("")) {
+		t.Error("Expected -theme to change the avatars")
+	}
+	want := new(bytes.Buffer)
+	if err := monsterid.Render(want, []byte("alice"), monsterid.FormatPNG, monsterid.WithSize(64), monsterid.WithTheme(monsterid.ThemeRobot)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(robot, want.Bytes()) {
+		t.Error("Expected the avatar of the robot theme")
+	}
+}
+
+func TestBatchCache(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "users.txt")
+	if err := os.WriteFile(in, []byte("alice\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cacheDir := filepath.Join(dir, "cache")
+
+	batch := func(out string, flags ...string) []byte {
+		args := append([]string{"-in", in, "-id", "-out-dir", filepath.Join(dir, out), "-cache-dir", cacheDir, "-progress", "0"}, flags...)
+		if err := runBatch(args, io.Discard, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, out, "alice.png"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return data
+	}
+
+	if !bytes.Equal(batch("first"), render(t, nil)) {
+		t.Fatal("Expected the avatar of the identifier")
+	}
+
+	// Later batches copy the cached avatar instead of rendering it
+	files, err := os.ReadDir(cacheDir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one cached avatar, got %d: %v", len(files), err)
+	}
+	cached := append(make([]byte, 8), "cached"...)
+	if err := os.WriteFile(filepath.Join(cacheDir, files[0].Name()), cached, 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := batch("second"); string(got) != "cached" {
+		t.Errorf("Expected the cached avatar, got %d bytes", len(got))
+	}
+
+	// Other style flags draw other avatars
+	if got := batch("robot", "-theme", "robot"); string(got) == "cached" {
+		t.Error("Expected a batch with another theme to render its avatars")
+	}
+}
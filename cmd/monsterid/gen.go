@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/weavatar/monsterid"
+)
+
+// Helper to run monsterid gen, writing the avatar of an email address or
+// identifier to a file or stdout
+func runGen(args []string, stdout, stderr io.Writer) error {
+	var s style
+	fs := flag.NewFlagSet("monsterid gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	email := fs.String("email", "", "render the avatar of this email `address`")
+	id := fs.String("id", "", "render the avatar of this `identifier` or hash as it is")
+	out := fs.String("out", "-", "write the avatar to this `file`, stdout if -")
+	s.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if (*email == "") == (*id == "") {
+		return errors.New("gen needs one of -email or -id")
+	}
+
+	opts, err := s.options()
+	if err != nil {
+		return err
+	}
+	format, err := s.formatFor(*out)
+	if err != nil {
+		return err
+	}
+	hash := *id
+	if *email != "" {
+		algo, err := s.algorithm()
+		if err != nil {
+			return err
+		}
+		hash = monsterid.HashString(monsterid.NormalizeEmail(*email), algo)
+	}
+
+	if *out == "-" {
+		return monsterid.Render(stdout, []byte(hash), format, opts...)
+	}
+
+	return writeFile(*out, func(w io.Writer) error {
+		return monsterid.Render(w, []byte(hash), format, opts...)
+	})
+}
+
+// Helper to write a file with render, removing it if rendering fails
+func writeFile(name string, render func(w io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+
+	return f.Close()
+}
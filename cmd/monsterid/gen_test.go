@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/weavatar/monsterid"
+)
+
+func TestGen(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "avatar.svg")
+	if err := run([]string{"gen", "--email", " User@Example.com", "--size", "128", "--out", out}, io.Discard, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := new(bytes.Buffer)
+	hash := monsterid.HashString("user@example.com", monsterid.MD5)
+	if err := monsterid.Render(want, []byte(hash), monsterid.FormatSVG, monsterid.WithSize(128)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Error("Expected the SVG of the MD5 hash of the normalized address")
+	}
+}
+
+func TestGenStdout(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	if err := run([]string{"gen", "-id", "alice", "-grey"}, stdout, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(stdout.Bytes(), render(t, []monsterid.Option{monsterid.WithGreyscale()})) {
+		t.Error("Expected the PNG of the identifier on stdout")
+	}
+
+	stdout.Reset()
+	if err := run([]string{"gen", "-email", "user@example.com", "-hash", "sha256"}, stdout, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := new(bytes.Buffer)
+	hash := monsterid.HashString("user@example.com", monsterid.SHA256)
+	if err := monsterid.Render(want, []byte(hash), monsterid.FormatPNG); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(stdout.Bytes(), want.Bytes()) {
+		t.Error("Expected the PNG of the SHA-256 hash")
+	}
+}
+
+func TestGenErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-email", "a@example.com", "-id", "alice"},
+		{"-id", "alice", "-format", "jpeg"},
+		{"-id", "alice", "-theme", "space"},
+		{"-email", "a@example.com", "-hash", "sha1"},
+		{"-id", "alice", "extra"},
+		{"-id", "alice", "-out", filepath.Join(t.TempDir(), "missing", "avatar.png")},
+	} {
+		if err := runGen(args, io.Discard, io.Discard); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
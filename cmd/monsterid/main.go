@@ -0,0 +1,189 @@
+// Command monsterid generates monsterid avatars from the command line, for
+// scripts and designers who don't write Go.
+//
+// The gen command writes the avatar of an email address or identifier:
+//
+//	monsterid gen -email user@example.com -size 256 -out avatar.png
+//	monsterid gen -id alice -theme robot -bg 00000000 -grey -format svg > alice.svg
+//
+// The batch command writes the avatars of a list of email addresses or
+// identifiers, one per line or in a column of a CSV file, to pre-generate the
+// avatars of an existing user base:
+//
+//	monsterid batch -in users.csv -column 2 -header -out-dir ./avatars -workers 8
+//
+// The montage command draws a grid of random or listed monsters into one PNG,
+// to review the variety of a new part pack before shipping it:
+//
+//	monsterid montage -pack ./parts -count 100 -cols 10 -size 64 -out sheet.png
+//
+// The show command draws an avatar in the terminal with truecolor half
+// blocks, or sixel graphics where the terminal supports them:
+//
+//	monsterid show -theme robot alice
+//
+// Email addresses are normalized and hashed with MD5, or SHA-256 with
+// -hash sha256, so the avatar matches the one served for the Gravatar or
+// Libravatar URL of the address. Identifiers are used as they are, matching
+// the avatar served at /{hash}. Run monsterid <command> -h for the flags of a
+// command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/weavatar/monsterid"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "monsterid:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// commands are the subcommands of monsterid by name.
+var commands = map[string]func(args []string, stdout, stderr io.Writer) error{
+	"gen":     runGen,
+	"batch":   runBatch,
+	"montage": runMontage,
+	"show":    runShow,
+}
+
+// Helper to run the subcommand named by the first argument
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	return cmd(args[1:], stdout, stderr)
+}
+
+// Helper to list the subcommands
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	fmt.Fprintf(w, "Usage: monsterid <command> [flags]\n\nCommands: %s\n", strings.Join(names, ", "))
+}
+
+// style is the look of avatars set by flags shared by the commands.
+type style struct {
+	size   int
+	theme  string
+	kind   string
+	name   string
+	bg     string
+	grey   bool
+	format string
+	hash   string
+}
+
+// Helper to register the flags of the style on fs
+func (s *style) register(fs *flag.FlagSet) {
+	fs.IntVar(&s.size, "size", 0, "size in `pixels` (the size of the parts if zero)")
+	fs.StringVar(&s.theme, "theme", "", "built-in `theme` of the parts, such as robot")
+	fs.StringVar(&s.kind, "style", "", "built-in `style` of avatar, such as identicon, blockies or rings (monster if empty)")
+	fs.StringVar(&s.name, "name", "", "`name` whose initials the initials style draws, such as \"Ada Lovelace\"")
+	fs.StringVar(&s.bg, "bg", "", "background `color` as hex RRGGBB or RRGGBBAA, such as 00000000")
+	fs.BoolVar(&s.grey, "grey", false, "render in greyscale")
+	fs.StringVar(&s.format, "format", "", "`format`: png, gif, bmp, tiff or svg (from the file extension, png if none)")
+	fs.StringVar(&s.hash, "hash", "md5", "hash `algorithm` of email addresses: md5 or sha256")
+}
+
+// Helper to map the style to options
+func (s *style) options() ([]monsterid.Option, error) {
+	var opts []monsterid.Option
+	if s.size < 0 {
+		return nil, fmt.Errorf("invalid size %d", s.size)
+	}
+	if s.size > 0 {
+		opts = append(opts, monsterid.WithSize(s.size))
+	}
+	if s.theme != "" {
+		if !slices.Contains(monsterid.Themes(), monsterid.Theme(s.theme)) {
+			return nil, fmt.Errorf("unknown theme %q", s.theme)
+		}
+		opts = append(opts, monsterid.WithTheme(monsterid.Theme(s.theme)))
+	}
+	if s.kind != "" {
+		if !slices.Contains(monsterid.Styles(), monsterid.Style(s.kind)) {
+			return nil, fmt.Errorf("unknown style %q", s.kind)
+		}
+		opts = append(opts, monsterid.WithStyle(monsterid.Style(s.kind)))
+	}
+	if s.name != "" {
+		opts = append(opts, monsterid.WithInitials(s.name))
+	}
+	if s.bg != "" {
+		c, err := monsterid.ParseColor(s.bg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, monsterid.WithBackground(c))
+	}
+	if s.grey {
+		opts = append(opts, monsterid.WithGreyscale())
+	}
+
+	return opts, nil
+}
+
+// Helper to get the hash algorithm of email addresses
+func (s *style) algorithm() (monsterid.HashAlgorithm, error) {
+	switch strings.ToLower(s.hash) {
+	case "md5":
+		return monsterid.MD5, nil
+	case "sha256", "sha-256":
+		return monsterid.SHA256, nil
+	}
+
+	return 0, fmt.Errorf("unknown hash algorithm %q", s.hash)
+}
+
+// Helper to get the format of the style, or else of the extension of name
+func (s *style) formatFor(name string) (monsterid.Format, error) {
+	format := monsterid.Format(strings.ToLower(s.format))
+	if format == "" {
+		format = extensionFormats[strings.ToLower(filepath.Ext(name))]
+	}
+	if format == "" {
+		format = monsterid.FormatPNG
+	}
+	if format == "webp" {
+		// There is no WebP encoder in the standard library
+		return "", errors.New("webp is not supported, use png or svg instead")
+	}
+	if format.ContentType() == "" {
+		return "", fmt.Errorf("unknown format %q", s.format)
+	}
+
+	return format, nil
+}
+
+// extensionFormats are the formats of file extensions.
+var extensionFormats = map[string]monsterid.Format{
+	".png":  monsterid.FormatPNG,
+	".gif":  monsterid.FormatGIF,
+	".bmp":  monsterid.FormatBMP,
+	".tif":  monsterid.FormatTIFF,
+	".tiff": monsterid.FormatTIFF,
+	".svg":  monsterid.FormatSVG,
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/weavatar/monsterid"
+)
+
+func TestRun(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	if err := run(nil, io.Discard, stderr); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Expected help without a command, got %v", err)
+	}
+	if !bytes.Contains(stderr.Bytes(), []byte("gen")) {
+		t.Errorf("Expected the usage to list the commands, got %q", stderr)
+	}
+	if err := run([]string{"paint"}, io.Discard, io.Discard); err == nil {
+		t.Error("Expected an error for an unknown command")
+	}
+}
+
+func TestStyleFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		name   string
+		want   monsterid.Format
+	}{
+		{"", "avatar.png", monsterid.FormatPNG},
+		{"", "avatar.SVG", monsterid.FormatSVG},
+		{"", "avatar.tif", monsterid.FormatTIFF},
+		{"", "-", monsterid.FormatPNG},
+		{"", "avatar.jpg", monsterid.FormatPNG},
+		{"gif", "avatar.png", monsterid.FormatGIF},
+	}
+
+	for _, test := range tests {
+		s := style{format: test.format}
+		got, err := s.formatFor(test.name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != test.want {
+			t.Errorf("Expected %q for %q and %q, got %q", test.want, test.format, test.name, got)
+		}
+	}
+
+	s := style{format: "webp"}
+	if _, err := s.formatFor("avatar.webp"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestStyleOptions(t *testing.T) {
+	s := style{size: 64, theme: "robot", bg: "#ff000080", grey: true}
+	opts, err := s.options()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []monsterid.Option{monsterid.WithSize(64), monsterid.WithTheme(monsterid.ThemeRobot),
+		monsterid.WithBackground(color.RGBA{R: 0x80, A: 0x80}), monsterid.WithGreyscale()}
+	if !bytes.Equal(render(t, opts), render(t, want)) {
+		t.Error("Expected the flags to map to their options")
+	}
+
+	s = style{size: 32, kind: "identicon"}
+	opts, err = s.options()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(render(t, opts), render(t, []monsterid.Option{monsterid.WithSize(32), monsterid.WithStyle(monsterid.StyleIdenticon)})) {
+		t.Error("Expected -style to select the style")
+	}
+
+	s = style{kind: "initials", name: "Ada Lovelace"}
+	opts, err = s.options()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(render(t, opts), render(t, []monsterid.Option{monsterid.WithStyle(monsterid.StyleInitials), monsterid.WithInitials("Ada Lovelace")})) {
+		t.Error("Expected -name to set the initials")
+	}
+
+	for _, s := range []style{{size: -1}, {theme: "space"}, {kind: "cubist"}, {bg: "red"}, {bg: "fff"}} {
+		if _, err := s.options(); err == nil {
+			t.Errorf("Expected an error for %+v", s)
+		}
+	}
+	if _, err := (&style{hash: "sha1"}).algorithm(); err == nil {
+		t.Error("Expected an error for an unknown hash algorithm")
+	}
+}
+
+// Helper to render the PNG of alice with opts
+func render(t *testing.T, opts []monsterid.Option) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	if err := monsterid.Render(buf, []byte("alice"), monsterid.FormatPNG, opts...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return buf.Bytes()
+}
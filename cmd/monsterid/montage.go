@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/weavatar/monsterid"
+)
+
+// Helper to run monsterid montage, writing a contact sheet of random or
+// listed monsters to review the variety of a part pack
+func runMontage(args []string, stdout, stderr io.Writer) error {
+	var s style
+	fs := flag.NewFlagSet("monsterid montage", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	count := fs.Int("count", 100, "draw this many random monsters without listed identifiers")
+	cols := fs.Int("cols", 10, "draw this many monsters per row")
+	gap := fs.Int("gap", 4, "leave this many `pixels` between monsters")
+	seed := fs.Uint64("seed", 0, "draw the random monsters of this `seed`, a new one if zero")
+	in := fs.String("in", "", "draw the identifiers in this `file`, one per line, instead of random ones")
+	pack := fs.String("pack", "", "render with the part pack in this `directory` instead of the embedded parts")
+	out := fs.String("out", "-", "write the PNG to this `file`, stdout if -")
+	s.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: monsterid montage [flags] [identifier...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *cols < 1 || *count < 1 || *gap < 0 {
+		return errors.New("montage needs positive -count and -cols and a -gap of at least 0")
+	}
+
+	opts, err := s.options()
+	if err != nil {
+		return err
+	}
+	if format, err := s.formatFor(*out); err != nil {
+		return err
+	} else if format != monsterid.FormatPNG {
+		return fmt.Errorf("montage only writes png, not %s", format)
+	}
+	g, err := newGenerator(*pack, s.theme)
+	if err != nil {
+		return err
+	}
+
+	idents := fs.Args()
+	if *in != "" {
+		listed, err := readLines(*in)
+		if err != nil {
+			return err
+		}
+		idents = append(idents, listed...)
+	}
+	if len(idents) == 0 {
+		if *seed == 0 {
+			*seed = uint64(time.Now().UnixNano())
+			// Print the seed so a sheet worth a second look can be drawn again
+			fmt.Fprintf(stderr, "monsterid: seed %d\n", *seed)
+		}
+		idents = randomIdentifiers(*seed, *count)
+	}
+
+	cell := s.size
+	if cell == 0 {
+		cell = monsterid.DefaultOptions().Size
+	}
+	sheet, err := drawMontage(g, idents, cell, *cols, *gap, opts)
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		return png.Encode(stdout, sheet)
+	}
+
+	return writeFile(*out, func(w io.Writer) error {
+		return png.Encode(w, sheet)
+	})
+}
+
+// Helper to draw the monsters of idents of cell pixels in a grid of cols
+// columns, gap pixels apart on white
+func drawMontage(g *monsterid.Generator, idents []string, cell, cols, gap int, opts []monsterid.Option) (*image.RGBA, error) {
+	cols = min(cols, len(idents))
+	rows := (len(idents) + cols - 1) / cols
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*(cell+gap)+gap, rows*(cell+gap)+gap))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for i, ident := range idents {
+		at := image.Pt(gap+(i%cols)*(cell+gap), gap+(i/cols)*(cell+gap))
+		if err := g.DrawTo(sheet, at, []byte(ident), opts...); err != nil {
+			return nil, fmt.Errorf("%s: %w", ident, err)
+		}
+	}
+
+	return sheet, nil
+}
+
+// Helper to make n random identifiers of seed
+func randomIdentifiers(seed uint64, n int) []string {
+	r := rand.New(rand.NewPCG(seed, seed))
+	idents := make([]string, n)
+	for i := range idents {
+		idents[i] = fmt.Sprintf("%016x", r.Uint64())
+	}
+
+	return idents
+}
+
+// Helper to read the non-empty lines of a file
+func readLines(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// Helper to create a generator of the part pack in dir, or of the embedded
+// parts of theme if dir is empty. A pack has no themes, so both can't be set.
+func newGenerator(dir, theme string) (*monsterid.Generator, error) {
+	if dir == "" {
+		return monsterid.NewThemeGenerator(cmp.Or(monsterid.Theme(theme), monsterid.ThemeClassic))
+	}
+	if theme != "" {
+		return nil, errors.New("-theme selects embedded parts and can't be combined with -pack")
+	}
+
+	return monsterid.NewGeneratorFromFS(os.DirFS(dir))
+}
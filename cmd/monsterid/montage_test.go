@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/weavatar/monsterid"
+)
+
+func TestMontage(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(list, []byte("carol\n\ndave\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stdout := new(bytes.Buffer)
+	args := []string{"montage", "-in", list, "-cols", "2", "-gap", "2", "-size", "32", "alice", "bob"}
+	if err := run(args, stdout, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sheet, err := png.Decode(stdout)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if got, want := sheet.Bounds(), image.Rect(0, 0, 2*34+2, 2*34+2); got != want {
+		t.Fatalf("Expected a %v sheet, got %v", want, got)
+	}
+
+	for i, ident := range []string{"alice", "bob", "carol", "dave"} {
+		want := monsterid.New([]byte(ident), monsterid.WithSize(32))
+		at := image.Pt(2+(i%2)*34, 2+(i/2)*34)
+		cell := image.NewRGBA(image.Rect(0, 0, 32, 32))
+		draw.Draw(cell, cell.Bounds(), sheet, at, draw.Src)
+		if !bytes.Equal(cell.Pix, toRGBA(want).Pix) {
+			t.Errorf("Expected %s at %v", ident, at)
+		}
+	}
+}
+
+func TestMontageRandom(t *testing.T) {
+	dir := t.TempDir()
+	sheets := make([][]byte, 2)
+	for i := range sheets {
+		out := filepath.Join(dir, "sheet.png")
+		if err := runMontage([]string{"-count", "6", "-cols", "3", "-size", "16", "-seed", "7", "-out", out}, io.Discard, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		sheets[i] = data
+	}
+	if !bytes.Equal(sheets[0], sheets[1]) {
+		t.Error("Expected a seed to draw the same monsters")
+	}
+
+	stderr := new(bytes.Buffer)
+	if err := runMontage([]string{"-count", "2", "-size", "16", "-pack", "../../parts"}, io.Discard, stderr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Contains(stderr.Bytes(), []byte("seed ")) {
+		t.Errorf("Expected the random seed to be reported, got %q", stderr)
+	}
+}
+
+func TestMontageTheme(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	if err := run([]string{"montage", "-theme", "robot", "-size", "32", "-gap", "0", "alice"}, stdout, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sheet, err := png.Decode(stdout)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	want := monsterid.New([]byte("alice"), monsterid.WithSize(32), monsterid.WithTheme(monsterid.ThemeRobot))
+	if !bytes.Equal(toRGBA(sheet).Pix, toRGBA(want).Pix) {
+		t.Error("Expected the monster of the robot theme")
+	}
+
+	err = runMontage([]string{"-pack", t.TempDir(), "-theme", "robot"}, io.Discard, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "-theme") {
+		t.Errorf("Expected an error for -theme with -pack, got %v", err)
+	}
+}
+
+func TestMontageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-cols", "0"},
+		{"-count", "0"},
+		{"-gap", "-1"},
+		{"-format", "svg"},
+		{"-out", "sheet.gif"},
+		{"-pack", filepath.Join(t.TempDir(), "missing")},
+		{"-in", filepath.Join(t.TempDir(), "missing.txt")},
+	} {
+		if err := runMontage(args, io.Discard, io.Discard); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+// Helper to convert an image to RGBA
+func toRGBA(img image.Image) *image.RGBA {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst
+}
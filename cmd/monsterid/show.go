@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/weavatar/monsterid"
+)
+
+// showSize is the size monsterid show draws at without -size, a third of the
+// width of a classic terminal.
+const showSize = 32
+
+// Helper to run monsterid show, drawing the avatar of a hash, identifier or
+// email address in the terminal
+func runShow(args []string, stdout, stderr io.Writer) error {
+	var s style
+	fs := flag.NewFlagSet("monsterid show", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	email := fs.Bool("email", false, "hash the argument as an email address")
+	mode := fs.String("mode", "auto", "draw with `mode`: ansi half blocks, sixel graphics or auto to detect sixel support")
+	s.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: monsterid show [flags] <hash>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("show needs one hash, identifier or email address")
+	}
+	if s.format != "" {
+		return errors.New("show draws in the terminal, -format doesn't apply")
+	}
+	if s.size == 0 {
+		s.size = showSize
+	}
+
+	opts, err := s.options()
+	if err != nil {
+		return err
+	}
+	hash := fs.Arg(0)
+	if *email {
+		algo, err := s.algorithm()
+		if err != nil {
+			return err
+		}
+		hash = monsterid.HashString(monsterid.NormalizeEmail(hash), algo)
+	}
+
+	switch *mode {
+	case "auto":
+		if sixelTerminal(os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")) {
+			return monsterid.EncodeSixel(stdout, []byte(hash), opts...)
+		}
+		return monsterid.EncodeANSI(stdout, []byte(hash), opts...)
+	case "ansi":
+		return monsterid.EncodeANSI(stdout, []byte(hash), opts...)
+	case "sixel":
+		return monsterid.EncodeSixel(stdout, []byte(hash), opts...)
+	}
+
+	return fmt.Errorf("unknown mode %q", *mode)
+}
+
+// Helper to guess if the terminal of the TERM and TERM_PROGRAM variables
+// shows sixel graphics, as asking the terminal needs a raw tty
+func sixelTerminal(term, program string) bool {
+	switch {
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "mlterm"), strings.HasPrefix(term, "foot"):
+		return true
+	case program == "WezTerm", program == "mintty":
+		return true
+	}
+
+	return false
+}
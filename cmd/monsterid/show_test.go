@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/weavatar/monsterid"
+)
+
+func TestShow(t *testing.T) {
+	tests := []struct {
+		args   []string
+		encode func(w io.Writer, hash []byte, opts ...monsterid.Option) error
+		hash   string
+		size   int
+	}{
+		{[]string{"-mode", "ansi", "alice"}, monsterid.EncodeANSI, "alice", showSize},
+		{[]string{"-mode", "sixel", "-size", "24", "alice"}, monsterid.EncodeSixel, "alice", 24},
+		{[]string{"-mode", "ansi", "-email", "-size", "8", "User@Example.com"}, monsterid.EncodeANSI, monsterid.HashString("user@example.com", monsterid.MD5), 8},
+	}
+
+	for _, test := range tests {
+		stdout := new(bytes.Buffer)
+		if err := run(append([]string{"show"}, test.args...), stdout, io.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := new(bytes.Buffer)
+		if err := test.encode(want, []byte(test.hash), monsterid.WithSize(test.size)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(stdout.Bytes(), want.Bytes()) {
+			t.Errorf("Expected %q to draw %s at %d pixels", test.args, test.hash, test.size)
+		}
+	}
+
+	for _, args := range [][]string{{}, {"alice", "bob"}, {"-mode", "kitty", "alice"}, {"-format", "png", "alice"}} {
+		if err := runShow(args, io.Discard, io.Discard); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+func TestSixelTerminal(t *testing.T) {
+	tests := []struct {
+		term, program string
+		want          bool
+	}{
+		{"xterm-256color", "", false},
+		{"xterm-256color", "WezTerm", true},
+		{"foot", "", true},
+		{"mlterm", "", true},
+		{"yaft-sixel", "", true},
+		{"screen", "Apple_Terminal", false},
+	}
+
+	for _, test := range tests {
+		if got := sixelTerminal(test.term, test.program); got != test.want {
+			t.Errorf("Expected %v for %q and %q, got %v", test.want, test.term, test.program, got)
+		}
+	}
+}
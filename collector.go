@@ -0,0 +1,161 @@
+package monsterid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// durationBuckets are the upper bounds of the duration histograms in
+// seconds, the default buckets of Prometheus clients.
+var durationBuckets = [...]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector counts what a Handler serves and how long rendering takes, and
+// exposes it in the Prometheus text format as an http.Handler, so it can be
+// scraped at an endpoint such as /metrics without a Prometheus client
+// library. It is safe for concurrent use and can be shared by handlers.
+type Collector struct {
+	requests    [len(formats)]atomic.Int64 // avatars served by format
+	renders     atomic.Int64               // avatars rendered rather than served from the cache
+	cacheHits   atomic.Int64               // avatars served from the cache
+	cacheMisses atomic.Int64               // avatars missing from the cache
+	bytes       atomic.Int64               // bytes of avatars served
+	unavailable atomic.Int64               // avatars not rendered in time or canceled
+	render      histogram                  // durations of composing avatars
+	encode      histogram                  // durations of encoding avatars
+}
+
+// formats are the formats counted by a Collector, in the order of its
+// counters.
+var formats = [...]Format{FormatPNG, FormatGIF, FormatBMP, FormatTIFF, FormatSVG}
+
+// histogram is a Prometheus histogram of durations in seconds.
+type histogram struct {
+	counts [len(durationBuckets) + 1]atomic.Int64 // observations per bucket, the last one above all bounds
+	sum    atomic.Uint64                          // bits of the float64 sum of the observations
+}
+
+// NewCollector creates a Collector, to pass to handlers with
+// HandlerConfig.Collector.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	writeHeader(bw, "monsterid_requests_total", "counter", "Avatars served by format.")
+	for i, format := range formats {
+		fmt.Fprintf(bw, "monsterid_requests_total{format=%q} %d\n", format, c.requests[i].Load())
+	}
+	writeCounter(bw, "monsterid_renders_total", "Avatars rendered rather than served from the cache.", c.renders.Load())
+	writeCounter(bw, "monsterid_cache_hits_total", "Avatars served from the cache.", c.cacheHits.Load())
+	writeCounter(bw, "monsterid_cache_misses_total", "Avatars missing from the cache.", c.cacheMisses.Load())
+	writeCounter(bw, "monsterid_response_bytes_total", "Bytes of avatars served.", c.bytes.Load())
+	writeCounter(bw, "monsterid_unavailable_total", "Avatars not rendered in time or canceled.", c.unavailable.Load())
+	c.render.write(bw, "monsterid_render_duration_seconds", "Time spent composing avatars.")
+	c.encode.write(bw, "monsterid_encode_duration_seconds", "Time spent encoding avatars.")
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Helper to count an avatar served in format, nothing if c is nil
+func (c *Collector) served(format Format, n int64) {
+	if c == nil {
+		return
+	}
+	for i, f := range formats {
+		if f == format {
+			c.requests[i].Add(1)
+		}
+	}
+	c.bytes.Add(n)
+}
+
+// Helper to count a rendered avatar and how long it took, nothing if c is nil
+func (c *Collector) rendered(times renderTimes) {
+	if c == nil {
+		return
+	}
+	c.renders.Add(1)
+	c.render.observe(times.render)
+	if times.encode > 0 {
+		c.encode.observe(times.encode)
+	}
+}
+
+// Helper to count a lookup in the cache, nothing if c is nil
+func (c *Collector) cached(hit bool) {
+	if c == nil {
+		return
+	}
+	if hit {
+		c.cacheHits.Add(1)
+	} else {
+		c.cacheMisses.Add(1)
+	}
+}
+
+// Helper to count an avatar that wasn't rendered in time, nothing if c is nil
+func (c *Collector) timedOut() {
+	if c == nil {
+		return
+	}
+	c.unavailable.Add(1)
+}
+
+// Helper to add a duration to the histogram
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+	i := 0
+	for i < len(durationBuckets) && v > durationBuckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Helper to write the histogram with cumulative buckets
+func (h *histogram) write(w *bufio.Writer, name, help string) {
+	writeHeader(w, name, "histogram", help)
+	var count int64
+	for i, le := range durationBuckets {
+		count += h.counts[i].Load()
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, strconv.FormatFloat(le, 'g', -1, 64), count)
+	}
+	count += h.counts[len(durationBuckets)].Load()
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(math.Float64frombits(h.sum.Load()), 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, count)
+}
+
+// Helper to write a counter
+func writeCounter(w *bufio.Writer, name, help string, v int64) {
+	writeHeader(w, name, "counter", help)
+	fmt.Fprintf(w, "%s %d\n", name, v)
+}
+
+// Helper to write the HELP and TYPE lines of a metric
+func writeHeader(w *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
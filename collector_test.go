@@ -0,0 +1,69 @@
+package monsterid
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	h := Handler(HandlerConfig{Collector: c, CacheBytes: 1 << 20})
+
+	var served int
+	for _, target := range []string{"/alice", "/alice", "/bob?format=svg", "/carol?format=gif", "/alice?s=0"} {
+		if rec := serve(h, http.MethodGet, target); rec.Code == http.StatusOK {
+			served += rec.Body.Len()
+		}
+	}
+
+	rec := serve(c, http.MethodGet, "/metrics")
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus text format, got %s", ct)
+	}
+	metrics := rec.Body.String()
+	for _, want := range []string{
+		`monsterid_requests_total{format="png"} 2`,
+		`monsterid_requests_total{format="gif"} 1`,
+		`monsterid_requests_total{format="svg"} 1`,
+		`monsterid_requests_total{format="bmp"} 0`,
+		"monsterid_renders_total 3",
+		"monsterid_cache_hits_total 1",
+		"monsterid_cache_misses_total 3",
+		"monsterid_response_bytes_total " + strconv.Itoa(served),
+		`monsterid_render_duration_seconds_bucket{le="+Inf"} 3`,
+		"monsterid_render_duration_seconds_count 3",
+		"monsterid_encode_duration_seconds_count 2",
+		"# TYPE monsterid_render_duration_seconds histogram",
+		"# TYPE monsterid_renders_total counter",
+	} {
+		if !strings.Contains(metrics, want+"\n") {
+			t.Errorf("Expected the metrics to contain %q", want)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	c := NewCollector()
+	for _, d := range []time.Duration{time.Millisecond, 5 * time.Millisecond, 20 * time.Millisecond, time.Minute} {
+		c.render.observe(d)
+	}
+
+	sb := new(strings.Builder)
+	c.WriteTo(sb)
+	for _, want := range []string{
+		`monsterid_render_duration_seconds_bucket{le="0.005"} 2`,
+		`monsterid_render_duration_seconds_bucket{le="0.01"} 2`,
+		`monsterid_render_duration_seconds_bucket{le="0.025"} 3`,
+		`monsterid_render_duration_seconds_bucket{le="10"} 3`,
+		`monsterid_render_duration_seconds_bucket{le="+Inf"} 4`,
+		"monsterid_render_duration_seconds_sum 60.026",
+		"monsterid_render_duration_seconds_count 4",
+	} {
+		if !strings.Contains(sb.String(), want+"\n") {
+			t.Errorf("Expected the histogram to contain %q, got\n%s", want, sb)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package monsterid
+
+import (
+	"image"
+	"math"
+)
+
+// colorCacheSaturations is the number of steps saturation is rounded to with
+// hue buckets, so similar monsters share their colorized parts.
+const colorCacheSaturations = 20
+
+// defaultColorCacheBytes is the budget of the colorized parts if
+// GeneratorConfig.ColorCacheBytes is zero.
+const defaultColorCacheBytes = 64 << 20
+
+// colorCache keeps parts colorized with quantized colors, so hot paths skip
+// colorizing them pixel by pixel.
+type colorCache struct {
+	buckets int                  // number of hue steps
+	parts   *partCache[colorKey] // recently used colorized parts
+}
+
+// colorKey is a colorized part by file name, scale and quantized color.
+type colorKey struct {
+	fileName   string
+	scale      int
+	hue        int // hue bucket
+	saturation int // saturation step
+	shift      float64
+	greyscale  bool
+}
+
+// Helper to create a color cache with buckets hue steps holding at most
+// budget bytes of pixels
+func newColorCache(buckets int, budget int64) *colorCache {
+	if budget <= 0 {
+		budget = defaultColorCacheBytes
+	}
+
+	return &colorCache{buckets: buckets, parts: newPartCache[colorKey](budget)}
+}
+
+// Helper to get a part colorized with its hue and saturation rounded to the
+// closest steps, colorizing a copy of img on first use. The result must not
+// be modified.
+func (c *colorCache) colorize(fileName string, scale int, img, mask *image.RGBA, hue, saturation, shift float64, greyscale bool) *image.RGBA {
+	key := colorKey{
+		fileName:   fileName,
+		scale:      scale,
+		hue:        int(math.Round(hue*float64(c.buckets))) % c.buckets,
+		saturation: int(math.Round(saturation * colorCacheSaturations)),
+		shift:      shift,
+		greyscale:  greyscale,
+	}
+
+	// Colorizing never fails
+	colorized, _ := c.parts.get(key, func() (*image.RGBA, error) {
+		colorized := cloneImage(img)
+		colorizePart(colorized, mask, float64(key.hue)/float64(c.buckets),
+			float64(key.saturation)/colorCacheSaturations, shift, greyscale)
+		return colorized, nil
+	})
+
+	return colorized
+}
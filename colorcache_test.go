@@ -0,0 +1,91 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io/fs"
+	"math"
+	"testing"
+)
+
+func TestGeneratorHueBuckets(t *testing.T) {
+	sub, err := fs.Sub(parts, "parts")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	const buckets = 36
+	g, err := NewGeneratorWithConfig(GeneratorConfig{Packs: []fs.FS{sub}, HueBuckets: buckets})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	exact, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	snap := func(v float64, steps int) float64 {
+		if v < 0 {
+			return v
+		}
+		return float64(int(math.Round(v*float64(steps)))%steps) / float64(steps)
+	}
+
+	for i := 0; i < 20; i++ {
+		hash := []byte(fmt.Sprintf("buckets-%d", i))
+		for _, opts := range [][]Option{nil, {WithSize(240), WithAlgorithmVersion(V2)}, {WithGreyscale()}} {
+			got, err := g.Generate(hash, opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Colorized like the exact colors rounded to the buckets
+			d := exact.Describe(hash, opts...)
+			d.Hue, d.LegsHue, d.ArmsHue = snap(d.Hue, buckets), snap(d.LegsHue, buckets), snap(d.ArmsHue, buckets)
+			d.Saturation = math.Round(d.Saturation*colorCacheSaturations) / colorCacheSaturations
+			want, err := exact.FromParts(d, opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got.(*image.RGBA).Pix, want.(*image.RGBA).Pix) {
+				t.Errorf("Expected %q to be colorized with the rounded colors", hash)
+			}
+		}
+	}
+
+	// Rendering again colorizes nothing new
+	c := g.set.Load().colorized.parts
+	n := c.order.Len()
+	if n == 0 {
+		t.Fatal("Expected colorized parts to be cached")
+	}
+	for i := 0; i < 20; i++ {
+		if _, err := g.Generate([]byte(fmt.Sprintf("buckets-%d", i))); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if c.order.Len() != n {
+		t.Errorf("Expected %d cached parts, got %d", n, c.order.Len())
+	}
+}
+
+func BenchmarkGeneratorHueBuckets(b *testing.B) {
+	sub, err := fs.Sub(parts, "parts")
+	if err != nil {
+		b.Fatal(err)
+	}
+	g, err := NewGeneratorWithConfig(GeneratorConfig{Packs: []fs.FS{sub}, HueBuckets: 36})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		img, err := g.Generate([]byte(fmt.Sprintf("benchmark-%d", i%64)), WithSize(240))
+		if err != nil {
+			b.Fatal(err)
+		}
+		ReleaseImage(img)
+	}
+}
@@ -0,0 +1,134 @@
+package monsterid
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// colorRule is how the parts of a category are colorized in artistic mode,
+// as declared in the "colorize" object of a pack manifest:
+//
+//	{"body": {"hues": [[0.5, 0.75]]}, "legs": {"chance": 0.3}, "hair": {"body": true}}
+//
+// The body always gets a hue of its own, arms and legs with the probability
+// Chance and other categories can only follow the body. Categories without a
+// rule keep the colors of their artwork.
+type colorRule struct {
+	Chance float64      `json:"chance"` // probability of a hue of its own, only for arms and legs
+	Hues   [][2]float64 `json:"hues"`   // ranges the hue is picked from, any hue if empty
+	Body   bool         `json:"body"`   // use the body hue when there is no hue of its own
+}
+
+// colorRules are the colorization rules by category, nil for the default.
+type colorRules map[string]colorRule
+
+// defaultColorRules colorize the body and give arms and legs a hue of their
+// own with a probability of 30%, as for the classic parts.
+var defaultColorRules = colorRules{
+	"body": {},
+	"legs": {Chance: 0.3},
+	"arms": {Chance: 0.3},
+}
+
+// ownHueParts are the categories that can have a hue of their own, as the
+// Descriptor stores the hue of these only.
+var ownHueParts = []string{"body", "legs", "arms"}
+
+// Helper to get the rules, the default ones unless set
+func (c colorRules) rules() colorRules {
+	if c == nil {
+		return defaultColorRules
+	}
+
+	return c
+}
+
+// Helper to check that the rules only use known categories and valid ranges
+func (c colorRules) validate() error {
+	for part, rule := range c {
+		if !slices.Contains(bodyParts, part) {
+			return fmt.Errorf("monsterid: colorize rule for unknown category %q", part)
+		}
+		own := slices.Contains(ownHueParts, part)
+		if !own && (rule.Chance != 0 || len(rule.Hues) > 0) {
+			return fmt.Errorf("monsterid: %s parts can only be colorized with the body hue", part)
+		}
+		if part == "body" && (rule.Chance != 0 || rule.Body) {
+			return fmt.Errorf("monsterid: body parts always get a hue of their own")
+		}
+		if rule.Chance < 0 || rule.Chance > 1 {
+			return fmt.Errorf("monsterid: %s colorize chance %g out of range 0-1", part, rule.Chance)
+		}
+
+		total := 0.0
+		for _, h := range rule.Hues {
+			if h[0] < 0 || h[1] > 1 || h[0] > h[1] {
+				return fmt.Errorf("monsterid: %s hue range %g-%g out of range 0-1", part, h[0], h[1])
+			}
+			total += h[1] - h[0]
+		}
+		if len(rule.Hues) > 0 && total == 0 {
+			return fmt.Errorf("monsterid: %s hue ranges are empty", part)
+		}
+	}
+
+	return nil
+}
+
+// Helper to map a random value in 0-1 into the allowed hue ranges, keeping
+// the distribution uniform over their total width
+func (r colorRule) hue(v float64) float64 {
+	if len(r.Hues) == 0 {
+		return v
+	}
+
+	total := 0.0
+	for _, h := range r.Hues {
+		total += h[1] - h[0]
+	}
+	t := v * total
+	for _, h := range r.Hues {
+		w := h[1] - h[0]
+		if t < w {
+			return h[0] + t
+		}
+		t -= w
+	}
+
+	return r.Hues[len(r.Hues)-1][1]
+}
+
+// Helper to draw the own hue of a limb, -1 if it has none
+func (c colorRules) limbHue(r *rand.Rand, part string) float64 {
+	rule, ok := c.rules()[part]
+	if !ok {
+		return -1
+	}
+	if r.Float64() < rule.Chance {
+		return rule.hue(r.Float64())
+	}
+
+	return -1
+}
+
+// Helper to get the hue a part is colorized with, -1 if it keeps the colors
+// of its artwork
+func (c colorRules) partHue(d *Descriptor, part string) float64 {
+	rule, ok := c.rules()[part]
+	switch {
+	case !ok:
+		return -1
+	case part == "body":
+		return d.Hue
+	case part == "legs" || part == "arms":
+		if hue := getPartHue(d, part); hue >= 0 {
+			return hue
+		}
+	}
+	if rule.Body {
+		return d.Hue
+	}
+
+	return -1
+}
@@ -0,0 +1,134 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"testing"
+	"testing/fstest"
+)
+
+// Helper to create a Generator from a pack of the embedded parts with a
+// manifest colorizing them by rules
+func colorizeGenerator(t *testing.T, rules string) *Generator {
+	t.Helper()
+
+	fsys := testPack(t, 3)
+	fsys[manifestName] = &fstest.MapFile{Data: []byte(fmt.Sprintf(
+		`{"parts": {"legs": 3, "hair": 3, "arms": 3, "body": 3, "eyes": 3, "mouth": 3}, "colorize": %s}`, rules))}
+
+	g, err := NewGeneratorFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return g
+}
+
+func TestColorRulesValidate(t *testing.T) {
+	tests := []struct {
+		rules colorRules
+		valid bool
+	}{
+		{nil, true},
+		{defaultColorRules, true},
+		{colorRules{"body": {Hues: [][2]float64{{0.5, 0.7}}}, "hair": {Body: true}}, true},
+		{colorRules{"tail": {}}, false},
+		{colorRules{"eyes": {Chance: 0.5}}, false},
+		{colorRules{"mouth": {Hues: [][2]float64{{0, 1}}}}, false},
+		{colorRules{"body": {Chance: 0.5}}, false},
+		{colorRules{"legs": {Chance: 1.5}}, false},
+		{colorRules{"arms": {Chance: 0.3, Hues: [][2]float64{{0.8, 0.2}}}}, false},
+		{colorRules{"body": {Hues: [][2]float64{{0.4, 0.4}}}}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.rules.validate(); (err == nil) != test.valid {
+			t.Errorf("Expected %v to be valid: %v, got %v", test.rules, test.valid, err)
+		}
+	}
+}
+
+func TestColorRuleHue(t *testing.T) {
+	rule := colorRule{Hues: [][2]float64{{0, 0.1}, {0.6, 0.7}}}
+
+	tests := []struct {
+		v, want float64
+	}{
+		{0, 0},
+		{0.25, 0.05},
+		{0.6, 0.62},
+		{0.75, 0.65},
+		{1, 0.7},
+	}
+
+	for _, test := range tests {
+		if got := rule.hue(test.v); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Expected hue %g for %g, got %g", test.want, test.v, got)
+		}
+	}
+}
+
+func TestColorizeHueRanges(t *testing.T) {
+	g := colorizeGenerator(t, `{"body": {"hues": [[0.5, 0.6]]}, "legs": {"chance": 1, "hues": [[0.1, 0.2]]}}`)
+
+	for i := 0; i < 50; i++ {
+		d := g.Describe([]byte(fmt.Sprintf("colorize-%d", i)))
+		if d.Hue < 0.5 || d.Hue > 0.6 {
+			t.Fatalf("Expected a body hue in 0.5-0.6, got %g", d.Hue)
+		}
+		if d.LegsHue < 0.1 || d.LegsHue > 0.2 {
+			t.Fatalf("Expected a legs hue in 0.1-0.2, got %g", d.LegsHue)
+		}
+		if d.ArmsHue != -1 {
+			t.Fatalf("Expected arms without a hue, got %g", d.ArmsHue)
+		}
+	}
+}
+
+func TestColorizeRules(t *testing.T) {
+	hash := []byte("colorize-rules")
+
+	// Without rules, no part is colorized
+	none := colorizeGenerator(t, `{}`)
+	img, err := none.Generate(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	plain, err := none.Generate(hash, WithArtistic(false))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(img.(*image.RGBA).Pix, plain.(*image.RGBA).Pix) {
+		t.Error("Expected no colorization without rules")
+	}
+
+	// Following the body hue colorizes the mouth too, the third one has
+	// colors to tint
+	body := colorizeGenerator(t, `{"body": {}}`)
+	for i := 0; body.Describe(hash).Mouth != 3; i++ {
+		hash = []byte(fmt.Sprintf("colorize-rules-%d", i))
+	}
+	img, err = body.Generate(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mouth, err := colorizeGenerator(t, `{"body": {}, "mouth": {"body": true}}`).Generate(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.Equal(img.(*image.RGBA).Pix, mouth.(*image.RGBA).Pix) {
+		t.Error("Expected the mouth to be colorized")
+	}
+}
+
+func TestColorizeInvalidManifest(t *testing.T) {
+	fsys := testPack(t, 1)
+	fsys[manifestName] = &fstest.MapFile{Data: []byte(
+		`{"parts": {"legs": 1, "hair": 1, "arms": 1, "body": 1, "eyes": 1, "mouth": 1}, "colorize": {"eyes": {"chance": 0.5}}}`)}
+
+	if _, err := NewGeneratorFromFS(fsys); err == nil {
+		t.Error("Expected an error for an invalid colorize rule")
+	}
+}
@@ -0,0 +1,64 @@
+package monsterid
+
+import (
+	"image/color"
+	"math"
+)
+
+// Helper to get the lightness shift for colorized parts, darkening or
+// lightening the body until it meets Options.MinContrast against the background
+func lightnessShift(d Descriptor, o Options) float64 {
+	shift := o.LightnessShift
+	if o.MinContrast <= 0 || o.Background.A == 0 {
+		return shift
+	}
+
+	// Move away from the background's luminance in small steps
+	bg := relativeLuminance(o.Background)
+	step := 0.05
+	if bg > 0.5 {
+		step = -step
+	}
+
+	for range 20 {
+		if contrastRatio(bodyLuminance(d, shift), bg) >= o.MinContrast {
+			break
+		}
+		next := shift + step
+		if next < -1 || next > 1 {
+			break
+		}
+		shift = next
+	}
+
+	return shift
+}
+
+// Helper to estimate the luminance of the body at its mid-tone lightness
+func bodyLuminance(d Descriptor, shift float64) float64 {
+	l := math.Max(0, math.Min(1, 0.5+shift))
+	r, g, b := hslToRgb(d.Hue, d.Saturation, l)
+	return relativeLuminance(color.RGBA{R: uint8(r * 255), G: uint8(g * 255), B: uint8(b * 255), A: 255})
+}
+
+// Helper to compute the WCAG relative luminance of a color
+func relativeLuminance(c color.RGBA) float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// Helper to compute the WCAG contrast ratio between two luminances
+func contrastRatio(l1, l2 float64) float64 {
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + 0.05) / (l2 + 0.05)
+}
@@ -0,0 +1,49 @@
+package monsterid
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	white := relativeLuminance(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	black := relativeLuminance(color.RGBA{A: 255})
+
+	if got := contrastRatio(white, black); math.Abs(got-21) > 0.01 {
+		t.Errorf("Expected 21:1 between white and black, got %.2f", got)
+	}
+	if got := contrastRatio(black, white); math.Abs(got-21) > 0.01 {
+		t.Errorf("Contrast ratio is not symmetric: %.2f", got)
+	}
+	if got := contrastRatio(white, white); got != 1 {
+		t.Errorf("Expected 1:1 for the same color, got %.2f", got)
+	}
+}
+
+func TestMinContrastAdjustsLightness(t *testing.T) {
+	backgrounds := []color.RGBA{
+		{R: 255, G: 255, B: 255, A: 255},
+		{R: 20, G: 20, B: 20, A: 255},
+		{R: 240, G: 240, B: 240, A: 255},
+	}
+
+	for _, bg := range backgrounds {
+		for i := 0; i < 50; i++ {
+			o := buildOptions([]Option{WithBackground(bg), WithMinContrast(3)})
+			d := describeHash([]byte{byte(i)}, o)
+
+			shift := lightnessShift(d, o)
+			if got := contrastRatio(bodyLuminance(d, shift), relativeLuminance(bg)); got < 3 {
+				t.Fatalf("Background %v, hash %d: contrast %.2f below 3 with shift %.2f", bg, i, got, shift)
+			}
+		}
+	}
+}
+
+func TestMinContrastDisabled(t *testing.T) {
+	o := buildOptions([]Option{WithLightnessShift(0.1)})
+	if got := lightnessShift(Describe([]byte("contrast")), o); got != 0.1 {
+		t.Errorf("Expected the configured shift without MinContrast, got %f", got)
+	}
+}
@@ -0,0 +1,333 @@
+package monsterid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// MonsterID describes a generated monster.
+//
+// Deprecated: use Descriptor.
+type MonsterID = Descriptor
+
+// Descriptor describes the parts and colors selected for a monster.
+type Descriptor struct {
+	Legs  int // legs part, 1-based
+	Hair  int // hair part, 1-based
+	Arms  int // arms part, 1-based
+	Body  int // body part, 1-based
+	Eyes  int // eyes part, 1-based
+	Mouth int // mouth part, 1-based
+
+	Hue        float64 // body hue, 0.0-1.0
+	Saturation float64 // body saturation, also used for arms and legs, see Options.SaturationRange
+	LegsHue    float64 // hue of recolored legs, -1 if they keep their original color
+	ArmsHue    float64 // hue of recolored arms, -1 if they keep their original color
+
+	Mirrored bool // flip the monster horizontally, only selected from V2 on
+
+	LegsJitter Jitter // small displacement of the legs with Options.Jitter
+	HairJitter Jitter // small displacement of the hair with Options.Jitter
+	ArmsJitter Jitter // small displacement of the arms with Options.Jitter
+
+	Accessory Accessory // item drawn over the monster, see Options.Accessories
+
+	Theme Theme // theme the parts are selected from, empty for ThemeClassic
+}
+
+// Jitter is a small translation and rotation applied to a part.
+type Jitter struct {
+	DX, DY int     // offset in native pixels
+	Angle  float64 // rotation around the center in radians
+}
+
+// Describe returns the parts and colors selected for the provided hash.
+// It panics if the options are invalid, use DescribeWithError to handle them.
+func Describe(hash []byte, opts ...Option) Descriptor {
+	d, err := DescribeWithError(hash, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// DescribeWithError returns the parts and colors selected for the provided
+// hash, returning an error instead of panicking if the options are invalid.
+func DescribeWithError(hash []byte, opts ...Option) (Descriptor, error) {
+	o := buildOptions(opts)
+	if err := o.checkVersion(); err != nil {
+		return Descriptor{}, err
+	}
+
+	return describeHash(hash, o), nil
+}
+
+// Helper to select parts and colors for a hash
+func describeHash(hash []byte, o Options) Descriptor {
+	d := describeSeed(hash, o, o.theme().pack())
+	if t := o.theme(); t != ThemeClassic {
+		d.Theme = t
+	}
+
+	return d
+}
+
+// seededRand is a random source with its generator, reused between monsters.
+type seededRand struct {
+	pcg rand.PCG
+	*rand.Rand
+}
+
+// rands reuses random sources, as the generator has no state of its own.
+var rands = sync.Pool{New: func() any {
+	r := new(seededRand)
+	r.Rand = rand.New(&r.pcg)
+	return r
+}}
+
+// Helper to select parts and colors for a hash out of p, with a reused
+// random source
+func describeSeed(hash []byte, o Options, p pack) Descriptor {
+	r := rands.Get().(*seededRand)
+	r.pcg.Seed(pcgSeed(hash, o))
+	d := describe(r.Rand, o, p)
+	rands.Put(r)
+
+	return d
+}
+
+// Helper to seed the random source for a hash
+func newRand(hash []byte, o Options) *rand.Rand {
+	return rand.New(rand.NewPCG(pcgSeed(hash, o)))
+}
+
+// Helper to derive the two seed words of the PCG generator for a hash. Up to
+// V2 both words come from the same 64-bit seed. From V3 on they are the
+// first 128 bits of the SHA-256 digest of the hash, so they are independent,
+// or with a HashFunc its seed spread by two steps of splitmix64.
+func pcgSeed(hash []byte, o Options) (uint64, uint64) {
+	if o.version() < V3 {
+		seed := hashSeed(hash, o)
+		return seed, (seed >> 1) | 1
+	}
+	if o.HashFunc != nil {
+		state := o.HashFunc(hash)
+		return splitmix64(&state), splitmix64(&state)
+	}
+
+	sum := sha256.Sum256(hash)
+	return binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16])
+}
+
+// Helper to advance a splitmix64 state and get its next output
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+
+	return z ^ z>>31
+}
+
+// Helper to reduce a hash to a seed
+func hashSeed(hash []byte, o Options) uint64 {
+	if o.HashFunc != nil {
+		return o.HashFunc(hash)
+	}
+
+	// FNV-64a, without allocating a hash.Hash64
+	seed := uint64(14695981039346656037)
+	for _, c := range hash {
+		seed ^= uint64(c)
+		seed *= 1099511628211
+	}
+
+	return seed
+}
+
+// Helper to select monster parts and colors out of the pack
+func describe(r *rand.Rand, o Options, p pack) Descriptor {
+	d := Descriptor{LegsHue: -1, ArmsHue: -1}
+	d.Legs = r.IntN(p.counts.count("legs")) + 1
+	d.Hair = r.IntN(p.counts.count("hair")) + 1
+	d.Arms = r.IntN(p.counts.count("arms")) + 1
+	d.Body = r.IntN(p.counts.count("body")) + 1
+	d.Eyes = r.IntN(p.counts.count("eyes")) + 1
+	d.Mouth = r.IntN(p.counts.count("mouth")) + 1
+
+	// Generate hue for body base color (for artistic mode)
+	d.Hue = r.Float64() // 0.0-1.0
+	if o.Hue == nil {
+		d.Hue = p.colors.rules()["body"].hue(d.Hue)
+	}
+	minSat, maxSat := o.saturationRange()
+	d.Saturation = minSat + r.Float64()*(maxSat-minSat) // 0.5-1.0 by default
+
+	// Constrain the body hue, reusing the random hue as the offset so the
+	// rest of the selection is unaffected
+	if o.Hue != nil {
+		d.Hue = wrapHue(*o.Hue + (d.Hue*2-1)*o.HueTolerance)
+	}
+
+	// Give arms and legs random colors, with 30% probability by default
+	if o.Artistic {
+		d.LegsHue = p.colors.limbHue(r, "legs")
+		d.ArmsHue = p.colors.limbHue(r, "arms")
+	}
+
+	// Drawn last so the selection above matches V1
+	if o.version() >= V2 {
+		d.Mirrored = r.IntN(2) == 1
+	}
+
+	// Also drawn after the V1 selection so enabling it only moves parts
+	if o.Jitter {
+		d.LegsJitter = randomJitter(r)
+		d.HairJitter = randomJitter(r)
+		d.ArmsJitter = randomJitter(r)
+	}
+
+	// Drawn last as well so only the accessory is added
+	if o.Accessories && r.Float64() < accessoryChance {
+		d.Accessory = randomAccessories[r.IntN(len(randomAccessories))]
+	}
+	if o.Accessory != AccessoryNone {
+		d.Accessory = o.Accessory
+	}
+
+	// Snap the generated colors to the closest palette entries
+	if len(o.Palette) > 0 {
+		d.Hue, d.Saturation = nearestPaletteColor(o.Palette, d.Hue)
+		if d.LegsHue >= 0 {
+			d.LegsHue, _ = nearestPaletteColor(o.Palette, d.LegsHue)
+		}
+		if d.ArmsHue >= 0 {
+			d.ArmsHue, _ = nearestPaletteColor(o.Palette, d.ArmsHue)
+		}
+	}
+
+	return d
+}
+
+// Helper to pick an offset of up to 3 pixels and a rotation of up to 8 degrees
+func randomJitter(r *rand.Rand) Jitter {
+	return Jitter{
+		DX:    r.IntN(7) - 3,
+		DY:    r.IntN(7) - 3,
+		Angle: (r.Float64()*2 - 1) * 8 * math.Pi / 180,
+	}
+}
+
+// Helper to find the hue and saturation of the palette color closest in hue to h
+func nearestPaletteColor(palette []color.Color, h float64) (float64, float64) {
+	bestHue, bestSat, bestDist := 0.0, 0.0, math.Inf(1)
+	for _, c := range palette {
+		r, g, b, _ := c.RGBA()
+		ph, ps, _ := rgbToHsl(float64(r)/0xFFFF, float64(g)/0xFFFF, float64(b)/0xFFFF)
+
+		// Hue is circular, so 0.95 is close to 0.05
+		dist := math.Abs(ph - h)
+		dist = math.Min(dist, 1-dist)
+		if dist < bestDist {
+			bestHue, bestSat, bestDist = ph, ps, dist
+		}
+	}
+
+	return bestHue, bestSat
+}
+
+func getPartJitter(d *Descriptor, part string) Jitter {
+	switch part {
+	case "legs":
+		return d.LegsJitter
+	case "hair":
+		return d.HairJitter
+	case "arms":
+		return d.ArmsJitter
+	}
+	return Jitter{}
+}
+
+func getPartHue(d *Descriptor, part string) float64 {
+	switch part {
+	case "legs":
+		return d.LegsHue
+	case "arms":
+		return d.ArmsHue
+	}
+	return -1
+}
+
+// FromParts creates a monsterid image from an explicit selection of parts and
+// colors, such as one picked by hand or returned by Describe. The parts are
+// those of the theme of d, unless the options set another.
+func FromParts(d Descriptor, opts ...Option) (image.Image, error) {
+	o := buildOptions(opts)
+	if o.Theme == "" {
+		o.Theme = d.Theme
+	}
+	p := o.theme().pack()
+	if err := d.validate(p.counts); err != nil {
+		return nil, err
+	}
+
+	return newImage(context.Background(), d, o, p)
+}
+
+// Helper to wrap a hue into the 0.0-1.0 range
+func wrapHue(h float64) float64 {
+	h = math.Mod(h, 1)
+	if h < 0 {
+		h++
+	}
+
+	return h
+}
+
+// Helper to check a descriptor read back from storage against the parts of
+// its own theme
+func (d Descriptor) validateTheme() error {
+	t := d.Theme
+	if t == "" {
+		t = ThemeClassic
+	}
+	if !slices.Contains(Themes(), t) {
+		return fmt.Errorf("monsterid: unknown theme %q", t)
+	}
+
+	return d.validate(t.pack().counts)
+}
+
+// Helper to check that all parts and colors of d are in range
+func (d Descriptor) validate(counts partCounts) error {
+	for _, part := range bodyParts {
+		if n := getPartNumber(&d, part); n < 1 || n > counts.count(part) {
+			return fmt.Errorf("monsterid: %s part %d out of range 1-%d", part, n, counts.count(part))
+		}
+	}
+	if d.Hue < 0 || d.Hue > 1 {
+		return fmt.Errorf("monsterid: hue %g out of range 0-1", d.Hue)
+	}
+	if d.Saturation < 0 || d.Saturation > 1 {
+		return fmt.Errorf("monsterid: saturation %g out of range 0-1", d.Saturation)
+	}
+	for _, part := range []string{"legs", "arms"} {
+		if hue := getPartHue(&d, part); hue != -1 && (hue < 0 || hue > 1) {
+			return fmt.Errorf("monsterid: %s hue %g out of range 0-1", part, hue)
+		}
+	}
+	if err := d.Accessory.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDescribeIsDeterministic(t *testing.T) {
+	hash := []byte("describe-test")
+
+	if Describe(hash) != Describe(hash) {
+		t.Error("Same hash produced different descriptors")
+	}
+}
+
+func TestDescribeRanges(t *testing.T) {
+	counts := ThemeClassic.pack().counts
+	for i := 0; i < 100; i++ {
+		d := Describe([]byte{byte(i), byte(i >> 8)})
+
+		for _, part := range bodyParts {
+			if n := getPartNumber(&d, part); n < 1 || n > counts.count(part) {
+				t.Fatalf("Part %s index %d out of range", part, n)
+			}
+		}
+		if d.Hue < 0 || d.Hue >= 1 {
+			t.Fatalf("Hue %f out of range", d.Hue)
+		}
+		if d.Saturation < 0.5 || d.Saturation >= 1 {
+			t.Fatalf("Saturation %f out of range", d.Saturation)
+		}
+		if d.LegsHue >= 1 || d.ArmsHue >= 1 {
+			t.Fatalf("Limb hues %f/%f out of range", d.LegsHue, d.ArmsHue)
+		}
+	}
+}
+
+func TestDescribeWithoutArtisticKeepsLimbColors(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		d := Describe([]byte{byte(i)}, WithArtistic(false))
+		if d.LegsHue != -1 || d.ArmsHue != -1 {
+			t.Fatalf("Expected original limb colors without artistic mode, got %f/%f", d.LegsHue, d.ArmsHue)
+		}
+	}
+}
+
+func TestFromPartsMatchesNew(t *testing.T) {
+	hash := []byte("from-parts")
+
+	img, err := FromParts(Describe(hash))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(img.(*image.RGBA).Pix, New(hash).(*image.RGBA).Pix) {
+		t.Error("FromParts with the described parts did not match New")
+	}
+}
+
+func TestFromPartsHandPicked(t *testing.T) {
+	d := Descriptor{Legs: 1, Hair: 2, Arms: 3, Body: 4, Eyes: 5, Mouth: 6, Hue: 0.5, Saturation: 0.8, LegsHue: -1, ArmsHue: 0.1}
+
+	img, err := FromParts(d, WithSize(64))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if img.Bounds().Dx() != 64 {
+		t.Errorf("Expected a 64 pixel image, got %d", img.Bounds().Dx())
+	}
+}
+
+func TestFromPartsRejectsInvalidSpec(t *testing.T) {
+	valid := Describe([]byte("from-parts-invalid"))
+
+	tests := []struct {
+		description string
+		modify      func(d *Descriptor)
+	}{
+		{"missing body", func(d *Descriptor) { d.Body = 0 }},
+		{"eyes out of range", func(d *Descriptor) { d.Eyes = 16 }},
+		{"negative hue", func(d *Descriptor) { d.Hue = -0.5 }},
+		{"saturation above one", func(d *Descriptor) { d.Saturation = 1.5 }},
+		{"arms hue out of range", func(d *Descriptor) { d.ArmsHue = 2 }},
+	}
+
+	for _, test := range tests {
+		d := valid
+		test.modify(&d)
+		if _, err := FromParts(d); err == nil {
+			t.Errorf("Expected an error for %s", test.description)
+		}
+	}
+}
+
+func TestHueLock(t *testing.T) {
+	const teal = 0.5
+
+	for i := 0; i < 50; i++ {
+		hash := []byte{byte(i)}
+		free := Describe(hash)
+
+		fixed := Describe(hash, WithHue(teal, 0))
+		if fixed.Hue != teal {
+			t.Fatalf("Expected hue %f, got %f", teal, fixed.Hue)
+		}
+
+		constrained := Describe(hash, WithHue(teal, 0.05))
+		if math.Abs(constrained.Hue-teal) > 0.05+1e-9 {
+			t.Fatalf("Hue %f outside tolerance of %f", constrained.Hue, teal)
+		}
+
+		// Everything else still varies by hash
+		fixed.Hue = free.Hue
+		if fixed != free {
+			t.Fatalf("Hue lock changed other parts: %+v vs %+v", fixed, free)
+		}
+	}
+}
+
+func TestWrapHue(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{0.25, 0.25},
+		{1.25, 0.25},
+		{-0.25, 0.75},
+		{1, 0},
+	}
+
+	for _, test := range tests {
+		if got := wrapHue(test.in); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("wrapHue(%f) = %f, want %f", test.in, got, test.want)
+		}
+	}
+}
+
+func TestSaturationRange(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		d := Describe([]byte{byte(i)}, WithSaturationRange(0.2, 0.4))
+		if d.Saturation < 0.2 || d.Saturation >= 0.4 {
+			t.Fatalf("Saturation %f outside range 0.2-0.4", d.Saturation)
+		}
+	}
+}
+
+func TestPaletteSnapsColors(t *testing.T) {
+	palette := []color.Color{
+		color.RGBA{R: 0, G: 128, B: 128, A: 255}, // teal
+		color.RGBA{R: 255, G: 165, B: 0, A: 255}, // orange
+	}
+
+	allowed := map[float64]bool{}
+	for _, c := range palette {
+		r, g, b, _ := c.RGBA()
+		h, _, _ := rgbToHsl(float64(r)/0xFFFF, float64(g)/0xFFFF, float64(b)/0xFFFF)
+		allowed[h] = true
+	}
+
+	for i := 0; i < 50; i++ {
+		d := Describe([]byte{byte(i)}, WithPalette(palette...))
+		if !allowed[d.Hue] {
+			t.Fatalf("Body hue %f not from palette", d.Hue)
+		}
+		for _, hue := range []float64{d.LegsHue, d.ArmsHue} {
+			if hue != -1 && !allowed[hue] {
+				t.Fatalf("Limb hue %f not from palette", hue)
+			}
+		}
+	}
+}
+
+func TestNearestPaletteColorWrapsHue(t *testing.T) {
+	palette := []color.Color{
+		color.RGBA{R: 255, G: 0, B: 0, A: 255}, // hue 0
+		color.RGBA{R: 0, G: 255, B: 0, A: 255}, // hue 1/3
+	}
+
+	if h, _ := nearestPaletteColor(palette, 0.95); h != 0 {
+		t.Errorf("Expected hue 0.95 to snap to red, got %f", h)
+	}
+}
+
+func TestJitterKeepsSelection(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		hash := []byte{byte(i)}
+
+		plain := Describe(hash)
+		jittered := Describe(hash, WithJitter())
+		if jittered.LegsJitter == (Jitter{}) && jittered.HairJitter == (Jitter{}) && jittered.ArmsJitter == (Jitter{}) {
+			t.Fatalf("Hash %d: no part was jittered", i)
+		}
+
+		jittered.LegsJitter, jittered.HairJitter, jittered.ArmsJitter = Jitter{}, Jitter{}, Jitter{}
+		if plain != jittered {
+			t.Fatalf("Jitter changed the selection: %+v vs %+v", plain, jittered)
+		}
+	}
+}
+
+func TestJitterMovesParts(t *testing.T) {
+	d := Describe([]byte("jitter-test"))
+	plain, err := FromParts(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	d.ArmsJitter = Jitter{DX: 2, DY: -1, Angle: 0.1}
+	moved, err := FromParts(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if bytes.Equal(plain.(*image.RGBA).Pix, moved.(*image.RGBA).Pix) {
+		t.Error("Jittering the arms did not change the image")
+	}
+}
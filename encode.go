@@ -0,0 +1,79 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"io"
+)
+
+// PNG creates a monsterid image based on the provided hash and returns it
+// encoded as PNG.
+func PNG(hash []byte, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodePNG(buf, hash, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodePNG creates a monsterid image based on the provided hash and writes
+// it to w as PNG, compressed with Options.Compression and with the generation
+// metadata embedded if Options.Metadata is set.
+func EncodePNG(w io.Writer, hash []byte, opts ...Option) error {
+	return Render(w, hash, FormatPNG, opts...)
+}
+
+// Base64 creates a monsterid image based on the provided hash and returns
+// the PNG encoding as standard base64.
+func Base64(hash []byte, opts ...Option) (string, error) {
+	data, err := PNG(hash, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DataURI creates a monsterid image based on the provided hash and returns it
+// as a data:image/png;base64 URI, ready to inline in HTML or emails.
+func DataURI(hash []byte, opts ...Option) (string, error) {
+	b64, err := Base64(hash, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + b64, nil
+}
+
+// GIF creates a monsterid image based on the provided hash and returns it
+// encoded as GIF.
+func GIF(hash []byte, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeGIF(buf, hash, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeGIF creates a monsterid image based on the provided hash and writes
+// it to w as GIF with at most Options.Colors colors (256 if zero). GIF only
+// supports fully transparent pixels, so translucent edges are made either
+// transparent or opaque.
+func EncodeGIF(w io.Writer, hash []byte, opts ...Option) error {
+	return Render(w, hash, FormatGIF, opts...)
+}
+
+// Helper to quantize img for GIF, dropping partial transparency
+func palettedGIF(img *image.RGBA, colors int) *image.Paletted {
+	if colors <= 0 {
+		colors = 256
+	}
+
+	flat := cloneImage(img)
+	hardenAlpha(flat)
+
+	return Quantize(flat, colors)
+}
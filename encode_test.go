@@ -0,0 +1,118 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+	hash := []byte("png-test")
+
+	data, err := PNG(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	want := New(hash).(*image.RGBA)
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			r1, g1, b1, a1 := img.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				t.Fatalf("Decoded PNG differs from New at (%d,%d)", x, y)
+			}
+		}
+	}
+}
+
+func TestEncodePNGCompression(t *testing.T) {
+	hash := []byte("png-compression")
+
+	fast := new(bytes.Buffer)
+	if err := EncodePNG(fast, hash, WithCompression(png.NoCompression)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	best := new(bytes.Buffer)
+	if err := EncodePNG(best, hash, WithCompression(png.BestCompression)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if best.Len() >= fast.Len() {
+		t.Errorf("Best compression is not smaller than no compression: %d vs %d bytes", best.Len(), fast.Len())
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	hash := []byte("data-uri")
+
+	uri, err := DataURI(hash, WithSize(32))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b64, ok := strings.CutPrefix(uri, "data:image/png;base64,")
+	if !ok {
+		t.Fatalf("Unexpected data URI prefix: %.40s", uri)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatalf("Failed to decode base64: %v", err)
+	}
+
+	want, _ := PNG(hash, WithSize(32))
+	if !bytes.Equal(data, want) {
+		t.Error("Data URI payload differs from PNG")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	data, err := GIF([]byte("gif-test"), WithSize(64))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := gif.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode GIF: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Errorf("Expected a 64x64 GIF, got %v", b)
+	}
+}
+
+func TestGIFTransparency(t *testing.T) {
+	data, err := GIF([]byte("gif-transparent"), WithTransparentBackground(), WithColors(32))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := gif.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode GIF: %v", err)
+	}
+
+	p := img.(*image.Paletted)
+	if len(p.Palette) > 32 {
+		t.Errorf("Expected at most 32 colors, got %d", len(p.Palette))
+	}
+	if _, _, _, a := p.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("Expected a transparent corner, got alpha %d", a)
+	}
+	for i := range p.Palette {
+		if _, _, _, a := p.Palette[i].RGBA(); a != 0 && a != 0xffff {
+			t.Fatalf("Palette entry %d is translucent", i)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package monsterid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileCacheHeader is the length of the expiry time in front of the data of a
+// FileCache file.
+const fileCacheHeader = 8
+
+// FileCache is a Cache of files in a directory, which survives restarts and
+// can be shared by servers on the same machine. Expired files are removed
+// when read, so the directory is best cleaned up periodically as well.
+type FileCache struct {
+	dir string           // directory of the cache files
+	now func() time.Time // current time, replaced by tests
+}
+
+// NewFileCache creates a FileCache storing files in dir, which is created if
+// it doesn't exist.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("monsterid: file cache: %w", err)
+	}
+
+	return &FileCache{dir: dir, now: time.Now}, nil
+}
+
+// Get returns the data cached for key, or ErrCacheMiss.
+func (c *FileCache) Get(_ context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: file cache: %w", err)
+	}
+	if len(data) < fileCacheHeader {
+		return nil, ErrCacheMiss
+	}
+
+	if expires := int64(binary.BigEndian.Uint64(data)); expires != 0 && c.now().UnixNano() >= expires {
+		os.Remove(c.path(key))
+		return nil, ErrCacheMiss
+	}
+
+	return data[fileCacheHeader:], nil
+}
+
+// Set caches data for key for ttl, or until deleted if ttl is zero. The file
+// is replaced at once, so concurrent readers never see part of it.
+func (c *FileCache) Set(_ context.Context, key string, data []byte, ttl time.Duration) error {
+	var expires int64
+	if ttl > 0 {
+		expires = c.now().Add(ttl).UnixNano()
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("monsterid: file cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	header := binary.BigEndian.AppendUint64(nil, uint64(expires))
+	if _, err := tmp.Write(append(header, data...)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("monsterid: file cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("monsterid: file cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("monsterid: file cache: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes key from the cache, if it is there.
+func (c *FileCache) Delete(_ context.Context, key string) error {
+	if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("monsterid: file cache: %w", err)
+	}
+
+	return nil
+}
+
+// Helper to get the file of a key, named after its SHA-256 so any key is a
+// valid file name
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,58 @@
+package monsterid
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "avatars")
+	c, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	testCache(t, c, func(d time.Duration) { now = now.Add(d) })
+
+	// Expired and deleted files are removed, and no temporary files are left
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected an empty directory, got %d files", len(entries))
+	}
+}
+
+func TestFileCachePersists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	c, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.Set(ctx, "v1/../alice?s=64", []byte("avatar"), 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reopened, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := reopened.Get(ctx, "v1/../alice?s=64"); err != nil || string(data) != "avatar" {
+		t.Errorf("Expected the cached avatar, got %q and %v", data, err)
+	}
+
+	// Truncated files are misses
+	if err := os.WriteFile(c.path("short"), []byte("abc"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := c.Get(ctx, "short"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected %v, got %v", ErrCacheMiss, err)
+	}
+}
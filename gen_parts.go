@@ -0,0 +1,1078 @@
+//go:build ignore
+
+// This program draws the part artwork of the built-in themes other than the
+// classic one into parts/<theme>/, the parts of the styles drawn from
+// artwork into parts/<style>/, the accessories into parts/accessories/ and
+// the seasonal overlays into parts/seasons/, with variants at two and four
+// times the resolution in the @2x and @4x directories. The pixel art of the
+// pixel style is only drawn at 32x32, into parts/pixel/. Run it with go
+// generate after changing a part, the output is committed.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// size is the width and height of a part, the same as the classic artwork.
+// Shapes are defined in these coordinates at every scale.
+const size = 120
+
+// scales are the resolutions the parts are drawn at.
+var scales = []int{1, 2, 4}
+
+// outlineWidth is the width of the black outline around filled shapes.
+const outlineWidth = 2
+
+// pixelSize is the width and height of the pixel art parts, drawn in the
+// same coordinates as the other parts.
+const pixelSize = 32
+
+// pixelOutline is the width of the outline of pixel art parts, one pixel.
+const pixelOutline = float64(size) / pixelSize
+
+var (
+	black = color.NRGBA{A: 255}
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	red   = color.NRGBA{R: 214, G: 48, B: 49, A: 255}
+	pink  = color.NRGBA{R: 250, G: 140, B: 160, A: 255}
+	grey  = color.NRGBA{R: 150, G: 156, B: 166, A: 255}
+	dark  = color.NRGBA{R: 70, G: 74, B: 82, A: 255}
+	// charcoal is a neutral dark grey for styles that must stay grey
+	charcoal = color.NRGBA{R: 72, G: 72, B: 72, A: 255}
+	gold     = color.NRGBA{R: 240, G: 190, B: 40, A: 255}
+	blue     = color.NRGBA{R: 40, G: 130, B: 230, A: 255}
+	green    = color.NRGBA{R: 40, G: 160, B: 90, A: 255}
+	orange   = color.NRGBA{R: 245, G: 130, B: 30, A: 255}
+)
+
+// shape is a signed distance function, negative inside the shape.
+type shape func(x, y float64) float64
+
+func circle(cx, cy, r float64) shape {
+	return func(x, y float64) float64 {
+		return math.Hypot(x-cx, y-cy) - r
+	}
+}
+
+// ellipse is an approximation that is exact on the axes, which is all the
+// aliased rendering needs.
+func ellipse(cx, cy, rx, ry float64) shape {
+	return func(x, y float64) float64 {
+		return (math.Hypot((x-cx)/rx, (y-cy)/ry) - 1) * math.Min(rx, ry)
+	}
+}
+
+// box is a rectangle centered on cx, cy with rounded corners of radius r.
+func box(cx, cy, hw, hh, r float64) shape {
+	return func(x, y float64) float64 {
+		dx := math.Abs(x-cx) - hw + r
+		dy := math.Abs(y-cy) - hh + r
+		return math.Hypot(math.Max(dx, 0), math.Max(dy, 0)) + math.Min(math.Max(dx, dy), 0) - r
+	}
+}
+
+// poly is a closed polygon through the points x0, y0, x1, y1, ...
+func poly(pts ...float64) shape {
+	return func(x, y float64) float64 {
+		d := math.Inf(1)
+		inside := false
+		n := len(pts) / 2
+		for i, j := 0, n-1; i < n; j, i = i, i+1 {
+			ax, ay, bx, by := pts[2*j], pts[2*j+1], pts[2*i], pts[2*i+1]
+			d = math.Min(d, segment(x, y, ax, ay, bx, by))
+			if (by > y) != (ay > y) && x < (ax-bx)*(y-by)/(ay-by)+bx {
+				inside = !inside
+			}
+		}
+		if inside {
+			return -d
+		}
+		return d
+	}
+}
+
+// line is a stroke of width w along the points x0, y0, x1, y1, ...
+func line(w float64, pts ...float64) shape {
+	return func(x, y float64) float64 {
+		d := math.Inf(1)
+		for i := 2; i+1 < len(pts); i += 2 {
+			d = math.Min(d, segment(x, y, pts[i-2], pts[i-1], pts[i], pts[i+1]))
+		}
+		return d - w/2
+	}
+}
+
+// arc is a stroke of width w along a circle from angle a0 to a1 in degrees,
+// clockwise on screen with 0 pointing right.
+func arc(cx, cy, r, a0, a1, w float64) shape {
+	const steps = 24
+	pts := make([]float64, 0, 2*(steps+1))
+	for i := 0; i <= steps; i++ {
+		a := (a0 + (a1-a0)*float64(i)/steps) * math.Pi / 180
+		pts = append(pts, cx+r*math.Cos(a), cy+r*math.Sin(a))
+	}
+	return line(w, pts...)
+}
+
+func intersect(a, b shape) shape {
+	return func(x, y float64) float64 {
+		return math.Max(a(x, y), b(x, y))
+	}
+}
+
+func union(shapes ...shape) shape {
+	return func(x, y float64) float64 {
+		d := math.Inf(1)
+		for _, s := range shapes {
+			d = math.Min(d, s(x, y))
+		}
+		return d
+	}
+}
+
+// mirror draws s on both sides of the vertical center line.
+func mirror(s shape) shape {
+	return union(s, func(x, y float64) float64 { return s(size-x, y) })
+}
+
+// Distance from x, y to the segment from a to b
+func segment(x, y, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((x-ax)*dx+(y-ay)*dy)/l))
+	}
+	return math.Hypot(x-ax-t*dx, y-ay-t*dy)
+}
+
+// canvas is a part being drawn, later operations paint over earlier ones.
+type canvas struct {
+	ops     []paintOp
+	outline float64 // width of the outline of filled shapes
+}
+
+// paintOp paints the pixels of a shape, at returns the color for a distance.
+type paintOp struct {
+	s  shape
+	at func(d float64) (color.NRGBA, bool)
+}
+
+func newCanvas() *canvas {
+	return &canvas{outline: outlineWidth}
+}
+
+// newPixelCanvas is a canvas for pixel art parts, with outlines one pixel
+// wide at the pixel art size.
+func newPixelCanvas() *canvas {
+	return &canvas{outline: pixelOutline}
+}
+
+// fill paints the inside of s in c, without an outline.
+func (cv *canvas) fill(s shape, c color.NRGBA) *canvas {
+	return cv.paint(s, func(d float64) (color.NRGBA, bool) { return c, d <= 0 })
+}
+
+// shape paints the inside of s in c with a black outline.
+func (cv *canvas) shape(s shape, c color.NRGBA) *canvas {
+	return cv.paint(s, func(d float64) (color.NRGBA, bool) {
+		if d <= -cv.outline {
+			return c, true
+		}
+		return black, d <= 0
+	})
+}
+
+// stroke paints s in black.
+func (cv *canvas) stroke(s shape) *canvas {
+	return cv.fill(s, black)
+}
+
+func (cv *canvas) paint(s shape, at func(d float64) (color.NRGBA, bool)) *canvas {
+	cv.ops = append(cv.ops, paintOp{s, at})
+	return cv
+}
+
+// image rasterizes the part at scale times its size.
+func (cv *canvas) image(scale int) *image.NRGBA {
+	return cv.pixels(size * scale)
+}
+
+// pixels rasterizes the part at n x n pixels.
+func (cv *canvas) pixels(n int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, n, n))
+	scale := float64(n) / size
+	for _, op := range cv.ops {
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				px, py := (float64(x)+0.5)/scale, (float64(y)+0.5)/scale
+				if c, ok := op.at(op.s(px, py)); ok {
+					img.SetNRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// theme is a set of parts by category.
+type theme map[string][]*canvas
+
+func main() {
+	overlays := map[string]map[string]*canvas{
+		"accessories": accessories(),
+		"seasons":     seasons(),
+	}
+
+	for name, images := range overlays {
+		for _, scale := range scales {
+			dir := scaledDir(filepath.Join("parts", name), scale)
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				log.Fatal(err)
+			}
+			for name, cv := range images {
+				if err := writePNG(filepath.Join(dir, name+".png"), cv.image(scale)); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+
+	// Styles count their parts in code, so they have no manifest
+	styles := map[string]theme{
+		"wavatar":  wavatar(),
+		"robohash": robohash(),
+		"cat":      cat(),
+	}
+
+	for name, parts := range styles {
+		writeParts(filepath.Join("parts", name), parts)
+	}
+
+	// Pixel art is only drawn at its own size
+	for category, images := range pixel() {
+		dir := filepath.Join("parts", "pixel")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Fatal(err)
+		}
+		for i, cv := range images {
+			if err := writePNG(filepath.Join(dir, fmt.Sprintf("%s_%d.png", category, i+1)), cv.pixels(pixelSize)); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	themes := map[string]theme{
+		"robot": robot(),
+		"cute":  cute(),
+	}
+
+	for name, parts := range themes {
+		dir := filepath.Join("parts", name)
+		counts := writeParts(dir, parts)
+
+		manifest, err := json.MarshalIndent(map[string]any{"parts": counts}, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "manifest.json"), append(manifest, '\n'), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// writeParts writes the parts of a theme to dir at every scale and returns
+// the number of parts per category.
+func writeParts(dir string, parts theme) map[string]int {
+	counts := make(map[string]int, len(parts))
+	for _, scale := range scales {
+		scaled := scaledDir(dir, scale)
+		if err := os.MkdirAll(scaled, 0o755); err != nil {
+			log.Fatal(err)
+		}
+
+		for category, images := range parts {
+			counts[category] = len(images)
+			for i, cv := range images {
+				if err := writePNG(filepath.Join(scaled, fmt.Sprintf("%s_%d.png", category, i+1)), cv.image(scale)); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+
+	return counts
+}
+
+// scaledDir is the directory of the parts in dir at a scale.
+func scaledDir(dir string, scale int) string {
+	if scale == 1 {
+		return dir
+	}
+	return filepath.Join(dir, fmt.Sprintf("@%dx", scale))
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	return enc.Encode(f, img)
+}
+
+// robot draws boxy machines with antennas, LED eyes and speaker grilles.
+func robot() theme {
+	bolts := func(cv *canvas, hw, hh float64) *canvas {
+		for _, p := range [][2]float64{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+			cv.fill(circle(60+p[0]*(hw-7), 57+p[1]*(hh-7), 2), dark)
+		}
+		return cv
+	}
+
+	return theme{
+		"legs": {
+			// Pistons with flat feet
+			newCanvas().
+				shape(mirror(box(48, 96, 4, 14, 1)), grey).
+				shape(mirror(box(46, 110, 9, 4, 2)), dark),
+			// Wheels
+			newCanvas().
+				shape(mirror(box(44, 92, 3, 6, 0)), grey).
+				shape(mirror(circle(44, 103, 10)), dark).
+				fill(mirror(circle(44, 103, 3)), grey),
+			// Caterpillar track
+			newCanvas().
+				shape(box(60, 102, 40, 11, 11), dark).
+				fill(union(circle(32, 102, 5), circle(51, 102, 5), circle(69, 102, 5), circle(88, 102, 5)), grey),
+			// Bent legs
+			newCanvas().
+				stroke(mirror(line(5, 48, 85, 36, 98, 44, 112))).
+				shape(mirror(box(46, 112, 8, 3, 1)), grey),
+		},
+		"hair": {
+			// Antenna with a ball
+			newCanvas().
+				stroke(line(3, 60, 40, 60, 10)).
+				shape(circle(60, 9, 6), red),
+			// Two antennas
+			newCanvas().
+				stroke(mirror(line(3, 48, 40, 38, 10))).
+				shape(mirror(circle(38, 9, 5)), red),
+			// Spikes
+			newCanvas().
+				shape(union(poly(36, 40, 44, 14, 52, 40), poly(52, 40, 60, 8, 68, 40), poly(68, 40, 76, 14, 84, 40)), grey),
+			// Propeller
+			newCanvas().
+				stroke(line(3, 60, 40, 60, 14)).
+				shape(box(60, 12, 24, 3, 3), grey),
+		},
+		"arms": {
+			// Straight arms with claws
+			newCanvas().
+				stroke(mirror(line(5, 40, 58, 14, 58))).
+				shape(mirror(circle(24, 58, 4)), grey).
+				stroke(mirror(line(3, 14, 58, 6, 50))).
+				stroke(mirror(line(3, 14, 58, 6, 66))),
+			// Raised arms
+			newCanvas().
+				stroke(mirror(line(5, 40, 56, 16, 56, 16, 26))).
+				shape(mirror(circle(16, 22, 6)), grey),
+			// Springs
+			newCanvas().
+				stroke(mirror(line(3, 40, 60, 35, 52, 30, 68, 25, 52, 20, 68, 15, 52, 12, 60))).
+				shape(mirror(box(9, 60, 5, 5, 1)), grey),
+			// Arms down with square hands
+			newCanvas().
+				stroke(mirror(line(5, 40, 52, 20, 68, 20, 86))).
+				shape(mirror(box(20, 92, 6, 6, 1)), grey),
+		},
+		"body": {
+			bolts(newCanvas().shape(box(60, 57, 34, 32, 4), grey), 34, 32),
+			bolts(newCanvas().shape(box(60, 57, 26, 37, 4), color.NRGBA{R: 240, G: 150, B: 60, A: 255}), 26, 37),
+			bolts(newCanvas().shape(box(60, 59, 38, 27, 4), color.NRGBA{R: 90, G: 170, B: 200, A: 255}), 38, 27),
+			newCanvas().shape(box(60, 57, 34, 34, 14), color.NRGBA{R: 120, G: 190, B: 120, A: 255}),
+			newCanvas().shape(poly(40, 22, 80, 22, 98, 57, 80, 92, 40, 92, 22, 57), color.NRGBA{R: 200, G: 200, B: 90, A: 255}),
+			newCanvas().
+				shape(circle(60, 57, 36), color.NRGBA{R: 170, G: 130, B: 210, A: 255}).
+				stroke(arc(60, 57, 28, 200, 340, 2)),
+			newCanvas().shape(poly(38, 24, 82, 24, 98, 90, 22, 90), color.NRGBA{R: 220, G: 100, B: 100, A: 255}),
+			newCanvas().shape(poly(46, 22, 74, 22, 96, 44, 96, 72, 74, 94, 46, 94, 24, 72, 24, 44), color.NRGBA{R: 100, G: 130, B: 220, A: 255}),
+		},
+		"eyes": {
+			// Square LEDs
+			newCanvas().
+				shape(mirror(box(48, 40, 7, 7, 1)), white).
+				fill(mirror(box(48, 40, 3, 3, 0)), black),
+			// Round lenses
+			newCanvas().
+				shape(mirror(circle(47, 40, 8)), white).
+				fill(mirror(circle(47, 40, 3)), black),
+			// Visor
+			newCanvas().shape(box(60, 40, 26, 6, 3), red),
+			// Cyclops lens
+			newCanvas().
+				shape(circle(60, 40, 12), white).
+				shape(circle(60, 40, 6), red),
+			// Slits
+			newCanvas().stroke(mirror(line(4, 40, 40, 54, 40))),
+			// Crosses
+			newCanvas().
+				stroke(mirror(line(3, 42, 34, 52, 44))).
+				stroke(mirror(line(3, 52, 34, 42, 44))),
+			// Happy chevrons
+			newCanvas().stroke(mirror(line(3, 40, 44, 47, 36, 54, 44))),
+			// Camera
+			newCanvas().
+				shape(mirror(circle(47, 40, 9)), dark).
+				shape(mirror(circle(47, 40, 4)), white),
+		},
+		"mouth": {
+			// Grille
+			newCanvas().
+				shape(box(60, 74, 14, 6, 1), white).
+				stroke(union(line(2, 53, 69, 53, 79), line(2, 60, 69, 60, 79), line(2, 67, 69, 67, 79))),
+			// Flat line
+			newCanvas().stroke(line(3, 48, 74, 72, 74)),
+			// Speaker
+			newCanvas().
+				shape(circle(60, 74, 7), dark).
+				fill(circle(60, 74, 2), black),
+			// Zigzag
+			newCanvas().stroke(line(3, 46, 74, 51, 70, 56, 78, 61, 70, 66, 78, 71, 70, 74, 74)),
+			// Vent
+			newCanvas().
+				shape(box(60, 74, 12, 7, 1), grey).
+				stroke(union(line(2, 50, 72, 70, 72), line(2, 50, 76, 70, 76))),
+			// Small square
+			newCanvas().shape(box(60, 74, 5, 5, 0), red),
+		},
+	}
+}
+
+// cute draws soft, round creatures with big shiny eyes.
+func cute() theme {
+	// Eyes with a highlight, shared by several parts
+	shiny := func(r float64) *canvas {
+		return newCanvas().
+			fill(mirror(circle(48, 40, r)), black).
+			fill(mirror(circle(48-r/3, 40-r/3, r/3)), white)
+	}
+	heart := func(cx, cy, r float64) shape {
+		return union(circle(cx-r/2, cy, r/2+0.5), circle(cx+r/2, cy, r/2+0.5), poly(cx-r, cy+1, cx+r, cy+1, cx, cy+r*1.2))
+	}
+
+	return theme{
+		"legs": {
+			// Stubby feet
+			newCanvas().shape(mirror(ellipse(46, 94, 9, 6)), pink),
+			// Little legs
+			newCanvas().
+				shape(mirror(box(48, 92, 5, 10, 5)), pink),
+			// Paws
+			newCanvas().
+				shape(mirror(ellipse(44, 95, 10, 7)), white).
+				fill(mirror(union(circle(39, 97, 1.5), circle(44, 98, 1.5), circle(49, 97, 1.5))), black),
+			// Floating, no legs
+			newCanvas(),
+		},
+		"hair": {
+			// Cat ears
+			newCanvas().shape(mirror(poly(28, 44, 34, 12, 52, 30)), pink),
+			// Bunny ears
+			newCanvas().shape(mirror(ellipse(46, 20, 7, 20)), white),
+			// Curl
+			newCanvas().stroke(union(line(3, 60, 30, 60, 20), arc(54, 16, 6, 0, 300, 3))),
+			// Bow
+			newCanvas().
+				shape(union(poly(60, 24, 44, 14, 44, 34), poly(60, 24, 76, 14, 76, 34)), red).
+				shape(circle(60, 24, 4), red),
+			// Sprout
+			newCanvas().
+				stroke(line(3, 60, 30, 60, 14)).
+				shape(union(ellipse(52, 13, 8, 4), ellipse(68, 13, 8, 4)), color.NRGBA{R: 100, G: 190, B: 90, A: 255}),
+		},
+		"arms": {
+			// Stubby arms
+			newCanvas().shape(mirror(ellipse(26, 62, 9, 6)), pink),
+			// Waving
+			newCanvas().
+				shape(line(10, 34, 56, 18, 34), pink).
+				shape(ellipse(94, 68, 8, 5), pink),
+			// Arms down
+			newCanvas().shape(mirror(box(26, 74, 5, 12, 5)), pink),
+			// Cheering
+			newCanvas().shape(mirror(line(10, 34, 56, 20, 40)), pink),
+		},
+		"body": {
+			newCanvas().shape(circle(60, 58, 34), color.NRGBA{R: 240, G: 160, B: 175, A: 255}),
+			newCanvas().shape(ellipse(60, 58, 30, 36), color.NRGBA{R: 150, G: 190, B: 235, A: 255}),
+			newCanvas().shape(union(circle(60, 66, 30), circle(60, 42, 20)), color.NRGBA{R: 160, G: 215, B: 160, A: 255}),
+			newCanvas().shape(box(60, 58, 36, 30, 30), color.NRGBA{R: 240, G: 205, B: 130, A: 255}),
+			newCanvas().shape(union(circle(42, 60, 18), circle(60, 48, 22), circle(78, 60, 18), box(60, 68, 34, 18, 16)), color.NRGBA{R: 190, G: 165, B: 235, A: 255}),
+			newCanvas().shape(ellipse(60, 64, 38, 26), color.NRGBA{R: 225, G: 200, B: 185, A: 255}),
+			newCanvas().shape(union(circle(60, 66, 28), poly(60, 16, 36, 56, 84, 56)), color.NRGBA{R: 130, G: 210, B: 210, A: 255}),
+			newCanvas().shape(box(60, 58, 28, 34, 24), color.NRGBA{R: 240, G: 175, B: 130, A: 255}),
+		},
+		"eyes": {
+			shiny(7),
+			// Happy arcs
+			newCanvas().stroke(mirror(arc(48, 44, 6, 200, 340, 3))),
+			// Dots
+			newCanvas().fill(mirror(circle(48, 40, 3)), black),
+			// Hearts
+			newCanvas().fill(mirror(heart(48, 38, 7)), red),
+			// Sleepy
+			newCanvas().stroke(mirror(arc(48, 38, 6, 20, 160, 3))),
+			// Stars
+			newCanvas().fill(mirror(poly(48, 31, 50, 37, 56, 38, 51, 42, 53, 48, 48, 44, 43, 48, 45, 42, 40, 38, 46, 37)), black),
+			// Squeezed shut
+			newCanvas().stroke(union(line(3, 40, 35, 50, 40, 40, 45), line(3, 80, 35, 70, 40, 80, 45))),
+			shiny(10),
+		},
+		"mouth": {
+			// Smile
+			newCanvas().stroke(arc(60, 68, 7, 30, 150, 3)),
+			// Cat mouth
+			newCanvas().stroke(union(arc(55, 72, 5, 10, 170, 3), arc(65, 72, 5, 10, 170, 3))),
+			// Open mouth
+			newCanvas().
+				fill(ellipse(60, 74, 7, 6), black).
+				fill(ellipse(60, 77, 4, 2.5), pink),
+			// Tongue out
+			newCanvas().
+				stroke(line(3, 52, 72, 68, 72)).
+				shape(box(60, 76, 4, 5, 4), pink),
+			// Blushing smile
+			newCanvas().
+				fill(mirror(ellipse(38, 66, 6, 3)), pink).
+				stroke(arc(60, 68, 6, 30, 150, 3)),
+			// Surprised
+			newCanvas().stroke(arc(60, 74, 4, 0, 360, 3)),
+		},
+	}
+}
+
+// wavatar draws the faces of the wavatar style. Faces are white with a black
+// outline, the style tints them with the face color. Eyes, brows and mouths
+// are black and white so they go with any face.
+func wavatar() theme {
+	light := color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+	// Eyes with pupils at an offset from the center of each eye
+	eyes := func(r, pr, dx, dy float64) *canvas {
+		return newCanvas().
+			shape(mirror(circle(46, 54, r)), white).
+			fill(circle(46+dx, 54+dy, pr), black).
+			fill(circle(74+dx, 54+dy, pr), black)
+	}
+
+	return theme{
+		"face": {
+			newCanvas().shape(circle(60, 64, 40), white),
+			newCanvas().shape(ellipse(60, 64, 34, 44), white),
+			newCanvas().shape(ellipse(60, 66, 44, 36), white),
+			newCanvas().shape(box(60, 64, 38, 40, 14), white),
+			// Pear
+			newCanvas().shape(union(circle(60, 76, 36), circle(60, 50, 28)), white),
+			// Cat ears
+			newCanvas().shape(union(circle(60, 66, 38), mirror(poly(26, 50, 30, 14, 54, 30))), white),
+			// Lumpy head
+			newCanvas().shape(union(circle(60, 66, 36), circle(36, 42, 14), circle(84, 42, 14)), white),
+			newCanvas().shape(poly(42, 22, 78, 22, 100, 46, 100, 82, 78, 106, 42, 106, 20, 82, 20, 46), white),
+			// Pointed chin
+			newCanvas().shape(union(box(60, 54, 38, 30, 16), poly(24, 60, 96, 60, 60, 106)), white),
+			// Onion
+			newCanvas().shape(union(circle(60, 70, 36), poly(40, 40, 60, 12, 80, 40)), white),
+		},
+		"eyes": {
+			eyes(9, 4, 0, 0),
+			eyes(12, 5, 0, -3),
+			// Dots
+			newCanvas().fill(mirror(circle(46, 54, 3)), black),
+			// Half closed
+			eyes(9, 4, 0, 2).fill(mirror(intersect(circle(46, 54, 8), box(46, 48, 10, 6, 0))), light).
+				stroke(mirror(line(2, 36, 54, 56, 54))),
+			// Tall ovals
+			newCanvas().
+				shape(mirror(ellipse(46, 54, 6, 10)), white).
+				fill(mirror(circle(46, 57, 3)), black),
+			// Happy arcs
+			newCanvas().stroke(mirror(arc(46, 58, 7, 200, 340, 3))),
+			// Cross-eyed
+			eyes(9, 4, 4, 0).fill(circle(70, 54, 4), black).fill(circle(78, 54, 4), white),
+			// One big, one small
+			newCanvas().
+				shape(circle(44, 54, 12), white).
+				fill(circle(46, 54, 5), black).
+				shape(circle(76, 54, 7), white).
+				fill(circle(77, 54, 3), black),
+			// Goggles
+			eyes(10, 4, 2, 0).stroke(mirror(arc(46, 54, 12, 0, 360, 3))).stroke(line(3, 58, 54, 62, 54)),
+			// Looking up
+			eyes(9, 4, 0, -4),
+		},
+		"brows": {
+			newCanvas().stroke(mirror(line(4, 36, 38, 54, 38))),
+			// Angry
+			newCanvas().stroke(mirror(line(4, 36, 34, 54, 40))),
+			// Worried
+			newCanvas().stroke(mirror(line(4, 36, 40, 54, 34))),
+			// Arched
+			newCanvas().stroke(mirror(arc(46, 44, 10, 210, 330, 4))),
+			// Bushy
+			newCanvas().fill(mirror(box(46, 37, 10, 3, 2)), black),
+			// Unibrow
+			newCanvas().stroke(line(4, 34, 38, 60, 41, 86, 38)),
+			// None
+			newCanvas(),
+			// One raised
+			newCanvas().stroke(union(line(4, 36, 38, 54, 38), line(4, 66, 32, 84, 36))),
+		},
+		"mouth": {
+			// Smile
+			newCanvas().stroke(arc(60, 74, 14, 30, 150, 4)),
+			// Flat
+			newCanvas().stroke(line(4, 48, 86, 72, 86)),
+			// Grin with teeth
+			newCanvas().
+				fill(intersect(circle(60, 76, 16), box(60, 87, 18, 9, 0)), black).
+				fill(box(60, 81, 10, 3, 0), white),
+			// Surprised
+			newCanvas().shape(ellipse(60, 86, 6, 8), charcoal),
+			// Frown
+			newCanvas().stroke(arc(60, 100, 14, 215, 325, 4)),
+			// Smirk
+			newCanvas().stroke(line(4, 48, 88, 64, 86, 74, 80)),
+			// Wavy
+			newCanvas().stroke(line(3, 46, 86, 51, 82, 56, 86, 61, 82, 66, 86, 71, 82, 74, 86)),
+			// Clenched teeth
+			newCanvas().
+				shape(box(60, 86, 16, 6, 3), white).
+				stroke(union(line(2, 45, 86, 75, 86), line(2, 52, 81, 52, 91), line(2, 60, 81, 60, 91), line(2, 68, 81, 68, 91))),
+			// Tongue out
+			newCanvas().
+				stroke(line(4, 48, 84, 72, 84)).
+				shape(ellipse(64, 90, 5, 6), light),
+			// Buck teeth
+			newCanvas().
+				stroke(line(4, 46, 84, 74, 84)).
+				shape(box(60, 89, 6, 4, 1), white).
+				stroke(line(2, 60, 86, 60, 93)),
+		},
+	}
+}
+
+// robohash draws the robots of the robohash style. Bodies and heads are
+// white and light grey with a black outline, the style tints them with the
+// chassis color. Antennas, eyes and mouths are grey so they go with any
+// chassis.
+func robohash() theme {
+	shade := color.NRGBA{R: 215, G: 215, B: 215, A: 255}
+	metal := color.NRGBA{R: 170, G: 170, B: 170, A: 255}
+	// Bodies start with a neck up to the head
+	body := func() *canvas {
+		return newCanvas().shape(box(60, 84, 9, 8, 1), shade)
+	}
+
+	return theme{
+		"body": {
+			// Square shoulders
+			body().
+				shape(box(60, 104, 40, 20, 6), white).
+				shape(box(60, 104, 14, 10, 2), shade),
+			// Round belly
+			body().
+				shape(ellipse(60, 112, 38, 26), white).
+				shape(circle(60, 106, 8), shade),
+			// Narrow torso with shoulder pads
+			body().
+				shape(box(60, 108, 24, 20, 4), white).
+				shape(mirror(circle(30, 96, 10)), shade),
+			// Trapezoid
+			body().
+				shape(poly(34, 86, 86, 86, 104, 124, 16, 124), white).
+				stroke(line(2, 40, 100, 80, 100)),
+			// Barrel with rivets
+			body().
+				shape(box(60, 106, 32, 22, 12), white).
+				fill(mirror(union(circle(38, 100, 2), circle(38, 112, 2))), charcoal),
+			// Tank with a vent
+			body().
+				shape(box(60, 104, 36, 22, 2), shade).
+				shape(box(60, 104, 20, 8, 1), white).
+				stroke(union(line(2, 44, 101, 76, 101), line(2, 44, 107, 76, 107))),
+		},
+		"antenna": {
+			// Single with a ball
+			newCanvas().
+				stroke(line(3, 60, 34, 60, 12)).
+				shape(circle(60, 10, 5), metal),
+			// Two whips
+			newCanvas().
+				stroke(mirror(line(3, 48, 34, 40, 8))).
+				shape(mirror(circle(40, 8, 4)), metal),
+			// Dish
+			newCanvas().
+				stroke(line(3, 60, 34, 60, 22)).
+				shape(intersect(circle(60, 10, 14), box(60, 22, 16, 10, 0)), metal),
+			// Ear bolts
+			newCanvas().shape(mirror(box(26, 56, 6, 10, 2)), metal),
+			// Fin
+			newCanvas().shape(poly(52, 34, 60, 8, 68, 34), metal),
+			// None
+			newCanvas(),
+		},
+		"head": {
+			newCanvas().shape(box(60, 56, 32, 26, 4), white),
+			newCanvas().shape(box(60, 56, 28, 28, 14), white),
+			// Dome
+			newCanvas().shape(union(intersect(circle(60, 62, 32), box(60, 46, 32, 16, 0)), box(60, 70, 32, 12, 2)), white),
+			// Wide with cheek plates
+			newCanvas().
+				shape(box(60, 58, 36, 22, 6), white).
+				shape(mirror(box(30, 66, 5, 8, 1)), shade),
+			// Hexagon
+			newCanvas().shape(poly(42, 28, 78, 28, 94, 56, 78, 84, 42, 84, 26, 56), white),
+			// Bucket
+			newCanvas().shape(poly(34, 30, 86, 30, 92, 84, 28, 84), white),
+			// Tall with a forehead panel
+			newCanvas().
+				shape(box(60, 54, 24, 32, 6), white).
+				shape(box(60, 34, 14, 5, 2), shade),
+			// Round
+			newCanvas().shape(circle(60, 56, 30), white),
+		},
+		"eyes": {
+			// Round lenses
+			newCanvas().
+				shape(mirror(circle(48, 50, 7)), white).
+				fill(mirror(circle(48, 50, 3)), black),
+			// Visor
+			newCanvas().
+				shape(box(60, 50, 24, 6, 3), charcoal).
+				fill(box(60, 50, 20, 2, 1), white),
+			// Square LEDs
+			newCanvas().shape(mirror(box(48, 50, 6, 5, 1)), charcoal),
+			// Cyclops
+			newCanvas().
+				shape(circle(60, 50, 10), white).
+				fill(circle(60, 50, 4), black),
+			// Slits
+			newCanvas().stroke(mirror(line(4, 42, 50, 54, 50))),
+			// Goggles
+			newCanvas().
+				shape(mirror(circle(47, 50, 9)), metal).
+				shape(mirror(circle(47, 50, 5)), white),
+			// Angry LEDs
+			newCanvas().shape(mirror(poly(40, 46, 56, 50, 56, 56, 40, 54)), charcoal),
+			// Crosshairs
+			newCanvas().
+				stroke(mirror(arc(48, 50, 6, 0, 360, 2))).
+				stroke(mirror(union(line(2, 48, 42, 48, 58), line(2, 40, 50, 56, 50)))),
+		},
+		"mouth": {
+			// Grille
+			newCanvas().
+				shape(box(60, 70, 14, 5, 1), white).
+				stroke(union(line(2, 53, 65, 53, 75), line(2, 60, 65, 60, 75), line(2, 67, 65, 67, 75))),
+			// Slot
+			newCanvas().stroke(line(3, 48, 70, 72, 70)),
+			// Speaker
+			newCanvas().
+				shape(circle(60, 70, 6), charcoal).
+				fill(circle(60, 70, 2), black),
+			// Zigzag
+			newCanvas().stroke(line(3, 46, 70, 51, 66, 56, 74, 61, 66, 66, 74, 71, 66, 74, 70)),
+			// Teeth
+			newCanvas().
+				shape(box(60, 70, 12, 5, 1), white).
+				stroke(line(2, 48, 70, 72, 70)),
+			// Smile
+			newCanvas().stroke(arc(60, 62, 10, 40, 140, 3)),
+		},
+	}
+}
+
+// cat draws the cats of the cat style. Ears, faces and fur patterns are
+// shades of grey with a black outline that the style tints with the fur
+// color, lighter for a white muzzle and darker for stripes. Eyes and
+// accessories are tinted with their own colors, whiskers are left as drawn.
+func cat() theme {
+	fur := color.NRGBA{R: 210, G: 210, B: 210, A: 255}
+	inner := color.NRGBA{R: 160, G: 160, B: 160, A: 255}
+	stripe := color.NRGBA{R: 120, G: 120, B: 120, A: 255}
+	// Patterns are kept inside the smallest face
+	within := func(s shape) shape {
+		return intersect(s, ellipse(60, 70, 33, 29))
+	}
+	iris := func(s shape, pupil shape) *canvas {
+		return newCanvas().shape(mirror(s), white).fill(mirror(pupil), black)
+	}
+	nose := func(cv *canvas) *canvas {
+		return cv.
+			fill(poly(55, 72, 65, 72, 60, 78), charcoal).
+			stroke(union(arc(55, 78, 5, 20, 160, 2), arc(65, 78, 5, 20, 160, 2)))
+	}
+
+	return theme{
+		"ears": {
+			// Pointy
+			newCanvas().
+				shape(mirror(poly(28, 56, 32, 14, 58, 38)), fur).
+				fill(mirror(poly(34, 48, 36, 24, 50, 38)), inner),
+			// Wide
+			newCanvas().
+				shape(mirror(poly(22, 60, 22, 22, 54, 40)), fur).
+				fill(mirror(poly(28, 52, 28, 30, 46, 42)), inner),
+			// Tall
+			newCanvas().
+				shape(mirror(poly(32, 50, 38, 6, 56, 36)), fur).
+				fill(mirror(poly(38, 44, 40, 18, 50, 36)), inner),
+			// Folded
+			newCanvas().shape(mirror(ellipse(40, 40, 13, 9)), fur),
+			// One ear bent
+			newCanvas().
+				shape(poly(28, 56, 32, 14, 58, 38), fur).
+				fill(poly(34, 48, 36, 24, 50, 38), inner).
+				shape(poly(62, 38, 92, 56, 100, 30), fur),
+			// Lynx tufts
+			newCanvas().
+				stroke(mirror(line(2, 32, 16, 30, 4))).
+				shape(mirror(poly(28, 56, 32, 14, 58, 38)), fur).
+				fill(mirror(poly(34, 48, 36, 24, 50, 38)), inner),
+		},
+		"face": {
+			newCanvas().shape(circle(60, 68, 36), fur),
+			newCanvas().shape(ellipse(60, 70, 44, 34), fur),
+			// Chubby cheeks
+			newCanvas().shape(union(circle(60, 64, 34), ellipse(60, 82, 42, 20)), fur),
+			// Fluffy cheeks
+			newCanvas().shape(union(circle(60, 68, 34), mirror(poly(30, 60, 14, 82, 34, 92))), fur),
+		},
+		"pattern": {
+			// Plain
+			newCanvas(),
+			// Tabby stripes
+			newCanvas().fill(within(union(line(4, 50, 38, 54, 52), line(4, 60, 36, 60, 52), line(4, 70, 38, 66, 52))), stripe),
+			// Eye patch
+			newCanvas().fill(within(circle(44, 58, 16)), stripe),
+			// White muzzle
+			newCanvas().fill(within(union(ellipse(53, 80, 11, 9), ellipse(67, 80, 11, 9))), white),
+			// Cheek stripes
+			newCanvas().fill(within(mirror(union(line(4, 26, 68, 38, 70), line(4, 26, 78, 38, 78)))), stripe),
+			// Tuxedo
+			newCanvas().fill(within(union(box(60, 94, 40, 18, 0), poly(60, 50, 48, 80, 72, 80))), white),
+			// Spots
+			newCanvas().fill(within(union(circle(72, 44, 7), circle(38, 82, 6), circle(84, 76, 5))), stripe),
+		},
+		"eyes": {
+			iris(circle(46, 62, 8), ellipse(46, 62, 2, 6)),
+			iris(circle(46, 62, 10), circle(46, 62, 5)),
+			// Almond
+			iris(intersect(circle(46, 70, 12), circle(46, 54, 12)), ellipse(46, 62, 2, 5)),
+			// Sleepy
+			newCanvas().stroke(mirror(arc(46, 58, 7, 20, 160, 3))),
+			// Happy
+			newCanvas().stroke(mirror(arc(46, 66, 7, 200, 340, 3))),
+			// Wide pupils
+			iris(circle(46, 62, 8), circle(46, 62, 6)),
+		},
+		"whiskers": {
+			nose(newCanvas()).stroke(mirror(union(line(2, 40, 76, 12, 70), line(2, 40, 80, 12, 80), line(2, 40, 84, 12, 90)))),
+			nose(newCanvas()).stroke(mirror(union(line(2, 40, 78, 14, 74), line(2, 40, 82, 14, 86)))),
+			// Curly whiskers and an open mouth
+			newCanvas().
+				fill(poly(55, 72, 65, 72, 60, 78), charcoal).
+				fill(ellipse(60, 84, 4, 5), charcoal).
+				stroke(mirror(union(arc(24, 92, 18, 250, 290, 2), arc(24, 98, 18, 250, 290, 2)))),
+			// Tongue out
+			nose(newCanvas()).
+				shape(ellipse(60, 86, 4, 5), inner).
+				stroke(mirror(union(line(2, 40, 78, 14, 74), line(2, 40, 82, 14, 86)))),
+		},
+		"accessory": {
+			// None
+			newCanvas(),
+			// Collar with a bell
+			newCanvas().
+				shape(box(60, 104, 26, 4, 2), white).
+				shape(circle(60, 111, 5), inner),
+			// Bow on the ear
+			newCanvas().
+				shape(union(poly(86, 30, 74, 20, 74, 40), poly(86, 30, 98, 20, 98, 40)), white).
+				shape(circle(86, 30, 4), white),
+			// Bandana
+			newCanvas().
+				shape(poly(32, 100, 88, 100, 60, 118), white).
+				fill(union(circle(52, 105, 2), circle(68, 105, 2), circle(60, 111, 2)), inner),
+			// Flower
+			newCanvas().
+				shape(union(circle(30, 30, 5), circle(40, 30, 5), circle(35, 22, 5), circle(35, 38, 5)), white).
+				shape(circle(35, 30, 3), inner),
+			// Bow tie
+			newCanvas().
+				shape(union(poly(60, 106, 46, 98, 46, 114), poly(60, 106, 74, 98, 74, 114)), white).
+				shape(circle(60, 106, 4), white),
+		},
+	}
+}
+
+// pixel draws the 8-bit monsters of the pixel style at pixelSize, in the
+// coordinates of the other parts. Bodies, horns, arms and legs are light
+// grey with a white highlight, the style tints them with the body color.
+// Eyes and mouths are black and white.
+func pixel() theme {
+	fur := color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+	body := func(s shape, hx, hy float64) *canvas {
+		return newPixelCanvas().shape(s, fur).fill(circle(hx, hy, 5), white)
+	}
+	limb := func(s shape) *canvas {
+		return newPixelCanvas().shape(s, fur)
+	}
+
+	return theme{
+		"legs": {
+			limb(mirror(box(46, 100, 6, 12, 2))),
+			limb(mirror(box(42, 98, 9, 14, 0))),
+			limb(mirror(line(8, 48, 88, 40, 112))),
+			limb(mirror(ellipse(44, 100, 12, 9))),
+		},
+		"arms": {
+			limb(mirror(line(9, 34, 64, 12, 56))),
+			// Raised
+			limb(mirror(line(9, 34, 60, 16, 30))),
+			// Down
+			limb(mirror(line(9, 34, 66, 18, 88))),
+			// None
+			newPixelCanvas(),
+		},
+		"horns": {
+			// None
+			newPixelCanvas(),
+			limb(mirror(poly(40, 42, 32, 8, 54, 34))),
+			// Antenna
+			newPixelCanvas().
+				stroke(line(4, 60, 36, 60, 14)).
+				shape(circle(60, 12, 7), fur),
+			// Spikes
+			limb(union(poly(38, 40, 46, 14, 54, 36), poly(52, 34, 60, 6, 68, 34), poly(66, 36, 74, 14, 82, 40))),
+			// Round ears
+			limb(mirror(circle(36, 36, 11))),
+		},
+		"body": {
+			body(circle(60, 64, 32), 46, 46),
+			body(box(60, 64, 30, 30, 8), 42, 44),
+			body(ellipse(60, 66, 38, 28), 40, 52),
+			body(ellipse(60, 62, 26, 38), 48, 38),
+			body(union(circle(60, 72, 28), circle(60, 46, 20)), 50, 36),
+			body(poly(60, 22, 98, 96, 22, 96), 56, 44),
+		},
+		"eyes": {
+			newPixelCanvas().
+				fill(mirror(circle(48, 56, 8)), white).
+				fill(mirror(box(50, 56, 3, 3, 0)), black),
+			// Cyclops
+			newPixelCanvas().
+				fill(circle(60, 54, 12), white).
+				fill(box(60, 56, 4, 4, 0), black),
+			// Dots
+			newPixelCanvas().fill(mirror(box(48, 56, 3, 6, 0)), black),
+			// Angry
+			newPixelCanvas().
+				fill(mirror(poly(38, 48, 56, 54, 56, 64, 38, 62)), white).
+				fill(mirror(box(50, 59, 3, 3, 0)), black),
+			// Three eyes
+			newPixelCanvas().
+				fill(union(circle(42, 58, 7), circle(60, 48, 7), circle(78, 58, 7)), white).
+				fill(union(box(42, 58, 2, 2, 0), box(60, 48, 2, 2, 0), box(78, 58, 2, 2, 0)), black),
+		},
+		"mouth": {
+			newPixelCanvas().stroke(line(4, 48, 78, 72, 78)),
+			// Fangs
+			newPixelCanvas().
+				stroke(line(4, 44, 76, 76, 76)).
+				fill(mirror(box(52, 82, 2, 4, 0)), white),
+			// Open
+			newPixelCanvas().fill(box(60, 80, 10, 6, 0), black),
+			// Smile
+			newPixelCanvas().stroke(arc(60, 68, 12, 30, 150, 4)),
+			// Teeth
+			newPixelCanvas().
+				fill(box(60, 80, 14, 5, 0), black).
+				fill(box(60, 80, 10, 2, 0), white),
+		},
+	}
+}
+
+// accessories draws the hats, glasses and badges drawn over any monster.
+func accessories() map[string]*canvas {
+	star := func(cx, cy, r float64) shape {
+		pts := make([]float64, 0, 20)
+		for i := 0; i < 10; i++ {
+			a := (float64(i)*36 - 90) * math.Pi / 180
+			l := r
+			if i%2 == 1 {
+				l = r * 0.45
+			}
+			pts = append(pts, cx+l*math.Cos(a), cy+l*math.Sin(a))
+		}
+		return poly(pts...)
+	}
+
+	return map[string]*canvas{
+		"top-hat": newCanvas().
+			shape(box(60, 12, 13, 11, 1), dark).
+			fill(box(60, 18, 11, 2, 0), red).
+			shape(box(60, 23, 22, 3, 1), dark),
+		"cap": newCanvas().
+			shape(union(intersect(ellipse(60, 24, 20, 14), box(60, 12, 22, 12, 0)), ellipse(78, 23, 14, 3)), red).
+			fill(circle(60, 10, 2), white),
+		"crown": newCanvas().
+			shape(poly(40, 26, 40, 10, 48, 18, 54, 4, 60, 16, 66, 4, 72, 18, 80, 10, 80, 26), gold).
+			fill(union(circle(48, 22, 2), circle(60, 22, 2), circle(72, 22, 2)), red),
+		"party-hat": newCanvas().
+			shape(poly(48, 26, 60, 2, 72, 26), pink).
+			fill(union(line(2, 52, 18, 64, 12), line(2, 50, 24, 68, 16)), white).
+			shape(circle(60, 3, 4), gold),
+		"glasses": newCanvas().
+			stroke(mirror(arc(47, 40, 9, 0, 360, 2))).
+			stroke(line(2, 56, 39, 64, 39)),
+		"sunglasses": newCanvas().
+			shape(mirror(box(47, 41, 10, 7, 4)), dark).
+			stroke(line(3, 56, 38, 64, 38)),
+		"bow-tie": newCanvas().
+			shape(union(poly(60, 88, 46, 80, 46, 96), poly(60, 88, 74, 80, 74, 96)), red).
+			shape(circle(60, 88, 4), red),
+		"moderator": newCanvas().
+			shape(poly(80, 74, 100, 74, 100, 86, 90, 98, 80, 86), green).
+			fill(star(90, 84, 7), white),
+		"verified": newCanvas().
+			shape(circle(90, 86, 11), blue).
+			fill(line(3, 84, 86, 88, 91, 96, 80), white),
+	}
+}
+
+// seasons draws the seasonal overlays, kept clear of the face.
+func seasons() map[string]*canvas {
+	return map[string]*canvas{
+		"santa-hat": newCanvas().
+			shape(poly(38, 24, 56, 2, 74, 4, 86, 18, 82, 24), red).
+			shape(circle(88, 20, 6), white).
+			shape(box(60, 25, 25, 4, 4), white),
+		"pumpkin": newCanvas().
+			shape(box(96, 94, 2, 5, 1), green).
+			shape(union(ellipse(90, 106, 8, 10), ellipse(102, 106, 8, 10)), orange).
+			shape(ellipse(96, 106, 8, 11), orange).
+			fill(union(poly(91, 104, 93, 100, 95, 104), poly(97, 104, 99, 100, 101, 104)), dark).
+			fill(poly(90, 109, 102, 109, 99, 113, 93, 113), dark),
+	}
+}
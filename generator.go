@@ -0,0 +1,363 @@
+package monsterid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/fs"
+	"maps"
+	"path"
+	"sync/atomic"
+)
+
+// Generator renders monsters from part images that are decoded once when the
+// Generator is created, and again by Reload, or on first use with a cache
+// budget. It is safe for concurrent use.
+type Generator struct {
+	packs      []fs.FS                 // sources of the parts, read again by Reload
+	cacheBytes int64                   // budget of the part cache, parts are decoded up front if zero
+	hueBuckets int                     // hue steps of colorized parts, colorized on every render if zero
+	colorBytes int64                   // budget of the colorized parts with hue buckets
+	set        atomic.Pointer[partSet] // decoded parts, swapped as a whole by Reload
+	usage      usage                   // selections reported by Stats
+}
+
+// GeneratorConfig configures a Generator created with NewGeneratorWithConfig.
+type GeneratorConfig struct {
+	Packs      []fs.FS // part packs, mixed as by NewGeneratorFromPacks
+	CacheBytes int64   // decode parts on first use, keeping at most this many bytes of pixels (all parts up front if zero)
+
+	// HueBuckets rounds the hue of colorized parts to one of this many steps,
+	// and their saturation to steps of 0.05, to cache the colorized parts
+	// instead of colorizing them on every render. Colors are slightly less
+	// precise, so it is off if zero.
+	HueBuckets      int
+	ColorCacheBytes int64 // budget of the colorized parts with HueBuckets, 64 MiB if zero
+}
+
+// partSet is the decoded parts of a Generator with the rules to use them.
+type partSet struct {
+	parts     map[partKey]*image.RGBA // decoded parts
+	files     map[partKey]partFile    // parts decoded on first use into cache
+	cache     *partCache[partKey]     // recently used parts of files, nil if all parts are decoded
+	colorized *colorCache             // parts colorized with quantized colors, nil without hue buckets
+	load      partLoader              // part, bound once so packs don't allocate
+	counts    partCounts              // number of parts per category
+	colors    colorRules              // colorization per category
+}
+
+// partKey is a part of a Generator by file name and scale.
+type partKey struct {
+	fileName string
+	scale    int
+}
+
+// partFile is a part file of a pack at a scale, decoded on first use.
+type partFile struct {
+	fsys     fs.FS
+	fileName string
+	scale    int
+}
+
+// NewGenerator creates a Generator with all embedded parts preloaded.
+func NewGenerator() (*Generator, error) {
+	sub, err := fs.Sub(parts, "parts")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGeneratorFromFS(sub)
+}
+
+// NewThemeGenerator creates a Generator with the embedded parts of a
+// built-in theme preloaded, drawing the same monsters as New with WithTheme.
+// A Generator ignores Options.Theme, so this is how it draws a theme.
+func NewThemeGenerator(t Theme) (*Generator, error) {
+	if t == ThemeClassic {
+		return NewGenerator()
+	}
+	if _, ok := themePacks[t]; !ok {
+		return nil, fmt.Errorf("monsterid: unknown theme %q", t)
+	}
+	sub, err := fs.Sub(parts, path.Join("parts", string(t)))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGeneratorFromFS(sub)
+}
+
+// NewGeneratorFromFS creates a Generator with the parts of a pack in fsys,
+// such as an os.DirFS, zip.Reader or embed.FS. Parts are 120x120 PNG files
+// named <category>_<n>.png, counting from 1, for the categories legs, hair,
+// arms, body, eyes and mouth, or SVG files named <category>_<n>.svg that are
+// rasterized at every resolution. A part can come with a recoloring mask
+// named <category>_<n>_mask.png. An optional manifest.json lists the number of
+// parts per category and how they are colorized, otherwise all consecutive
+// files are used with the classic colorization. Variants at two and four
+// times the resolution in the @2x and @4x directories are used for large
+// sizes.
+//
+// The same hash selects different parts with a different number of parts,
+// so avatars change when parts are added to a pack.
+func NewGeneratorFromFS(fsys fs.FS) (*Generator, error) {
+	return NewGeneratorFromPacks(fsys)
+}
+
+// NewGeneratorFromPacks creates a Generator mixing the parts of several packs,
+// as read by NewGeneratorFromFS. The parts of each category are pooled across
+// the packs, so a monster may get its body from one pack and its eyes from
+// another, and the number of combinations grows with every pack. Colorization
+// follows the manifest of the first pack.
+func NewGeneratorFromPacks(packs ...fs.FS) (*Generator, error) {
+	return NewGeneratorWithConfig(GeneratorConfig{Packs: packs})
+}
+
+// NewGeneratorWithConfig creates a Generator from the packs of cfg. With a
+// CacheBytes budget, parts are decoded when first drawn instead of up front
+// and the least recently used ones are dropped to stay within the budget, for
+// large packs with many high resolution variants. Broken parts are then
+// reported when drawn rather than by the constructor and Reload.
+func NewGeneratorWithConfig(cfg GeneratorConfig) (*Generator, error) {
+	if len(cfg.Packs) == 0 {
+		return nil, errors.New("monsterid: no part packs")
+	}
+
+	g := &Generator{packs: cfg.Packs, cacheBytes: cfg.CacheBytes, hueBuckets: cfg.HueBuckets, colorBytes: cfg.ColorCacheBytes}
+	if err := g.Reload(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// Reload decodes the parts of the packs again, so changes to the artwork of a
+// pack in a directory take effect without restarting. Monsters being
+// rendered keep the parts they started with and the new parts are swapped in
+// at once. On error the current parts are kept.
+func (g *Generator) Reload() error {
+	s := &partSet{parts: make(map[partKey]*image.RGBA), files: make(map[partKey]partFile), counts: make(partCounts, len(bodyParts))}
+	if g.cacheBytes > 0 {
+		s.cache = newPartCache[partKey](g.cacheBytes)
+	}
+	if g.hueBuckets > 0 {
+		s.colorized = newColorCache(g.hueBuckets, g.colorBytes)
+	}
+	for i, fsys := range g.packs {
+		p, err := readPack(fsys)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			s.colors = p.colors
+		}
+		if err := s.addParts(fsys, p.counts); err != nil {
+			return err
+		}
+	}
+	s.load = s.part
+	g.set.Store(s)
+
+	return nil
+}
+
+// Helper to load the parts of a pack, numbering them after the parts of the
+// packs added before
+func (s *partSet) addParts(fsys fs.FS, counts partCounts) error {
+	for _, part := range bodyParts {
+		for i := 1; i <= counts.count(part); i++ {
+			fileName := partFileName(part, i)
+			name := partFileName(part, s.counts[part]+i)
+			for _, scale := range partScales {
+				err := s.addPart(fsys, fileName, partKey{name, scale})
+				if scale > 1 && errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				// Recoloring masks are optional
+				err = s.addPart(fsys, maskName(fileName), partKey{maskName(name), scale})
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+		}
+		s.counts[part] += counts.count(part)
+	}
+
+	return nil
+}
+
+// Helper to add a part file of a pack at the scale of key under key,
+// decoding it now or, with a cache, only checking that it exists
+func (s *partSet) addPart(fsys fs.FS, fileName string, key partKey) error {
+	scale := key.scale
+	if s.cache == nil {
+		img, err := decodePackPart(fsys, fileName, scale)
+		if err != nil {
+			return err
+		}
+		s.parts[key] = img
+		return nil
+	}
+
+	if !packFileExists(fsys, fileName, scale) {
+		return fmt.Errorf("monsterid: load part %s: %w", scaledPartPath(fileName, scale), fs.ErrNotExist)
+	}
+	s.files[key] = partFile{fsys: fsys, fileName: fileName, scale: scale}
+
+	return nil
+}
+
+// Warmup prepares the parts of the Generator for every resolution ahead of
+// the first requests. Parts are decoded when the Generator is created and by
+// Reload, Warmup also scales the recoloring masks of parts with a high
+// resolution variant but no such mask, which is otherwise done on every
+// render. With a cache budget, Warmup decodes the parts into the cache
+// instead, as far as the budget goes. Call it again after Reload.
+func (g *Generator) Warmup(ctx context.Context) error {
+	if s := g.set.Load(); s.cache != nil {
+		for key := range s.files {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, _, err := s.lookup(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for {
+		s := g.set.Load()
+		warm := &partSet{parts: maps.Clone(s.parts), files: s.files, counts: s.counts, colors: s.colors, colorized: s.colorized}
+		warm.load = warm.part
+		for _, part := range bodyParts {
+			for n := 1; n <= s.counts.count(part); n++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				fileName := partFileName(part, n)
+				mask, ok := s.parts[partKey{maskName(fileName), 1}]
+				if !ok {
+					continue
+				}
+				for _, scale := range partScales[1:] {
+					img, ok := s.parts[partKey{fileName, scale}]
+					key := partKey{maskName(fileName), scale}
+					if _, scaled := s.parts[key]; !ok || scaled {
+						continue
+					}
+					warm.parts[key] = scaleImage(mask, img.Bounds().Dx(), bilinear)
+				}
+			}
+		}
+
+		// Start over on the new parts of a concurrent Reload
+		if g.set.CompareAndSwap(s, warm) {
+			return nil
+		}
+	}
+}
+
+// Generate creates a monsterid image based on the provided hash.
+func (g *Generator) Generate(hash []byte, opts ...Option) (image.Image, error) {
+	return g.GenerateContext(context.Background(), hash, opts...)
+}
+
+// GenerateContext is like Generate but abandons generation with the context's
+// error once ctx is done.
+func (g *Generator) GenerateContext(ctx context.Context, hash []byte, opts ...Option) (image.Image, error) {
+	o := buildOptions(opts)
+	if err := o.checkVersion(); err != nil {
+		return nil, err
+	}
+	if o.style() != StyleMonster {
+		return newStyleImage(ctx, hash, o)
+	}
+
+	p := g.pack()
+	d := describeSeed(hash, o, p)
+	g.usage.record(d, p.counts)
+	return newImage(ctx, d, o, p)
+}
+
+// DrawTo draws the monster for hash onto dst with its top-left corner at at,
+// composited over the existing content of dst.
+func (g *Generator) DrawTo(dst draw.Image, at image.Point, hash []byte, opts ...Option) error {
+	o := buildOptions(opts)
+	if err := o.checkVersion(); err != nil {
+		return err
+	}
+	if o.style() != StyleMonster {
+		return drawStyle(context.Background(), dst, at, hash, o)
+	}
+
+	p := g.pack()
+	d := describeSeed(hash, o, p)
+	g.usage.record(d, p.counts)
+	return render(context.Background(), dst, at, d, o, p)
+}
+
+// Describe returns the parts and colors selected for the provided hash out of
+// the parts of the Generator.
+func (g *Generator) Describe(hash []byte, opts ...Option) Descriptor {
+	o := buildOptions(opts)
+	return describeSeed(hash, o, g.pack())
+}
+
+// Helper to get the current parts of the generator with the rules to use
+// them, which stay the same during a Reload
+func (g *Generator) pack() pack {
+	s := g.set.Load()
+	return pack{load: s.load, counts: s.counts, colors: s.colors, colorized: s.colorized}
+}
+
+// Helper to look up a part, at the native resolution if there is no variant
+// at scale
+func (s *partSet) part(fileName string, scale int) (*image.RGBA, error) {
+	if img, ok, err := s.lookup(partKey{fileName, scale}); ok {
+		return img, err
+	}
+
+	img, ok, err := s.lookup(partKey{fileName, 1})
+	if !ok {
+		// Unwrapped, as missing masks are looked up for every monster
+		return nil, fs.ErrNotExist
+	}
+
+	return img, err
+}
+
+// Helper to get a part, decoding it if it isn't preloaded, and whether the
+// part exists
+func (s *partSet) lookup(key partKey) (*image.RGBA, bool, error) {
+	if img, ok := s.parts[key]; ok {
+		return img, true, nil
+	}
+
+	f, ok := s.files[key]
+	if !ok {
+		return nil, false, nil
+	}
+	img, err := s.cache.get(key, func() (*image.RGBA, error) {
+		return decodePackPart(f.fsys, f.fileName, f.scale)
+	})
+
+	return img, true, err
+}
+
+// FromParts creates a monsterid image from an explicit selection of parts and colors.
+func (g *Generator) FromParts(d Descriptor, opts ...Option) (image.Image, error) {
+	p := g.pack()
+	if err := d.validate(p.counts); err != nil {
+		return nil, err
+	}
+
+	return newImage(context.Background(), d, buildOptions(opts), p)
+}
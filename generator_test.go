@@ -0,0 +1,397 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGeneratorMatchesNew(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		hash := []byte(fmt.Sprintf("generator-%d", i))
+
+		img, err := g.Generate(hash)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := New(hash).(*image.RGBA)
+		if !bytes.Equal(img.(*image.RGBA).Pix, want.Pix) {
+			t.Errorf("Generator and New produced different images for %q", hash)
+		}
+	}
+}
+
+func TestGeneratorDoesNotModifyCachedParts(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	hash := []byte("generator-cache")
+	img1, _ := g.Generate(hash)
+	img2, _ := g.Generate(hash)
+
+	if !bytes.Equal(img1.(*image.RGBA).Pix, img2.(*image.RGBA).Pix) {
+		t.Error("Repeated generation produced different images")
+	}
+}
+
+func TestGeneratorConcurrentUse(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := g.Generate([]byte(fmt.Sprintf("concurrent-%d", i)), WithGreyscale()); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestNewGeneratorFromFS(t *testing.T) {
+	g, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		hash := []byte(fmt.Sprintf("pack-%d", i))
+		d := g.Describe(hash)
+		for _, part := range bodyParts {
+			if n := getPartNumber(&d, part); n > 2 {
+				t.Fatalf("Selected %s part %d of a pack with 2", part, n)
+			}
+		}
+
+		img, err := g.Generate(hash)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want, _ := FromParts(d)
+		if !bytes.Equal(img.(*image.RGBA).Pix, want.(*image.RGBA).Pix) {
+			t.Errorf("Pack and embedded parts rendered %+v differently", d)
+		}
+	}
+
+	d := Descriptor{Legs: 1, Hair: 1, Arms: 1, Body: 3, Eyes: 1, Mouth: 1, LegsHue: -1, ArmsHue: -1}
+	if _, err := g.FromParts(d); err == nil {
+		t.Error("Expected an error for parts the pack doesn't have")
+	}
+}
+
+func TestNewGeneratorFromFSWrongSize(t *testing.T) {
+	fsys := testPack(t, 1)
+	buf := new(bytes.Buffer)
+	png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 64, 64)))
+	fsys["body_1.png"] = &fstest.MapFile{Data: buf.Bytes()}
+
+	if _, err := NewGeneratorFromFS(fsys); err == nil {
+		t.Error("Expected an error for a part of the wrong size")
+	}
+}
+
+func TestGeneratorHighResolutionParts(t *testing.T) {
+	fsys := testPack(t, 1)
+
+	// A solid @2x body makes it easy to tell which variant was drawn
+	body := image.NewRGBA(image.Rect(0, 0, 2*nativeSize, 2*nativeSize))
+	draw.Draw(body, body.Bounds(), image.NewUniform(color.RGBA{R: 10, G: 200, B: 30, A: 255}), image.Point{}, draw.Src)
+	buf := new(bytes.Buffer)
+	png.Encode(buf, body)
+	fsys["@2x/body_1.png"] = &fstest.MapFile{Data: buf.Bytes()}
+
+	g, err := NewGeneratorFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	opts := []Option{WithArtistic(false), WithTransparentBackground()}
+	large, _ := g.Generate([]byte("hi-res"), append(opts, WithSize(240))...)
+	if c := large.(*image.RGBA).RGBAAt(0, 0); c != (color.RGBA{R: 10, G: 200, B: 30, A: 255}) {
+		t.Errorf("Expected the @2x body at 240 pixels, got %v", c)
+	}
+
+	small, _ := g.Generate([]byte("hi-res"), opts...)
+	if c := small.(*image.RGBA).RGBAAt(0, 0); c.A != 0 {
+		t.Errorf("Expected the native body at 120 pixels, got %v", c)
+	}
+}
+
+func TestNewGeneratorFromFSWrongScaledSize(t *testing.T) {
+	fsys := testPack(t, 1)
+	fsys["@4x/eyes_1.png"] = fsys["eyes_1.png"]
+
+	if _, err := NewGeneratorFromFS(fsys); err == nil {
+		t.Error("Expected an error for a @4x part of the native size")
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	hash := []byte("benchmark")
+	for i := 0; i < b.N; i++ {
+		New(hash)
+	}
+}
+
+func BenchmarkGenerator(b *testing.B) {
+	g, err := NewGenerator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	hash := []byte("benchmark")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.Generate(hash); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGeneratorAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("Pooled values are dropped with the race detector")
+	}
+
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	hash := []byte("allocations")
+	for _, size := range []int{nativeSize, 240, 512} {
+		opts := []Option{WithSize(size)}
+
+		// Released images are reused, so nothing is allocated once warm
+		allocs := testing.AllocsPerRun(50, func() {
+			img, err := g.Generate(hash, opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			ReleaseImage(img)
+		})
+		if allocs > 0 {
+			t.Errorf("Expected no allocations at %d pixels, got %v", size, allocs)
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		allocs = testing.AllocsPerRun(50, func() {
+			if err := g.DrawTo(dst, image.Point{}, hash, opts...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+		if allocs > 0 {
+			t.Errorf("Expected no allocations drawing at %d pixels, got %v", size, allocs)
+		}
+	}
+}
+
+func TestNewGeneratorFromPacks(t *testing.T) {
+	robot, _ := fs.Sub(parts, "parts/robot")
+	g, err := NewGeneratorFromPacks(testPack(t, 2), robot)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// The parts of the second pack are numbered after the ones of the first
+	robotCounts := ThemeRobot.pack().counts
+	for _, part := range bodyParts {
+		if want := 2 + robotCounts.count(part); g.pack().counts.count(part) != want {
+			t.Errorf("Expected %d %s parts, got %d", want, part, g.pack().counts.count(part))
+		}
+	}
+	mixed, _ := g.pack().load("body_3.png", 1)
+	first, _ := ThemeRobot.pack().load("body_1.png", 1)
+	if !bytes.Equal(mixed.Pix, first.Pix) {
+		t.Error("Expected body 3 to be the first body of the second pack")
+	}
+
+	// Monsters mix parts from both packs
+	seen := map[bool]int{}
+	for i := 0; i < 50; i++ {
+		d := g.Describe([]byte(fmt.Sprintf("packs-%d", i)))
+		for _, part := range bodyParts {
+			seen[getPartNumber(&d, part) > 2]++
+		}
+	}
+	if seen[false] == 0 || seen[true] == 0 {
+		t.Errorf("Expected parts from both packs, got %v", seen)
+	}
+
+	if _, err := NewGeneratorFromPacks(); err == nil {
+		t.Error("Expected an error without packs")
+	}
+}
+
+func TestGeneratorReload(t *testing.T) {
+	fsys := testPack(t, 2)
+	g, err := NewGeneratorFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Render concurrently while the parts are swapped
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := g.Generate([]byte(fmt.Sprintf("reload-%d-%d", i, j))); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+
+	// New parts are picked up
+	more := testPack(t, 3)
+	for name, file := range more {
+		fsys[name] = file
+	}
+	if err := g.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+	if n := g.pack().counts.count("body"); n != 3 {
+		t.Errorf("Expected 3 body parts after reload, got %d", n)
+	}
+
+	// A broken pack keeps the current parts
+	fsys["eyes_1.png"] = &fstest.MapFile{Data: []byte("not a png")}
+	if err := g.Reload(); err == nil {
+		t.Error("Expected an error for a broken pack")
+	}
+	if _, err := g.Generate([]byte("reload")); err != nil {
+		t.Errorf("Unexpected error after a failed reload: %v", err)
+	}
+}
+
+func TestGeneratorWarmup(t *testing.T) {
+	fsys := testPack(t, 1)
+
+	// A @2x body with a mask only at the native resolution
+	body := image.NewRGBA(image.Rect(0, 0, 2*nativeSize, 2*nativeSize))
+	draw.Draw(body, body.Bounds(), image.NewUniform(color.RGBA{R: 200, G: 200, B: 200, A: 255}), image.Point{}, draw.Src)
+	mask := image.NewRGBA(image.Rect(0, 0, nativeSize, nativeSize))
+	draw.Draw(mask, image.Rect(0, 0, nativeSize/2, nativeSize), image.White, image.Point{}, draw.Src)
+	for name, img := range map[string]image.Image{"@2x/body_1.png": body, "body_1_mask.png": mask} {
+		buf := new(bytes.Buffer)
+		png.Encode(buf, img)
+		fsys[name] = &fstest.MapFile{Data: buf.Bytes()}
+	}
+
+	g, err := NewGeneratorFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	before, err := g.Generate([]byte("warmup"), WithSize(240))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := g.Warmup(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if m, ok := g.set.Load().parts[partKey{"body_1_mask.png", 2}]; !ok || m.Bounds().Dx() != 2*nativeSize {
+		t.Error("Expected the mask to be scaled for the @2x body")
+	}
+
+	after, err := g.Generate([]byte("warmup"), WithSize(240))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(before.(*image.RGBA).Pix, after.(*image.RGBA).Pix) {
+		t.Error("Expected Warmup to keep the rendered image")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.Warmup(ctx); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestGeneratorsCoexist(t *testing.T) {
+	small, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	large, err := NewGeneratorFromFS(testPack(t, 3))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Each generator selects from its own parts, also at the same time
+	var wg sync.WaitGroup
+	for _, test := range []struct {
+		g *Generator
+		n int
+	}{{small, 2}, {large, 3}, {small, 2}, {large, 3}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen := make(map[int]bool)
+			for i := 0; i < 50; i++ {
+				d := test.g.Describe([]byte(fmt.Sprintf("coexist-%d", i)))
+				if d.Body < 1 || d.Body > test.n {
+					t.Errorf("Expected a body out of %d, got %d", test.n, d.Body)
+					return
+				}
+				seen[d.Body] = true
+			}
+			if len(seen) != test.n {
+				t.Errorf("Expected all %d bodies to be selected, got %v", test.n, seen)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewThemeGenerator(t *testing.T) {
+	for _, theme := range Themes() {
+		g, err := NewThemeGenerator(theme)
+		if err != nil {
+			t.Fatalf("Failed to create generator: %v", err)
+		}
+		for i := 0; i < 5; i++ {
+			hash := []byte(fmt.Sprintf("theme-generator-%d", i))
+			got, err := g.Generate(hash, WithSize(64))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := New(hash, WithTheme(theme), WithSize(64))
+			if !bytes.Equal(got.(*image.RGBA).Pix, want.(*image.RGBA).Pix) {
+				t.Errorf("Expected the %s generator to draw like WithTheme for %s", theme, hash)
+			}
+		}
+	}
+
+	if _, err := NewThemeGenerator("plush"); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
@@ -0,0 +1,81 @@
+package monsterid
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+// goldenCases are rendered for every algorithm version. The images must never
+// change within a version, since users would lose their avatar.
+var goldenCases = []struct {
+	name string
+	hash string
+	opts []Option
+}{
+	{"default", "golden@example.com", nil},
+	{"plain", "plain@example.com", []Option{WithArtistic(false)}},
+	{"greyscale", "grey@example.com", []Option{WithGreyscale()}},
+	{"small", "small@example.com", []Option{WithSize(48)}},
+}
+
+func TestGolden(t *testing.T) {
+	for v := V1; v <= LatestVersion; v++ {
+		for _, test := range goldenCases {
+			name := fmt.Sprintf("v%d_%s.png", v, test.name)
+			path := filepath.Join("testdata", "golden", name)
+			img := toNRGBA(New([]byte(test.hash), withVersion(test.opts, v)...))
+
+			if *update {
+				buf := new(bytes.Buffer)
+				if err := png.Encode(buf, img); err != nil {
+					t.Fatalf("Failed to encode %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+				continue
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatalf("Failed to open %s, run with -update to create it: %v", name, err)
+			}
+			want, err := png.Decode(f)
+			f.Close()
+			if err != nil {
+				t.Fatalf("Failed to decode %s: %v", name, err)
+			}
+
+			if !bytes.Equal(img.Pix, toNRGBA(want).Pix) || img.Bounds() != want.Bounds() {
+				t.Errorf("Image differs from %s", name)
+			}
+		}
+	}
+}
+
+func TestGoldenVersionsDiffer(t *testing.T) {
+	// Find a hash that V2 mirrors, so the versions are told apart
+	for i := 0; i < 256; i++ {
+		hash := []byte{byte(i)}
+		if !Describe(hash, WithAlgorithmVersion(V2)).Mirrored {
+			continue
+		}
+
+		v1 := NewV1(hash).(*image.RGBA)
+		v2 := NewV2(hash).(*image.RGBA)
+		if bytes.Equal(v1.Pix, v2.Pix) {
+			t.Error("Expected V1 and V2 to render a mirrored monster differently")
+		}
+		return
+	}
+
+	t.Fatal("No mirrored monster found")
+}
@@ -0,0 +1,132 @@
+package monsterid
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// gravatarSize is the size of Gravatar avatars without a size parameter.
+const gravatarSize = 80
+
+// gravatarStyles are the styles of the Gravatar defaults drawn by a style
+// other than StyleMonster.
+var gravatarStyles = map[string]Style{
+	"identicon": StyleIdenticon,
+	"wavatar":   StyleWavatar,
+	"robohash":  StyleRobohash,
+	"retro":     StylePixel,
+}
+
+// Helper to serve an avatar at /avatar/{hash} following the Gravatar URL
+// conventions, which Libravatar shares. The hash is the hex MD5 or SHA-256
+// digest of an email address, optionally with an extension such as .png or
+// .jpg. There are no uploaded avatars, only those proxied from Gravatar, so
+// requests get the default avatar of the d or default parameter:
+//   - 404 responds with 404 Not Found, so the client shows its own default
+//   - blank responds with a transparent PNG
+//   - an http or https URL redirects to that URL
+//   - identicon, wavatar and robohash respond with the avatar of that style,
+//     and retro with StylePixel
+//   - monsterid or any other built-in default responds with the monster
+//
+// The s or size parameter sets the size, 80 by default and clamped to
+// HandlerConfig.MaxSize like Gravatar does. The f or forcedefault parameter
+// forces the default avatar, and the r or rating parameter is passed on to
+// Gravatar.
+//
+// Without a HandlerConfig.GravatarURL there are no real avatars, so the
+// default avatar is always served. With one, Gravatar is asked for the avatar
+// of the hash with d=404 first and its avatar is served if it has one. The
+// answer is cached and both kinds of avatars are cached by browsers for only
+// 5 minutes, as users may add an avatar at any time. When Gravatar doesn't
+// answer within HandlerConfig.GravatarTimeout, the default avatar is served.
+func (h *handler) serveGravatar(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	name := r.PathValue("hash")
+	format := h.cfg.Format
+	if ext := path.Ext(name); ext != "" {
+		name = strings.TrimSuffix(name, ext)
+		// Gravatar serves JPEG for .jpg, the closest here is PNG
+		if f := Format(ext[1:]); f == FormatPNG || f == FormatGIF {
+			format = f
+		} else {
+			format = FormatPNG
+		}
+	}
+	hash, err := gravatarHash(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	size := gravatarSize
+	if s := queryParam(query, "s", "size"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			size = min(n, h.cfg.MaxSize)
+		}
+	}
+
+	req, err := h.request(hash, format, query, append(slices.Clip(h.cfg.Options), WithSize(size)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.gravatars != nil && queryParam(query, "f", "forcedefault") != "y" {
+		// Without an answer in time, the default avatar stands in for now
+		avatar, _ := h.fetchGravatar(r.Context(), hash, size, queryParam(query, "r", "rating"))
+		if avatar != nil {
+			h.writeGravatar(w, r, avatar)
+			return
+		}
+		req.mutable = true
+	}
+	switch d := queryParam(query, "d", "default"); {
+	case d == "404":
+		http.NotFound(w, r)
+		return
+	case d == "blank":
+		req.blank, req.format = true, FormatPNG
+		req.key += "/blank"
+	case gravatarStyles[d] != "":
+		req.o.Style = gravatarStyles[d]
+		req.key += "/" + d
+	case strings.HasPrefix(d, "http://") || strings.HasPrefix(d, "https://"):
+		if u, err := url.Parse(d); err == nil && u.Host != "" {
+			http.Redirect(w, r, u.String(), http.StatusFound)
+			return
+		}
+		http.Error(w, "invalid default URL", http.StatusBadRequest)
+		return
+	}
+
+	h.write(w, r, req)
+}
+
+// Helper to normalize a Gravatar hash to lowercase hex, which is the hash
+// input of the monster
+func gravatarHash(name string) ([]byte, error) {
+	name = strings.ToLower(name)
+	if _, err := hex.DecodeString(name); err != nil || (len(name) != 32 && len(name) != 64) {
+		return nil, errors.New("hash must be a hex MD5 or SHA-256 digest")
+	}
+
+	return []byte(name), nil
+}
+
+// Helper to get the first of several names of a query parameter
+func queryParam(query url.Values, names ...string) string {
+	for _, name := range names {
+		if v := query.Get(name); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
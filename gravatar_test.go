@@ -0,0 +1,88 @@
+package monsterid
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+func TestHandlerGravatar(t *testing.T) {
+	h := Handler(HandlerConfig{MaxSize: 256})
+	const md5 = "0bc83cb571cd1c50ba6f3e8a78ef1346"
+	tests := []struct {
+		target string
+		format Format
+		style  Style
+		size   int
+	}{
+		{"/avatar/" + md5, FormatPNG, "", 80},
+		{"/avatar/" + md5 + "?s=40", FormatPNG, "", 40},
+		{"/avatar/" + md5 + "?size=48&d=monsterid&f=y&r=g", FormatPNG, "", 48},
+		{"/avatar/" + md5 + ".jpg?s=32", FormatPNG, "", 32},
+		{"/avatar/" + md5 + ".gif?s=32", FormatGIF, "", 32},
+		{"/avatar/0BC83CB571CD1C50BA6F3E8A78EF1346?d=identicon", FormatPNG, StyleIdenticon, 80},
+		{"/avatar/" + md5 + "?d=wavatar&s=64", FormatPNG, StyleWavatar, 64},
+		{"/avatar/" + md5 + ".gif?d=robohash", FormatGIF, StyleRobohash, 80},
+		{"/avatar/" + md5 + "?d=retro&s=64", FormatPNG, StylePixel, 64},
+		{"/avatar/" + md5 + "?s=2048", FormatPNG, "", 256},
+		{"/avatar/" + md5 + "?s=large", FormatPNG, "", 80},
+	}
+
+	for _, test := range tests {
+		rec := serve(h, http.MethodGet, test.target)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected %s to be served, got %d: %s", test.target, rec.Code, rec.Body)
+		}
+
+		want := new(bytes.Buffer)
+		if err := Render(want, []byte(md5), test.format, WithSize(test.size), WithStyle(test.style)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+			t.Errorf("Expected %s to serve the %q style at %d pixels", test.target, test.style, test.size)
+		}
+	}
+}
+
+func TestHandlerGravatarDefaults(t *testing.T) {
+	h := Handler(HandlerConfig{})
+	const sha256 = "b4c9a289323b21a01c3e940f150eb9b8c542587f1abfd8f0e1cc1ffc5e475514"
+
+	if rec := serve(h, http.MethodGet, "/avatar/"+sha256+"?d=404"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected d=404 to be 404, got %d", rec.Code)
+	}
+
+	rec := serve(h, http.MethodGet, "/avatar/"+sha256+"?default=https%3A%2F%2Fexample.com%2Fdefault.png")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/default.png" {
+		t.Errorf("Expected a redirect to the default URL, got %d to %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = serve(h, http.MethodGet, "/avatar/"+sha256+"?d=blank&s=16")
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 16 {
+		t.Errorf("Expected a 16 pixel blank avatar, got %v", img.Bounds())
+	}
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				t.Fatalf("Expected a transparent avatar, got alpha %d at %d,%d", a, x, y)
+			}
+		}
+	}
+	if rec.Header().Get("ETag") == serve(h, http.MethodGet, "/avatar/"+sha256+"?s=16").Header().Get("ETag") {
+		t.Error("Expected blank avatars to have their own ETag")
+	}
+	if serve(h, http.MethodGet, "/avatar/"+sha256+"?d=wavatar").Header().Get("ETag") == serve(h, http.MethodGet, "/avatar/"+sha256).Header().Get("ETag") {
+		t.Error("Expected wavatars to have their own ETag")
+	}
+
+	for _, target := range []string{"/avatar/alice", "/avatar/" + sha256[:40], "/avatar/" + sha256 + "?d=http%3A%2F%2F"} {
+		if rec := serve(h, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected %s to be rejected, got %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultGravatarTimeout is how long Handler waits for
+// HandlerConfig.GravatarURL if GravatarTimeout is zero.
+const defaultGravatarTimeout = 2 * time.Second
+
+// gravatarMaxAge is how long Gravatar checks are cached, and how long
+// browsers cache the avatars they decide, as users may add or change their
+// avatar at any time. Gravatar itself caches avatars this long.
+const gravatarMaxAge = 5 * time.Minute
+
+// maxGravatarBytes is the largest avatar proxied from Gravatar.
+const maxGravatarBytes = 4 << 20
+
+// gravatarCacheBytes is the size of the cache of Gravatar checks without a
+// HandlerConfig.Cache or CacheBytes.
+const gravatarCacheBytes = 16 << 20
+
+// gravatarAvatar is a real avatar fetched from Gravatar.
+type gravatarAvatar struct {
+	contentType string
+	data        []byte
+}
+
+// Helper to get the real avatar of a hash at size from
+// HandlerConfig.GravatarURL, or nil if it has none. Answers are cached for
+// gravatarMaxAge, including the lack of an avatar, but failures aren't.
+func (h *handler) fetchGravatar(ctx context.Context, hash []byte, size int, rating string) (*gravatarAvatar, error) {
+	key := fmt.Sprintf("gravatar/%s/%d/%s", hash, size, rating)
+	if data, err := h.gravatars.Get(ctx, key); err == nil {
+		return decodeGravatar(data), nil
+	}
+
+	query := url.Values{"d": {"404"}, "s": {strconv.Itoa(size)}}
+	if rating != "" {
+		query.Set("r", rating)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.GravatarTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(h.cfg.GravatarURL, "/")+"/"+string(hash)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: gravatar: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: gravatar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var avatar *gravatarAvatar
+	switch contentType := resp.Header.Get("Content-Type"); {
+	case resp.StatusCode == http.StatusNotFound:
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("monsterid: gravatar %s: %s", hash, resp.Status)
+	case !strings.HasPrefix(contentType, "image/"):
+		return nil, fmt.Errorf("monsterid: gravatar %s: unexpected content type %q", hash, contentType)
+	default:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxGravatarBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("monsterid: gravatar %s: %w", hash, err)
+		}
+		if len(data) > maxGravatarBytes {
+			return nil, fmt.Errorf("monsterid: gravatar %s is larger than %d bytes", hash, maxGravatarBytes)
+		}
+		avatar = &gravatarAvatar{contentType: contentType, data: data}
+	}
+
+	// A failure only costs checking again
+	h.gravatars.Set(ctx, key, avatar.encode(), gravatarMaxAge)
+
+	return avatar, nil
+}
+
+// Helper to encode an avatar for the cache as its content type and data
+// separated by a newline, or nothing for no avatar
+func (a *gravatarAvatar) encode() []byte {
+	if a == nil {
+		return nil
+	}
+
+	return append([]byte(a.contentType+"\n"), a.data...)
+}
+
+// Helper to decode an avatar encoded by gravatarAvatar.encode
+func decodeGravatar(data []byte) *gravatarAvatar {
+	contentType, data, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil
+	}
+
+	return &gravatarAvatar{contentType: string(contentType), data: data}
+}
+
+// Helper to write a real avatar fetched from Gravatar
+func (h *handler) writeGravatar(w http.ResponseWriter, r *http.Request, avatar *gravatarAvatar) {
+	header := w.Header()
+	sum := sha256.Sum256(avatar.data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(min(h.cfg.MaxAge, gravatarMaxAge).Seconds())))
+	if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatch(inm, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", avatar.contentType)
+	header.Set("Content-Length", strconv.Itoa(len(avatar.data)))
+	w.Write(avatar.data)
+}
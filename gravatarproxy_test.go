@@ -0,0 +1,117 @@
+package monsterid
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandlerGravatarProxy(t *testing.T) {
+	const (
+		real    = "0bc83cb571cd1c50ba6f3e8a78ef1346"
+		none    = "b4c9a289323b21a01c3e940f150eb9b8c542587f1abfd8f0e1cc1ffc5e475514"
+		failing = "00000000000000000000000000000000"
+	)
+	var checks atomic.Int32
+	gravatar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+		if d := r.URL.Query().Get("d"); d != "404" {
+			t.Errorf("Expected d=404, got %q", d)
+		}
+		switch r.URL.Path {
+		case "/avatar/" + real:
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write([]byte("jpeg of size " + r.URL.Query().Get("s") + " rated " + r.URL.Query().Get("r")))
+		case "/avatar/" + failing:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer gravatar.Close()
+
+	h := Handler(HandlerConfig{GravatarURL: gravatar.URL + "/avatar/"})
+
+	rec := serve(h, http.MethodGet, "/avatar/"+real+"?s=40&r=pg")
+	if rec.Code != http.StatusOK || rec.Body.String() != "jpeg of size 40 rated pg" {
+		t.Fatalf("Expected the real avatar, got %d: %q", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Expected the content type of Gravatar, got %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("Expected a short Cache-Control, got %q", cc)
+	}
+	if again := serve(h, http.MethodGet, "/avatar/"+real+"?s=40&r=pg"); again.Body.String() != rec.Body.String() || checks.Load() != 1 {
+		t.Errorf("Expected the check to be cached, got %d checks", checks.Load())
+	}
+
+	rec = serve(h, http.MethodGet, "/avatar/"+none+"?s=40")
+	want := new(bytes.Buffer)
+	if err := Render(want, []byte(none), FormatPNG, WithSize(40)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+		t.Errorf("Expected the monster without a real avatar, got %d", rec.Code)
+	}
+	if cc := rec.Header().Get("Cache-Control"); strings.Contains(cc, "immutable") {
+		t.Errorf("Expected the monster to be cached briefly, got %q", cc)
+	}
+	serve(h, http.MethodGet, "/avatar/"+none+"?s=40")
+	if checks.Load() != 2 {
+		t.Errorf("Expected the lack of an avatar to be cached, got %d checks", checks.Load())
+	}
+	if rec := serve(h, http.MethodGet, "/avatar/"+none+"?d=404"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected d=404 without a real avatar to be 404, got %d", rec.Code)
+	}
+
+	// Failures fall back to the monster and are checked again
+	for i := 0; i < 2; i++ {
+		if rec := serve(h, http.MethodGet, "/avatar/"+failing); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+			t.Errorf("Expected the monster when Gravatar fails, got %d", rec.Code)
+		}
+	}
+	if checks.Load() != 5 {
+		t.Errorf("Expected failures not to be cached, got %d checks", checks.Load())
+	}
+
+	if rec := serve(h, http.MethodGet, "/avatar/"+real+"?f=y"); rec.Header().Get("Content-Type") != "image/png" || checks.Load() != 5 {
+		t.Errorf("Expected f=y to skip Gravatar, got %q", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestHandlerGravatarProxyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	gravatar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer gravatar.Close()
+	defer close(release)
+
+	h := Handler(HandlerConfig{GravatarURL: gravatar.URL, GravatarTimeout: 50 * time.Millisecond})
+	start := time.Now()
+	rec := serve(h, http.MethodGet, "/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Expected the monster when Gravatar is slow, got %d", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the check to time out, took %v", elapsed)
+	}
+}
+
+func TestGravatarAvatarEncode(t *testing.T) {
+	avatar := &gravatarAvatar{contentType: "image/png", data: []byte("\x89PNG\n")}
+	got := decodeGravatar(avatar.encode())
+	if got == nil || got.contentType != avatar.contentType || !bytes.Equal(got.data, avatar.data) {
+		t.Errorf("Expected %+v, got %+v", avatar, got)
+	}
+
+	var none *gravatarAvatar
+	if got := decodeGravatar(none.encode()); got != nil {
+		t.Errorf("Expected no avatar, got %+v", got)
+	}
+}
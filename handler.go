@@ -0,0 +1,343 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxSize is the largest size Handler renders if HandlerConfig.MaxSize
+// is zero, and a sensible limit for other sizes from untrusted input.
+const DefaultMaxSize = 1024
+
+// defaultMaxAge is how long avatars are cached if HandlerConfig.MaxAge is
+// zero, the longest duration browsers support.
+const defaultMaxAge = 365 * 24 * time.Hour
+
+// seasonMaxAge is how long avatars with seasons are cached at most, as their
+// overlay changes with the date.
+const seasonMaxAge = 24 * time.Hour
+
+// HandlerConfig configures the avatar endpoint created with Handler.
+type HandlerConfig struct {
+	Generator *Generator // parts to render with, the embedded parts if nil
+	Options   []Option   // applied to every avatar before the query parameters
+	Format    Format     // format without a format parameter, FormatPNG if empty
+	MaxSize   int        // largest size accepted by the s parameter, 1024 if zero
+	Params    []string   // query parameters allowed to set options, such as ParamTheme, which a Generator ignores
+
+	MaxAge     time.Duration // how long browsers and CDNs cache avatars, a year if zero
+	Revision   string        // part of every ETag, change it along with Options or the parts
+	Cache      Cache         // stores encoded avatars, a MemoryCache of CacheBytes if nil
+	CacheBytes int64         // keep up to this many bytes of encoded avatars in memory, none if zero
+	Collector  *Collector    // counts requests, renders and durations, if not nil
+
+	MaxRenders    int           // avatars rendered at the same time, others wait for a turn, unlimited if zero
+	RenderTimeout time.Duration // longest wait and render of an avatar, unlimited if zero
+
+	Secret []byte // only serve URLs signed with this key by Sign, any URL if empty
+
+	GravatarURL     string        // serve real avatars from this Gravatar server at /avatar/{hash}, such as https://secure.gravatar.com/avatar
+	GravatarTimeout time.Duration // longest check of GravatarURL for a real avatar, 2 seconds if zero
+}
+
+// Handler returns an http.Handler serving the monster for a hash at
+// GET /{hash}, such as /alice?s=240&format=svg. The s parameter sets the
+// size in pixels, up to HandlerConfig.MaxSize, and the format parameter one
+// of png, gif, bmp, tiff or svg. Invalid parameters are rejected with 400 Bad
+// Request. Use http.StripPrefix to serve it under a path such as /avatars/.
+//
+// Gravatar URLs are served at GET /avatar/{hash}, so software using Gravatar
+// can use the handler for its default avatars, as described by serveGravatar.
+//
+// The query parameters listed in HandlerConfig.Params set further options,
+// such as ?style=identicon&bg=00000000&shape=circle&grey=1, so avatars can be
+// styled by the page showing them. Others are ignored and invalid values are
+// rejected with 400 Bad Request. Handler panics if Params lists an unknown
+// parameter.
+//
+// Avatars never change for the same request, so they are served with a
+// strong ETag of the hash, format, size and algorithm version, and cached for
+// HandlerConfig.MaxAge as immutable. Requests with a matching If-None-Match
+// header get 304 Not Modified without rendering. With a HandlerConfig.Cache or
+// CacheBytes, encoded avatars are cached for as long as browsers may cache
+// them and served without rendering them again. Cache failures are treated as
+// misses.
+//
+// HandlerConfig.MaxRenders and RenderTimeout keep bursts of large avatars from
+// exhausting the server: renders beyond the limit wait for a turn, and
+// requests that can't be rendered in time get 503 Service Unavailable.
+//
+// With a HandlerConfig.Secret, only URLs with a sig parameter computed by
+// Sign are served and others get 403 Forbidden, so a public endpoint can't be
+// used to render arbitrary inputs and sizes.
+//
+// With a HandlerConfig.GravatarURL, /avatar/{hash} first asks Gravatar for a
+// real avatar and only falls back to the default avatar without one, as
+// described by serveGravatar.
+func Handler(cfg HandlerConfig) http.Handler {
+	return newHandler(cfg).routes()
+}
+
+// Helper to create the handler of cfg, filling in the defaults
+func newHandler(cfg HandlerConfig) *handler {
+	if cfg.Format == "" {
+		cfg.Format = FormatPNG
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxSize
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaultMaxAge
+	}
+	if err := ValidateParams(cfg.Params); err != nil {
+		panic(err)
+	}
+	if cfg.Generator != nil {
+		// A Generator draws its own parts whatever the theme, so the
+		// parameter would only split the cache
+		cfg.Params = slices.DeleteFunc(slices.Clone(cfg.Params), func(p string) bool { return p == ParamTheme })
+	}
+
+	h := &handler{cfg: cfg, cache: cfg.Cache}
+	if cfg.MaxRenders > 0 {
+		h.renders = make(chan struct{}, cfg.MaxRenders)
+	}
+	if h.cache == nil && cfg.CacheBytes > 0 {
+		h.cache = NewMemoryCache(cfg.CacheBytes)
+	}
+	if cfg.GravatarURL != "" {
+		if h.cfg.GravatarTimeout <= 0 {
+			h.cfg.GravatarTimeout = defaultGravatarTimeout
+		}
+		h.gravatars = h.cache
+		if h.gravatars == nil {
+			h.gravatars = NewMemoryCache(gravatarCacheBytes)
+		}
+	}
+
+	return h
+}
+
+// Helper to route the endpoints of the handler
+func (h *handler) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{hash}", h.signed(h.serveAvatar))
+	mux.HandleFunc("GET /avatar/{hash}", h.signed(h.serveGravatar))
+
+	return mux
+}
+
+// handler serves the avatars of Handler.
+type handler struct {
+	cfg       HandlerConfig
+	cache     Cache         // encoded avatars, nil without a cache
+	gravatars Cache         // Gravatar checks, nil without HandlerConfig.GravatarURL
+	renders   chan struct{} // a slot per render in progress, nil if unlimited
+}
+
+// avatarRequest is a validated request for an avatar.
+type avatarRequest struct {
+	hash    []byte
+	format  Format
+	o       Options
+	key     string // canonical form of what the avatar depends on
+	blank   bool   // a transparent PNG instead of the monster
+	mutable bool   // checked with Gravatar, so a real avatar may replace it
+}
+
+// Helper to validate the path and query parameters of a request
+func (h *handler) parse(r *http.Request) (avatarRequest, error) {
+	format := h.cfg.Format
+	query := r.URL.Query()
+
+	if f := query.Get("format"); f != "" {
+		format = Format(f)
+		if format.ContentType() == "" {
+			return avatarRequest{}, fmt.Errorf("unknown format %q", f)
+		}
+	}
+
+	opts := slices.Clip(h.cfg.Options)
+	if s := query.Get("s"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size <= 0 || size > h.cfg.MaxSize {
+			return avatarRequest{}, fmt.Errorf("size must be between 1 and %d", h.cfg.MaxSize)
+		}
+		opts = append(opts, WithSize(size))
+	}
+
+	return h.request([]byte(r.PathValue("hash")), format, query, opts)
+}
+
+// Helper to build the request for an avatar of hash in format, with the
+// options set by the allowed query parameters on top of opts
+func (h *handler) request(hash []byte, format Format, query url.Values, opts []Option) (avatarRequest, error) {
+	paramOpts, params, err := paramOptions(query, h.cfg.Params)
+	if err != nil {
+		return avatarRequest{}, err
+	}
+
+	req := avatarRequest{hash: hash, format: format, o: buildOptions(append(opts, paramOpts...))}
+	season, _ := req.o.season()
+	req.key = fmt.Sprintf("%s/%s/v%d/%s/%d/%s/%s/%x", h.cfg.Revision, req.o.theme(), req.o.version(),
+		season.Name, req.o.size(), req.format, params, req.hash)
+
+	return req, nil
+}
+
+// Helper to get the strong ETag of a request
+func (req avatarRequest) etag() string {
+	sum := sha256.Sum256([]byte(req.key))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Helper to check if an If-None-Match header lists etag, comparing weakly as
+// required for If-None-Match
+func etagMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Helper to serve the avatar at /{hash}
+func (h *handler) serveAvatar(w http.ResponseWriter, r *http.Request) {
+	req, err := h.parse(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.write(w, r, req)
+}
+
+// Helper to render and write the avatar of a request
+func (h *handler) write(w http.ResponseWriter, r *http.Request, req avatarRequest) {
+	header := w.Header()
+	etag := req.etag()
+	header.Set("ETag", etag)
+	maxAge := h.cfg.MaxAge
+	if req.mutable {
+		maxAge = min(maxAge, gravatarMaxAge)
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(maxAge.Seconds())))
+	} else if len(req.o.Seasons) > 0 {
+		maxAge = min(maxAge, seasonMaxAge)
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(maxAge.Seconds())))
+	} else {
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int64(maxAge.Seconds())))
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatch(inm, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", req.format.ContentType())
+	var times renderTimes
+	if h.cache != nil {
+		ctx := r.Context()
+		data, err := h.cache.Get(ctx, req.key)
+		h.cfg.Collector.cached(err == nil)
+		if err != nil {
+			buf := new(bytes.Buffer)
+			if err := h.render(ctx, buf, req, &times); err != nil {
+				h.fail(w, err)
+				return
+			}
+			h.cfg.Collector.rendered(times)
+			data = buf.Bytes()
+			// A failure only costs rendering it again
+			h.cache.Set(ctx, req.key, data, maxAge)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+		h.cfg.Collector.served(req.format, int64(len(data)))
+		return
+	}
+
+	cw := &countingWriter{w: w}
+	err := h.render(r.Context(), cw, req, &times)
+	if err != nil && cw.n == 0 {
+		h.fail(w, err)
+		return
+	}
+	if err == nil {
+		h.cfg.Collector.rendered(times)
+	}
+	h.cfg.Collector.served(req.format, cw.n)
+}
+
+// Helper to respond with the error of a render that didn't write anything
+func (h *handler) fail(w http.ResponseWriter, err error) {
+	// Failures must not be cached like the avatar
+	header := w.Header()
+	header.Del("ETag")
+	header.Set("Cache-Control", "no-store")
+
+	status := http.StatusInternalServerError
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusServiceUnavailable
+		header.Set("Retry-After", "1")
+		h.cfg.Collector.timedOut()
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// Helper to render the avatar of a request to w, measuring how long it takes
+// into times
+func (h *handler) render(ctx context.Context, w io.Writer, req avatarRequest, times *renderTimes) error {
+	if h.cfg.RenderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.RenderTimeout)
+		defer cancel()
+	}
+	if h.renders != nil {
+		select {
+		case h.renders <- struct{}{}:
+			defer func() { <-h.renders }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if req.blank {
+		img := getRGBA(image.Rect(0, 0, req.o.size(), req.o.size()))
+		defer putRGBA(img)
+		return png.Encode(w, img)
+	}
+	if g := h.cfg.Generator; g != nil {
+		return g.renderHash(ctx, w, req.hash, req.format, req.o, times)
+	}
+
+	return renderHash(ctx, w, req.hash, req.format, req.o, times)
+}
+
+// countingWriter counts the bytes written through it, so errors are only
+// reported before the response has started.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,246 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Helper to send a request to h and record the response
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	h := Handler(HandlerConfig{})
+	tests := []struct {
+		target      string
+		hash        string
+		format      Format
+		contentType string
+		opts        []Option
+	}{
+		{"/alice", "alice", FormatPNG, "image/png", nil},
+		{"/alice?s=64", "alice", FormatPNG, "image/png", []Option{WithSize(64)}},
+		{"/alice?format=gif", "alice", FormatGIF, "image/gif", nil},
+		{"/alice?format=bmp&s=32", "alice", FormatBMP, "image/bmp", []Option{WithSize(32)}},
+		{"/alice?format=tiff", "alice", FormatTIFF, "image/tiff", nil},
+		{"/alice?format=svg&s=240", "alice", FormatSVG, "image/svg+xml", []Option{WithSize(240)}},
+		{"/user%40example.com", "user@example.com", FormatPNG, "image/png", nil},
+	}
+
+	for _, test := range tests {
+		rec := serve(h, http.MethodGet, test.target)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected %s to be served, got %d: %s", test.target, rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("Content-Type"); got != test.contentType {
+			t.Errorf("Expected %s to be %s, got %s", test.target, test.contentType, got)
+		}
+
+		want := new(bytes.Buffer)
+		if err := Render(want, []byte(test.hash), test.format, test.opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+			t.Errorf("Expected %s to serve the rendered avatar", test.target)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	h := Handler(HandlerConfig{MaxSize: 256})
+	tests := []struct {
+		method string
+		target string
+		status int
+	}{
+		{http.MethodGet, "/alice?s=0", http.StatusBadRequest},
+		{http.MethodGet, "/alice?s=-1", http.StatusBadRequest},
+		{http.MethodGet, "/alice?s=large", http.StatusBadRequest},
+		{http.MethodGet, "/alice?s=257", http.StatusBadRequest},
+		{http.MethodGet, "/alice?format=jpeg", http.StatusBadRequest},
+		{http.MethodGet, "/", http.StatusNotFound},
+		{http.MethodGet, "/alice/bob", http.StatusNotFound},
+		{http.MethodPost, "/alice", http.StatusMethodNotAllowed},
+		{http.MethodHead, "/alice?s=256", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		if rec := serve(h, test.method, test.target); rec.Code != test.status {
+			t.Errorf("Expected %s %s to be %d, got %d", test.method, test.target, test.status, rec.Code)
+		}
+	}
+}
+
+func TestHandlerConfig(t *testing.T) {
+	g, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	h := Handler(HandlerConfig{Generator: g, Options: []Option{WithSize(48), WithGreyscale()}, Format: FormatSVG})
+
+	rec := serve(h, http.MethodGet, "/alice")
+	want := new(bytes.Buffer)
+	if err := g.Render(want, []byte("alice"), FormatSVG, WithSize(48), WithGreyscale()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+		t.Error("Expected the configured generator, options and format")
+	}
+
+	// Query parameters override the configured options
+	rec = serve(h, http.MethodGet, "/alice?s=24&format=png")
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if size := img.Bounds().Dx(); size != 24 {
+		t.Errorf("Expected a size of 24, got %d", size)
+	}
+	if g.Stats().Monsters != 3 {
+		t.Errorf("Expected 3 monsters rendered by the generator, got %d", g.Stats().Monsters)
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	h := Handler(HandlerConfig{})
+	etag := serve(h, http.MethodGet, "/alice?s=64").Header().Get("ETag")
+	if len(etag) != 34 || etag[0] != '"' || etag[33] != '"' {
+		t.Fatalf("Expected a strong ETag, got %q", etag)
+	}
+	if got := serve(h, http.MethodGet, "/alice?s=64").Header().Get("ETag"); got != etag {
+		t.Errorf("Expected the same ETag for the same avatar, got %q and %q", etag, got)
+	}
+
+	// Anything changing the avatar changes the ETag
+	others := []struct {
+		h      http.Handler
+		target string
+	}{
+		{h, "/bob?s=64"},
+		{h, "/alice?s=65"},
+		{h, "/alice?s=64&format=gif"},
+		{Handler(HandlerConfig{Options: []Option{WithAlgorithmVersion(V2)}}), "/alice?s=64"},
+		{Handler(HandlerConfig{Options: []Option{WithTheme(ThemeRobot)}}), "/alice?s=64"},
+		{Handler(HandlerConfig{Revision: "2"}), "/alice?s=64"},
+	}
+	for _, other := range others {
+		if got := serve(other.h, http.MethodGet, other.target).Header().Get("ETag"); got == etag {
+			t.Errorf("Expected a different ETag for %s", other.target)
+		}
+	}
+}
+
+func TestHandlerConditionalGet(t *testing.T) {
+	h := Handler(HandlerConfig{})
+	etag := serve(h, http.MethodGet, "/alice").Header().Get("ETag")
+
+	tests := []struct {
+		ifNoneMatch string
+		status      int
+	}{
+		{etag, http.StatusNotModified},
+		{"W/" + etag, http.StatusNotModified},
+		{`"other", ` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{`"other"`, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/alice", nil)
+		req.Header.Set("If-None-Match", test.ifNoneMatch)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != test.status {
+			t.Errorf("Expected If-None-Match %s to be %d, got %d", test.ifNoneMatch, test.status, rec.Code)
+		}
+		if rec.Code == http.StatusNotModified && (rec.Body.Len() > 0 || rec.Header().Get("ETag") != etag) {
+			t.Errorf("Expected an empty 304 with the ETag for %s", test.ifNoneMatch)
+		}
+	}
+}
+
+func TestHandlerCacheControl(t *testing.T) {
+	tests := []struct {
+		cfg  HandlerConfig
+		want string
+	}{
+		{HandlerConfig{}, "public, max-age=31536000, immutable"},
+		{HandlerConfig{MaxAge: time.Hour}, "public, max-age=3600, immutable"},
+		{HandlerConfig{Options: []Option{WithSeasons(Seasons()...)}}, "public, max-age=86400"},
+		{HandlerConfig{Options: []Option{WithSeasons(Seasons()...)}, MaxAge: time.Hour}, "public, max-age=3600"},
+	}
+
+	for _, test := range tests {
+		if got := serve(Handler(test.cfg), http.MethodGet, "/alice").Header().Get("Cache-Control"); got != test.want {
+			t.Errorf("Expected %q, got %q", test.want, got)
+		}
+	}
+}
+
+func TestHandlerMaxRenders(t *testing.T) {
+	c := NewCollector()
+	h := newHandler(HandlerConfig{MaxRenders: 1, RenderTimeout: 20 * time.Millisecond, Collector: c})
+	routes := h.routes()
+
+	// Requests give up waiting for a busy slot
+	h.renders <- struct{}{}
+	rec := serve(routes, http.MethodGet, "/alice")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After while busy, got %d", rec.Code)
+	}
+	if rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("Expected the failure not to be cacheable")
+	}
+	if !strings.Contains(serve(c, http.MethodGet, "/metrics").Body.String(), "monsterid_unavailable_total 1\n") {
+		t.Error("Expected the unavailable avatar to be counted")
+	}
+
+	<-h.renders
+	if rec := serve(routes, http.MethodGet, "/alice"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the avatar once a slot is free, got %d", rec.Code)
+	}
+}
+
+func TestHandlerMaxRendersConcurrent(t *testing.T) {
+	h := Handler(HandlerConfig{MaxRenders: 2})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve(h, http.MethodGet, fmt.Sprintf("/concurrent-%d?s=240", i)).Code
+		}()
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("Expected request %d to wait for its turn, got %d", i, code)
+		}
+	}
+}
+
+func TestHandlerRenderTimeout(t *testing.T) {
+	h := Handler(HandlerConfig{RenderTimeout: time.Nanosecond})
+	if rec := serve(h, http.MethodGet, "/alice?s=1024"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for a render over the timeout, got %d", rec.Code)
+	}
+
+	h = Handler(HandlerConfig{RenderTimeout: time.Minute})
+	if rec := serve(h, http.MethodGet, "/alice"); rec.Code != http.StatusOK {
+		t.Errorf("Expected a render within the timeout, got %d", rec.Code)
+	}
+}
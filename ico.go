@@ -0,0 +1,93 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// FaviconSizes are the sizes bundled into a favicon.ico by default.
+var FaviconSizes = []int{16, 32, 48}
+
+// AppleTouchIconSizes are the apple-touch-icon sizes used by iOS devices.
+var AppleTouchIconSizes = []int{120, 152, 167, 180}
+
+// ICO creates a multi-resolution favicon for the provided hash, see EncodeICO.
+func ICO(hash []byte, sizes []int, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeICO(buf, hash, sizes, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeICO writes a .ico file to w containing the monster for the provided
+// hash at each of sizes (FaviconSizes if empty, at most 256), stored as PNG.
+func EncodeICO(w io.Writer, hash []byte, sizes []int, opts ...Option) error {
+	if len(sizes) == 0 {
+		sizes = FaviconSizes
+	}
+
+	images := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		if size < 1 || size > 256 {
+			return fmt.Errorf("monsterid: icon size %d out of range 1-256", size)
+		}
+
+		data, err := PNG(hash, append(slices.Clone(opts), WithSize(size))...)
+		if err != nil {
+			return err
+		}
+		images[i] = data
+	}
+
+	// ICONDIR header followed by one ICONDIRENTRY per image
+	const headerSize, entrySize = 6, 16
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, [3]uint16{0, 1, uint16(len(sizes))}) //nolint:errcheck // bytes.Buffer never fails
+
+	offset := headerSize + entrySize*len(sizes)
+	for i, size := range sizes {
+		dim := uint8(size) // 256 is stored as 0
+		entry := iconDirEntry{Width: dim, Height: dim, Planes: 1, BitCount: 32, Size: uint32(len(images[i])), Offset: uint32(offset)}
+		binary.Write(buf, binary.LittleEndian, entry) //nolint:errcheck // bytes.Buffer never fails
+		offset += len(images[i])
+	}
+	for _, data := range images {
+		buf.Write(data)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// iconDirEntry describes one image of an .ico file.
+type iconDirEntry struct {
+	Width, Height, Colors, Reserved uint8
+	Planes, BitCount                uint16
+	Size, Offset                    uint32
+}
+
+// AppleTouchIcons creates PNG apple-touch-icons for the provided hash keyed by
+// size, one for each of AppleTouchIconSizes. iOS shows transparent pixels as
+// black, so a transparent background is replaced by the default one.
+func AppleTouchIcons(hash []byte, opts ...Option) (map[int][]byte, error) {
+	o := buildOptions(opts)
+	if o.Background.A == 0 {
+		o.Background = DefaultOptions().Background
+	}
+
+	icons := make(map[int][]byte, len(AppleTouchIconSizes))
+	for _, size := range AppleTouchIconSizes {
+		data, err := PNG(hash, o, WithSize(size))
+		if err != nil {
+			return nil, err
+		}
+		icons[size] = data
+	}
+
+	return icons, nil
+}
@@ -0,0 +1,66 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"testing"
+)
+
+func TestICO(t *testing.T) {
+	data, err := ICO([]byte("ico-test"), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var header [3]uint16
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	if header != [3]uint16{0, 1, uint16(len(FaviconSizes))} {
+		t.Fatalf("Unexpected ICO header %v", header)
+	}
+
+	for i, size := range FaviconSizes {
+		entry := data[6+16*i:]
+		if int(entry[0]) != size || int(entry[1]) != size {
+			t.Errorf("Entry %d: expected %dx%d, got %dx%d", i, size, size, entry[0], entry[1])
+		}
+
+		length := binary.LittleEndian.Uint32(entry[8:])
+		offset := binary.LittleEndian.Uint32(entry[12:])
+		img, err := png.Decode(bytes.NewReader(data[offset : offset+length]))
+		if err != nil {
+			t.Fatalf("Entry %d: failed to decode PNG: %v", i, err)
+		}
+		if img.Bounds().Dx() != size {
+			t.Errorf("Entry %d: expected a %d pixel image, got %d", i, size, img.Bounds().Dx())
+		}
+	}
+}
+
+func TestICORejectsLargeSizes(t *testing.T) {
+	if _, err := ICO([]byte("ico-large"), []int{512}); err == nil {
+		t.Error("Expected an error for a 512 pixel icon")
+	}
+}
+
+func TestAppleTouchIcons(t *testing.T) {
+	icons, err := AppleTouchIcons([]byte("apple-touch"), WithTransparentBackground())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, size := range AppleTouchIconSizes {
+		img, err := png.Decode(bytes.NewReader(icons[size]))
+		if err != nil {
+			t.Fatalf("Size %d: failed to decode PNG: %v", size, err)
+		}
+		if img.Bounds().Dx() != size {
+			t.Errorf("Expected a %d pixel icon, got %d", size, img.Bounds().Dx())
+		}
+		if _, _, _, a := img.At(0, 0).RGBA(); a != 0xffff {
+			t.Errorf("Size %d: expected an opaque background", size)
+		}
+	}
+}
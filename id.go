@@ -0,0 +1,66 @@
+package monsterid
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ID returns a compact identifier of the monster for the provided hash, such
+// as "v1-b07e12m03a02l05h01-h0.42s0.81", made of the algorithm version, the
+// body, eyes, mouth, arms, legs and hair parts, and the body hue and
+// saturation rounded to two decimals. Mirrored monsters end in "-f", followed
+// by the accessory and the season if there are any, as in "-f-crown-pumpkin",
+// and other themes than ThemeClassic are prepended, as in "robot-v1-...".
+// Excluded parts come last, as in "-no-hair-arms". Other styles than
+// StyleMonster have no parts, so their ID is the style, version and a digest
+// of the hash, as in "identicon-v1-5d41402abc4b", followed by the initials
+// drawn by StyleInitials if Options.Initials has any.
+// The ID only identifies the selection of parts and colors: the size,
+// background, shape, format and other drawing options don't change it, so a
+// cache key needs them as well.
+func ID(hash []byte, opts ...Option) string {
+	o := buildOptions(opts)
+	if o.style() != StyleMonster {
+		return styleID(hash, o)
+	}
+
+	id := descriptorID(describeHash(hash, o), o.version())
+	if t := o.theme(); t != ThemeClassic {
+		id = string(t) + "-" + id
+	}
+	if s, ok := o.season(); ok {
+		id += "-" + s.Name
+	}
+	if excluded := slices.DeleteFunc(slices.Clone(bodyParts), func(part string) bool { return !o.excluded(part) }); len(excluded) > 0 {
+		id += "-no-" + strings.Join(excluded, "-")
+	}
+
+	return id
+}
+
+// Helper to format the ID of an avatar of a style other than StyleMonster
+func styleID(hash []byte, o Options) string {
+	sum := sha256.Sum256(hash)
+	id := fmt.Sprintf("%s-v%d-%x", o.style(), o.version(), sum[:6])
+	if letters := initialsOf(o.Initials); o.style() == StyleInitials && letters != "" {
+		id += "-" + letters
+	}
+
+	return id
+}
+
+// Helper to format the ID of a descriptor
+func descriptorID(d Descriptor, v Version) string {
+	id := fmt.Sprintf("v%d-b%02de%02dm%02da%02dl%02dh%02d-h%.2fs%.2f",
+		v, d.Body, d.Eyes, d.Mouth, d.Arms, d.Legs, d.Hair, d.Hue, d.Saturation)
+	if d.Mirrored {
+		id += "-f"
+	}
+	if d.Accessory != AccessoryNone {
+		id += "-" + string(d.Accessory)
+	}
+
+	return id
+}
@@ -0,0 +1,58 @@
+package monsterid
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	hash := []byte("id-test")
+
+	id := ID(hash)
+	if id != ID(hash) {
+		t.Fatal("ID is not deterministic")
+	}
+	if !regexp.MustCompile(`^v1-b\d\de\d\dm\d\da\d\dl\d\dh\d\d-h[01]\.\d\ds[01]\.\d\d$`).MatchString(id) {
+		t.Errorf("Unexpected ID format %q", id)
+	}
+	if ID([]byte("id-other")) == id {
+		t.Error("Expected different hashes to have different IDs")
+	}
+	if got, want := ID(hash, WithExclude("arms", "hair")), id+"-no-hair-arms"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestDescriptorID(t *testing.T) {
+	d := Descriptor{Legs: 5, Hair: 1, Arms: 2, Body: 7, Eyes: 12, Mouth: 3, Hue: 0.4213, Saturation: 0.8099}
+	if got, want := descriptorID(d, V1), "v1-b07e12m03a02l05h01-h0.42s0.81"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	d.Mirrored = true
+	if got, want := descriptorID(d, V2), "v2-b07e12m03a02l05h01-h0.42s0.81-f"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestStyleID(t *testing.T) {
+	hash := []byte("id-test")
+
+	id := ID(hash, WithStyle(StyleIdenticon))
+	if !regexp.MustCompile(`^identicon-v1-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("Unexpected ID format %q", id)
+	}
+	if ID(hash, WithStyle(StyleWavatar)) == id || ID([]byte("id-other"), WithStyle(StyleIdenticon)) == id {
+		t.Error("Expected different styles and hashes to have different IDs")
+	}
+	if got := ID(hash, WithStyle(StyleInitials), WithInitials("Ada Lovelace")); !regexp.MustCompile(`^initials-v1-[0-9a-f]{12}-AL$`).MatchString(got) {
+		t.Errorf("Expected the initials in the ID, got %q", got)
+	}
+}
+
+func TestIDIgnoresDrawingOptions(t *testing.T) {
+	hash := []byte("id-drawing")
+	if got, want := ID(hash, WithSize(512), WithPadding(8)), ID(hash); got != want {
+		t.Errorf("Expected the ID of the selection %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,36 @@
+package monsterid
+
+import (
+	"image"
+	"math/rand/v2"
+)
+
+// identiconCells is the number of cells across an identicon.
+const identiconCells = 5
+
+// Helper to draw a GitHub-style identicon, a 5x5 grid of cells mirrored
+// around the middle column in one color, with a margin of half a cell
+func drawIdenticon(img *image.RGBA, r *rand.Rand, o Options) error {
+	c := figureColor(r, o, 0.5)
+	bits := r.Uint32()
+
+	// Cell edges are rounded to whole pixels so cells stay crisp
+	size := img.Rect.Dx()
+	edge := func(i int) int {
+		return size * (2*i + 1) / (2*identiconCells + 2)
+	}
+
+	half := (identiconCells + 1) / 2
+	for y := 0; y < identiconCells; y++ {
+		for x := 0; x < half; x++ {
+			if bits&(1<<(y*half+x)) == 0 {
+				continue
+			}
+			for _, col := range []int{x, identiconCells - 1 - x} {
+				fillRGBA(img, image.Rect(edge(col), edge(y), edge(col+1), edge(y+1)), c)
+			}
+		}
+	}
+
+	return nil
+}
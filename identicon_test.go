@@ -0,0 +1,80 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestIdenticon(t *testing.T) {
+	img := New([]byte("alice"), WithStyle(StyleIdenticon)).(*image.RGBA)
+	bg := DefaultOptions().Background
+	b := img.Bounds()
+	if b.Dx() != nativeSize {
+		t.Fatalf("Expected the native size, got %v", b)
+	}
+
+	// One color over the background, mirrored around the middle column
+	var fg color.RGBA
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := img.RGBAAt(x, y)
+			if c != img.RGBAAt(b.Dx()-1-x, y) {
+				t.Fatalf("Expected a mirrored identicon, got %v and %v at row %d", c, img.RGBAAt(b.Dx()-1-x, y), y)
+			}
+			if c == bg {
+				continue
+			}
+			if fg == (color.RGBA{}) {
+				fg = c
+			}
+			if c != fg {
+				t.Fatalf("Expected a single color, got %v and %v", fg, c)
+			}
+		}
+	}
+	if fg == (color.RGBA{}) {
+		t.Fatal("Expected some cells to be filled")
+	}
+
+	// The margin is half a cell
+	for x := 0; x < b.Dx(); x++ {
+		for _, y := range []int{0, nativeSize/12 - 1, nativeSize - nativeSize/12} {
+			if img.RGBAAt(x, y) != bg {
+				t.Fatalf("Expected the margin to be background at %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestIdenticonColors(t *testing.T) {
+	hue := 0.0
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(c color.RGBA) bool
+	}{
+		{"greyscale", []Option{WithGreyscale()}, func(c color.RGBA) bool { return c.R == c.G && c.G == c.B }},
+		{"hue", []Option{WithHue(hue, 0)}, func(c color.RGBA) bool { return c.R > c.G && c.G == c.B }},
+		{"palette", []Option{WithPalette(color.RGBA{B: 0xff, A: 0xff})}, func(c color.RGBA) bool { return c.R == c.G && c.B > c.R }},
+	}
+
+	for _, test := range tests {
+		opts := append([]Option{WithStyle(StyleIdenticon), WithTransparentBackground()}, test.opts...)
+		img := New([]byte("alice"), opts...).(*image.RGBA)
+		found := false
+		for i := 0; i < len(img.Pix); i += 4 {
+			c := color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+			if c.A == 0 {
+				continue
+			}
+			found = true
+			if !test.check(c) {
+				t.Fatalf("Unexpected %s color %v", test.name, c)
+			}
+		}
+		if !found {
+			t.Errorf("Expected some cells for %s", test.name)
+		}
+	}
+}
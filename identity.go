@@ -0,0 +1,45 @@
+package monsterid
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"strings"
+)
+
+// HashAlgorithm selects how identifiers are hashed before generation.
+type HashAlgorithm int
+
+const (
+	MD5    HashAlgorithm = iota // Gravatar compatible
+	SHA256                      // Libravatar compatible
+)
+
+// NormalizeEmail trims surrounding whitespace and lowercases an email address.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// HashString returns the hex encoded hash of s, the same value Gravatar and
+// Libravatar use in avatar URLs.
+func HashString(s string, algo HashAlgorithm) string {
+	if algo == SHA256 {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewFromString creates a monsterid image from the hex encoded hash of s.
+func NewFromString(s string, algo HashAlgorithm, opts ...Option) image.Image {
+	return New([]byte(HashString(s, algo)), opts...)
+}
+
+// NewFromEmail creates a monsterid image from a normalized email address,
+// matching the avatar generated for its Gravatar or Libravatar hash.
+func NewFromEmail(email string, algo HashAlgorithm, opts ...Option) image.Image {
+	return NewFromString(NormalizeEmail(email), algo, opts...)
+}
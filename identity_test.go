@@ -0,0 +1,43 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestHashString(t *testing.T) {
+	tests := []struct {
+		input string
+		algo  HashAlgorithm
+		want  string
+	}{
+		{"myemailaddress@example.com", MD5, "0bc83cb571cd1c50ba6f3e8a78ef1346"},
+		{"", MD5, "d41d8cd98f00b204e9800998ecf8427e"},
+		{"", SHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+
+	for _, test := range tests {
+		if got := HashString(test.input, test.algo); got != test.want {
+			t.Errorf("HashString(%q, %d) = %s, want %s", test.input, test.algo, got, test.want)
+		}
+	}
+}
+
+func TestNewFromEmailNormalizes(t *testing.T) {
+	img1 := NewFromEmail("  MyEmailAddress@Example.com \n", MD5).(*image.RGBA)
+	img2 := New([]byte("0bc83cb571cd1c50ba6f3e8a78ef1346")).(*image.RGBA)
+
+	if !bytes.Equal(img1.Pix, img2.Pix) {
+		t.Error("NewFromEmail did not match the image for the Gravatar hash")
+	}
+}
+
+func TestNewFromStringAlgorithmsDiffer(t *testing.T) {
+	img1 := NewFromString("user", MD5).(*image.RGBA)
+	img2 := NewFromString("user", SHA256).(*image.RGBA)
+
+	if bytes.Equal(img1.Pix, img2.Pix) {
+		t.Error("MD5 and SHA256 produced identical images")
+	}
+}
@@ -0,0 +1,108 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"unicode"
+)
+
+// Layout of the initials in units of the strokeFont grid
+const (
+	initialsStroke = 0.7 // width of the strokes
+	initialsGap    = 1.6 // space between two letters
+)
+
+// initialsHeight is the height of the letters as a fraction of the size.
+const initialsHeight = 0.4
+
+// Helper to get the initials of a name, the first letter or digit of its
+// first and last words in upper case, such as AL for "Ada Lovelace" or JS for
+// "john.smith@example.com". Words starting with a character missing from
+// strokeFont are skipped.
+func initialsOf(name string) string {
+	name, _, _ = strings.Cut(name, "@")
+	var letters []rune
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if r := unicode.ToUpper([]rune(word)[0]); strokeFont[r] != nil {
+			letters = append(letters, r)
+		}
+	}
+	if len(letters) > 2 {
+		letters = []rune{letters[0], letters[len(letters)-1]}
+	}
+
+	return string(letters)
+}
+
+// Helper to draw up to two letters of Options.Initials, or two letters from
+// the hash if there are none, in white or black over a square of one color
+func drawInitials(img *image.RGBA, r *rand.Rand, o Options) error {
+	bg := figureColor(r, o, 0.45)
+	text := []rune(initialsOf(o.Initials))
+	if len(text) == 0 {
+		text = []rune{'A' + rune(r.IntN(26)), 'A' + rune(r.IntN(26))}
+	}
+
+	fillRGBA(img, img.Rect, bg)
+	fg := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	if l := relativeLuminance(bg); contrastRatio(l, 0) > contrastRatio(l, 1) {
+		fg = color.RGBA{A: 0xff}
+	}
+
+	size := float64(img.Rect.Dx())
+	unit := size * initialsHeight / fontHeight
+	width := float64(len(text)*fontWidth) + float64(len(text)-1)*initialsGap
+	x, y := (size-width*unit)/2, (size-fontHeight*unit)/2
+	for _, ch := range text {
+		drawGlyph(img, strokeFont[ch], x, y, unit, fg)
+		x += (fontWidth + initialsGap) * unit
+	}
+
+	return nil
+}
+
+// Helper to draw the strokes of a glyph in c with antialiased edges, with
+// the top-left corner of its grid at ox, oy and unit pixels per grid unit
+func drawGlyph(img *image.RGBA, strokes [][]float64, ox, oy, unit float64, c color.RGBA) {
+	half := initialsStroke * unit / 2
+	bounds := image.Rect(
+		int(math.Floor(ox-half-1)), int(math.Floor(oy-half-1)),
+		int(math.Ceil(ox+fontWidth*unit+half+1)), int(math.Ceil(oy+fontHeight*unit+half+1)),
+	).Intersect(img.Rect)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := (float64(x)+0.5-ox)/unit, (float64(y)+0.5-oy)/unit
+			d := math.Inf(1)
+			for _, pts := range strokes {
+				for i := 2; i+1 < len(pts); i += 2 {
+					d = min(d, segmentDistance(px, py, pts[i-2], pts[i-1], pts[i], pts[i+1]))
+				}
+			}
+
+			// Coverage of the pixel by the stroke, in pixels
+			a := max(0, min(1, half-d*unit+0.5))
+			if a == 0 {
+				continue
+			}
+			i := img.PixOffset(x, y)
+			for ch, v := range [4]uint8{c.R, c.G, c.B, c.A} {
+				img.Pix[i+ch] = uint8(float64(img.Pix[i+ch])*(1-a) + float64(v)*a + 0.5)
+			}
+		}
+	}
+}
+
+// Helper to get the distance from x, y to the segment from a to b
+func segmentDistance(x, y, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = max(0, min(1, ((x-ax)*dx+(y-ay)*dy)/l))
+	}
+
+	return math.Hypot(x-ax-t*dx, y-ay-t*dy)
+}
@@ -0,0 +1,119 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"net/http"
+	"testing"
+)
+
+func TestInitialsOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ada Lovelace", "AL"},
+		{"ada", "A"},
+		{"Grace Brewster Murray Hopper", "GH"},
+		{"john.smith@example.com", "JS"},
+		{"jean-luc picard", "JP"},
+		{"R2 D2", "RD"},
+		{"Émile Zola", "Z"},
+		{"  ", ""},
+		{"@example.com", ""},
+	}
+
+	for _, test := range tests {
+		if got := initialsOf(test.name); got != test.want {
+			t.Errorf("Expected %q for %q, got %q", test.want, test.name, got)
+		}
+	}
+}
+
+func TestStrokeFont(t *testing.T) {
+	for ch, strokes := range strokeFont {
+		for _, pts := range strokes {
+			if len(pts) < 4 || len(pts)%2 != 0 {
+				t.Errorf("Expected points of strokes of %c, got %v", ch, pts)
+			}
+			for i := 0; i+1 < len(pts); i += 2 {
+				if pts[i] < 0 || pts[i] > fontWidth || pts[i+1] < 0 || pts[i+1] > fontHeight {
+					t.Errorf("Expected the strokes of %c on the grid, got %v", ch, pts)
+				}
+			}
+		}
+	}
+}
+
+func TestInitials(t *testing.T) {
+	img := New([]byte("alice"), WithStyle(StyleInitials), WithInitials("Ada Lovelace")).(*image.RGBA)
+	bg := img.RGBAAt(0, 0)
+	if bg.A != 0xff {
+		t.Fatalf("Expected an opaque square, got %v", bg)
+	}
+
+	// Two letters around the middle in one color, the corners are the background
+	letters := 0
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			if img.RGBAAt(x, y) == bg {
+				continue
+			}
+			letters++
+			if y < nativeSize/4 || y >= nativeSize*3/4 {
+				t.Fatalf("Expected the letters in the middle, got %v at %d,%d", img.RGBAAt(x, y), x, y)
+			}
+		}
+	}
+	if letters == 0 {
+		t.Fatal("Expected letters")
+	}
+
+	same := New([]byte("alice"), WithStyle(StyleInitials), WithInitials("Alan Lee")).(*image.RGBA)
+	if !bytes.Equal(img.Pix, same.Pix) {
+		t.Error("Expected names with the same initials to look the same")
+	}
+	if bytes.Equal(img.Pix, New([]byte("alice"), WithStyle(StyleInitials), WithInitials("Bob")).(*image.RGBA).Pix) {
+		t.Error("Expected other initials to differ")
+	}
+	if bytes.Equal(img.Pix, New([]byte("bob"), WithStyle(StyleInitials), WithInitials("Ada Lovelace")).(*image.RGBA).Pix) {
+		t.Error("Expected the color to differ by hash")
+	}
+	if bytes.Equal(New([]byte("alice"), WithStyle(StyleInitials)).(*image.RGBA).Pix, New([]byte("bob"), WithStyle(StyleInitials)).(*image.RGBA).Pix) {
+		t.Error("Expected letters from the hash without initials")
+	}
+}
+
+func TestInitialsContrast(t *testing.T) {
+	tests := []struct {
+		shift float64
+		want  color.RGBA
+	}{
+		{-0.3, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{0.4, color.RGBA{A: 0xff}},
+	}
+
+	for _, test := range tests {
+		img := New([]byte("alice"), WithStyle(StyleInitials), WithInitials("W"), WithLightnessShift(test.shift)).(*image.RGBA)
+		if c := img.RGBAAt(nativeSize/2, nativeSize/2); c != test.want {
+			t.Errorf("Expected %v letters with a lightness shift of %v, got %v", test.want, test.shift, c)
+		}
+	}
+}
+
+func TestHandlerInitials(t *testing.T) {
+	h := Handler(HandlerConfig{Params: []string{ParamStyle, ParamName}})
+	ada := serve(h, http.MethodGet, "/alice?style=initials&name=Ada+Lovelace")
+	if ada.Code != http.StatusOK {
+		t.Fatalf("Expected the initials to be served, got %d: %s", ada.Code, ada.Body)
+	}
+	alan := serve(h, http.MethodGet, "/alice?style=initials&name=alan.lee%40example.com")
+	if ada.Header().Get("ETag") != alan.Header().Get("ETag") {
+		t.Error("Expected names with the same initials to share the ETag")
+	}
+	bob := serve(h, http.MethodGet, "/alice?style=initials&name=Bob")
+	if ada.Header().Get("ETag") == bob.Header().Get("ETag") {
+		t.Error("Expected other initials to have their own ETag")
+	}
+}
@@ -0,0 +1,92 @@
+package monsterid
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// description is the document written by DescribeJSON. Fields are only ever
+// added so clients can rely on the layout.
+type description struct {
+	Version  Version            `json:"version"`
+	Size     int                `json:"size"`
+	Parts    descriptionParts   `json:"parts"`
+	Colors   *descriptionColors `json:"colors,omitempty"`
+	Mirrored bool               `json:"mirrored"`
+
+	Accessory Accessory `json:"accessory,omitempty"`
+	Theme     Theme     `json:"theme"`
+}
+
+// descriptionParts lists the selected parts, 1-based.
+type descriptionParts struct {
+	Legs  int `json:"legs"`
+	Hair  int `json:"hair"`
+	Arms  int `json:"arms"`
+	Body  int `json:"body"`
+	Eyes  int `json:"eyes"`
+	Mouth int `json:"mouth"`
+}
+
+// descriptionColors lists the colors of the recolored parts, only present
+// with Options.Artistic.
+type descriptionColors struct {
+	Body *descriptionColor `json:"body"`
+	Legs *descriptionColor `json:"legs,omitempty"`
+	Arms *descriptionColor `json:"arms,omitempty"`
+}
+
+// descriptionColor is a hue and saturation, both 0.0-1.0.
+type descriptionColor struct {
+	Hue        float64 `json:"hue"`
+	Saturation float64 `json:"saturation"`
+}
+
+// DescribeJSON returns the parts, colors, size and algorithm version selected
+// for the provided hash as a JSON document. Other styles than StyleMonster
+// have no parts to describe, so they return an error.
+func DescribeJSON(hash []byte, opts ...Option) ([]byte, error) {
+	o := buildOptions(opts)
+	if o.style() != StyleMonster {
+		return nil, fmt.Errorf("monsterid: style %s has no parts to describe", o.style())
+	}
+	if err := o.checkVersion(); err != nil {
+		return nil, err
+	}
+	d := describeHash(hash, o)
+
+	doc := description{
+		Version: o.version(),
+		Size:    o.size(),
+		Parts: descriptionParts{
+			Legs:  d.Legs,
+			Hair:  d.Hair,
+			Arms:  d.Arms,
+			Body:  d.Body,
+			Eyes:  d.Eyes,
+			Mouth: d.Mouth,
+		},
+		Mirrored:  d.Mirrored,
+		Accessory: d.Accessory,
+		Theme:     o.theme(),
+	}
+
+	if o.Artistic {
+		doc.Colors = &descriptionColors{
+			Body: &descriptionColor{Hue: d.Hue, Saturation: d.Saturation},
+			Legs: limbColor(d.LegsHue, d.Saturation),
+			Arms: limbColor(d.ArmsHue, d.Saturation),
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// Helper to get the color of a limb, nil if it keeps its original color
+func limbColor(hue, saturation float64) *descriptionColor {
+	if hue < 0 {
+		return nil
+	}
+
+	return &descriptionColor{Hue: hue, Saturation: saturation}
+}
@@ -0,0 +1,66 @@
+package monsterid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDescribeJSON(t *testing.T) {
+	hash := []byte("describe-json")
+
+	data, err := DescribeJSON(hash, WithSize(64), WithAlgorithmVersion(V2), WithArtistic(false))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	d := Describe(hash, WithAlgorithmVersion(V2))
+	parts := doc["parts"].(map[string]any)
+	if parts["body"] != float64(d.Body) || parts["mouth"] != float64(d.Mouth) {
+		t.Errorf("Expected body %d and mouth %d, got %v", d.Body, d.Mouth, parts)
+	}
+	if doc["size"] != 64.0 || doc["version"] != 2.0 || doc["mirrored"] != d.Mirrored {
+		t.Errorf("Unexpected size, version or mirroring in %s", data)
+	}
+	if _, ok := doc["colors"]; ok {
+		t.Errorf("Expected no colors without artistic mode, got %s", data)
+	}
+}
+
+func TestDescribeJSONArtistic(t *testing.T) {
+	hash := []byte("describe-json-artistic")
+
+	data, err := DescribeJSON(hash, WithArtistic(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc description
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	d := Describe(hash, WithArtistic(true))
+	if doc.Colors == nil || doc.Colors.Body.Hue != d.Hue || doc.Colors.Body.Saturation != d.Saturation {
+		t.Fatalf("Expected body color %v/%v, got %s", d.Hue, d.Saturation, data)
+	}
+	if (doc.Colors.Legs != nil) != (d.LegsHue >= 0) || (doc.Colors.Arms != nil) != (d.ArmsHue >= 0) {
+		t.Errorf("Limb colors don't match the descriptor: %s", data)
+	}
+
+	again, _ := DescribeJSON(hash, WithArtistic(true))
+	if string(again) != string(data) {
+		t.Error("DescribeJSON is not deterministic")
+	}
+}
+
+func TestDescribeJSONStyle(t *testing.T) {
+	if _, err := DescribeJSON([]byte("describe-json"), WithStyle(StyleIdenticon)); err == nil || !strings.Contains(err.Error(), "identicon") {
+		t.Errorf("Expected an error for a style without parts, got %v", err)
+	}
+}
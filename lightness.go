@@ -0,0 +1,62 @@
+package monsterid
+
+import (
+	"math"
+	"sync"
+)
+
+// maxLightnessLevels bounds the number of distinct HSL lightness values of
+// 8-bit colors, which is a little over 800.
+const maxLightnessLevels = 1024
+
+// lightnessTable lists the HSL lightness of every 8-bit color as computed by
+// rgbToHsl, which only depends on its brightest and darkest channel.
+type lightnessTable struct {
+	index  [256][256]uint16 // level of the lightness by brightest and darkest channel
+	levels []float64        // distinct lightness values
+}
+
+// lightnesses is built on first use and shared by all renders.
+var lightnesses = sync.OnceValue(func() *lightnessTable {
+	t := &lightnessTable{}
+	levels := make(map[float64]uint16)
+	for hi := 0; hi < 256; hi++ {
+		for lo := 0; lo <= hi; lo++ {
+			// Channels on the 16-bit scale of color.Color, as colorizeImage
+			c := float64(uint32(hi)*0x101) / 0xFFFF
+			_, _, l := rgbToHsl(c, float64(uint32(lo)*0x101)/0xFFFF, c)
+			k, ok := levels[l]
+			if !ok {
+				k = uint16(len(t.levels))
+				levels[l] = k
+				t.levels = append(t.levels, l)
+			}
+			t.index[hi][lo] = k
+		}
+	}
+
+	return t
+})
+
+// lightnessLUT is the colorized RGB color of each lightness level for a hue,
+// saturation and lightness shift, computed on first use of a level.
+type lightnessLUT struct {
+	table                  *lightnessTable
+	hue, saturation, shift float64
+	colors                 [maxLightnessLevels][3]uint8
+	done                   [maxLightnessLevels]bool
+}
+
+// Helper to get the colorized color of a pixel by its brightest and darkest
+// channel
+func (lut *lightnessLUT) color(hi, lo uint8) [3]uint8 {
+	k := lut.table.index[hi][lo]
+	if !lut.done[k] {
+		l := math.Max(0, math.Min(1, lut.table.levels[k]+lut.shift))
+		r, g, b := hslToRgb(lut.hue, lut.saturation, l)
+		lut.colors[k] = [3]uint8{uint8(r * 255), uint8(g * 255), uint8(b * 255)}
+		lut.done[k] = true
+	}
+
+	return lut.colors[k]
+}
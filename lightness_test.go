@@ -0,0 +1,52 @@
+package monsterid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLightnessLUTMatchesHSL(t *testing.T) {
+	if n := len(lightnesses().levels); n > maxLightnessLevels {
+		t.Fatalf("Expected at most %d lightness levels, got %d", maxLightnessLevels, n)
+	}
+
+	tests := []struct {
+		hue, saturation, shift float64
+	}{
+		{0, 1, 0},
+		{0.37, 0.73, 0.05},
+		{0.9, 0.5, -0.2},
+	}
+
+	for _, test := range tests {
+		lut := lightnessLUT{table: lightnesses(), hue: test.hue, saturation: test.saturation, shift: test.shift}
+		for hi := 0; hi < 256; hi++ {
+			for lo := 0; lo <= hi; lo++ {
+				// The middle channel doesn't change the lightness
+				mid := (hi + lo) / 2
+				_, _, l := rgbToHsl(float64(uint32(hi)*0x101)/0xFFFF, float64(uint32(mid)*0x101)/0xFFFF, float64(uint32(lo)*0x101)/0xFFFF)
+				r, g, b := hslToRgb(test.hue, test.saturation, math.Max(0, math.Min(1, l+test.shift)))
+				want := [3]uint8{uint8(r * 255), uint8(g * 255), uint8(b * 255)}
+
+				if got := lut.color(uint8(hi), uint8(lo)); got != want {
+					t.Fatalf("Expected %v for %d,%d,%d with %+v, got %v", want, hi, mid, lo, test, got)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkColorizeImageLarge(b *testing.B) {
+	part, err := loadPart("body_1.png", 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	large := scaleImage(part, 4*nativeSize, bilinear)
+	img := cloneImage(large)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(img.Pix, large.Pix)
+		colorizeImage(img, 0.3, 0.8, 0, true)
+	}
+}
@@ -0,0 +1,222 @@
+package monsterid
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// descriptorFormat is the first byte of the binary encoding of a Descriptor,
+// bumped whenever the layout changes. Format 2 adds the accessory, format 3
+// the theme.
+const descriptorFormat = 3
+
+// descriptorSize is the length of the fixed part of the binary encoding: the
+// format, six parts, four colors, mirroring and three jitters. Format 1 has
+// nothing else, format 2 ends with the length and name of the accessory and
+// format 3 with the length and name of the theme after that.
+const descriptorSize = 1 + 6 + 4*8 + 1 + 3*(2+8)
+
+// MarshalBinary implements encoding.BinaryMarshaler, the encoding is stable
+// so it can be stored and rendered again with FromParts.
+func (d Descriptor) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, descriptorSize+1+len(d.Accessory)+1+len(d.Theme))
+	b = append(b, descriptorFormat)
+	for _, part := range bodyParts {
+		b = append(b, uint8(getPartNumber(&d, part)))
+	}
+	for _, f := range []float64{d.Hue, d.Saturation, d.LegsHue, d.ArmsHue} {
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+	}
+
+	mirrored := uint8(0)
+	if d.Mirrored {
+		mirrored = 1
+	}
+	b = append(b, mirrored)
+
+	for _, j := range []Jitter{d.LegsJitter, d.HairJitter, d.ArmsJitter} {
+		b = append(b, uint8(int8(j.DX)), uint8(int8(j.DY)))
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(j.Angle))
+	}
+
+	b = append(b, uint8(len(d.Accessory)))
+	b = append(b, d.Accessory...)
+	b = append(b, uint8(len(d.Theme)))
+	b = append(b, d.Theme...)
+
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for the encoding
+// written by MarshalBinary.
+func (d *Descriptor) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] < 1 || data[0] > descriptorFormat {
+		return errors.New("monsterid: unknown descriptor format")
+	}
+
+	size := descriptorSize
+	if data[0] >= 2 {
+		if len(data) <= descriptorSize {
+			return fmt.Errorf("monsterid: descriptor is %d bytes, want more than %d", len(data), descriptorSize)
+		}
+		size += 1 + int(data[descriptorSize])
+	}
+	if data[0] >= 3 {
+		if len(data) <= size {
+			return fmt.Errorf("monsterid: descriptor is %d bytes, want more than %d", len(data), size)
+		}
+		size += 1 + int(data[size])
+	}
+	if len(data) != size {
+		return fmt.Errorf("monsterid: descriptor is %d bytes, want %d", len(data), size)
+	}
+
+	var n Descriptor
+	parts := []*int{&n.Legs, &n.Hair, &n.Arms, &n.Body, &n.Eyes, &n.Mouth}
+	for i, p := range parts {
+		*p = int(data[1+i])
+	}
+
+	off := 1 + len(parts)
+	for _, f := range []*float64{&n.Hue, &n.Saturation, &n.LegsHue, &n.ArmsHue} {
+		*f = math.Float64frombits(binary.BigEndian.Uint64(data[off:]))
+		off += 8
+	}
+
+	n.Mirrored = data[off] == 1
+	off++
+
+	for _, j := range []*Jitter{&n.LegsJitter, &n.HairJitter, &n.ArmsJitter} {
+		j.DX, j.DY = int(int8(data[off])), int(int8(data[off+1]))
+		j.Angle = math.Float64frombits(binary.BigEndian.Uint64(data[off+2:]))
+		off += 10
+	}
+
+	if data[0] >= 2 {
+		n.Accessory = Accessory(data[off+1 : off+1+int(data[off])])
+		off += 1 + len(n.Accessory)
+	}
+	if data[0] >= 3 {
+		n.Theme = Theme(data[off+1:])
+	}
+
+	if err := n.validateTheme(); err != nil {
+		return err
+	}
+
+	*d = n
+	return nil
+}
+
+// descriptorJSON is the JSON encoding of a Descriptor.
+type descriptorJSON struct {
+	Legs       int     `json:"legs"`
+	Hair       int     `json:"hair"`
+	Arms       int     `json:"arms"`
+	Body       int     `json:"body"`
+	Eyes       int     `json:"eyes"`
+	Mouth      int     `json:"mouth"`
+	Hue        float64 `json:"hue"`
+	Saturation float64 `json:"saturation"`
+	LegsHue    float64 `json:"legsHue"`
+	ArmsHue    float64 `json:"armsHue"`
+	Mirrored   bool    `json:"mirrored,omitempty"`
+	LegsJitter *Jitter `json:"legsJitter,omitempty"`
+	HairJitter *Jitter `json:"hairJitter,omitempty"`
+	ArmsJitter *Jitter `json:"armsJitter,omitempty"`
+
+	Accessory Accessory `json:"accessory,omitempty"`
+	Theme     Theme     `json:"theme,omitempty"`
+}
+
+// jitterJSON is the JSON encoding of a Jitter.
+type jitterJSON struct {
+	DX    int     `json:"dx"`
+	DY    int     `json:"dy"`
+	Angle float64 `json:"angle"`
+}
+
+// MarshalJSON implements json.Marshaler, leaving out jitter and mirroring
+// when they are not used.
+func (d Descriptor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(descriptorJSON{
+		Legs:       d.Legs,
+		Hair:       d.Hair,
+		Arms:       d.Arms,
+		Body:       d.Body,
+		Eyes:       d.Eyes,
+		Mouth:      d.Mouth,
+		Hue:        d.Hue,
+		Saturation: d.Saturation,
+		LegsHue:    d.LegsHue,
+		ArmsHue:    d.ArmsHue,
+		Mirrored:   d.Mirrored,
+		LegsJitter: jitterOrNil(d.LegsJitter),
+		HairJitter: jitterOrNil(d.HairJitter),
+		ArmsJitter: jitterOrNil(d.ArmsJitter),
+		Accessory:  d.Accessory,
+		Theme:      d.Theme,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, limbs without a hue keep their
+// original color.
+func (d *Descriptor) UnmarshalJSON(data []byte) error {
+	v := descriptorJSON{LegsHue: -1, ArmsHue: -1}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	n := Descriptor{
+		Legs:       v.Legs,
+		Hair:       v.Hair,
+		Arms:       v.Arms,
+		Body:       v.Body,
+		Eyes:       v.Eyes,
+		Mouth:      v.Mouth,
+		Hue:        v.Hue,
+		Saturation: v.Saturation,
+		LegsHue:    v.LegsHue,
+		ArmsHue:    v.ArmsHue,
+		Mirrored:   v.Mirrored,
+		Accessory:  v.Accessory,
+		Theme:      v.Theme,
+	}
+	for _, j := range []struct {
+		dst *Jitter
+		src *Jitter
+	}{{&n.LegsJitter, v.LegsJitter}, {&n.HairJitter, v.HairJitter}, {&n.ArmsJitter, v.ArmsJitter}} {
+		if j.src != nil {
+			*j.dst = *j.src
+		}
+	}
+
+	if err := n.validateTheme(); err != nil {
+		return err
+	}
+
+	*d = n
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j Jitter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jitterJSON(j))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *Jitter) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*jitterJSON)(j))
+}
+
+// Helper to get a pointer to j, nil if there is no jitter
+func jitterOrNil(j Jitter) *Jitter {
+	if j == (Jitter{}) {
+		return nil
+	}
+
+	return &j
+}
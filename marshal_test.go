@@ -0,0 +1,141 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestDescriptorBinaryRoundTrip(t *testing.T) {
+	for _, want := range []Descriptor{
+		Describe([]byte("marshal-binary"), WithAlgorithmVersion(V2), WithJitter()),
+		Describe([]byte("marshal-binary"), WithAccessory(AccessoryModerator)),
+	} {
+		data, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if size := descriptorSize + 1 + len(want.Accessory) + 1 + len(want.Theme); len(data) != size {
+			t.Errorf("Expected %d bytes, got %d", size, len(data))
+		}
+
+		var got Descriptor
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestDescriptorUnmarshalBinaryFormat1(t *testing.T) {
+	want := Describe([]byte("marshal-format-1"))
+	data, _ := want.MarshalBinary()
+
+	// Format 1 has no accessory
+	data = data[:descriptorSize]
+	data[0] = 1
+
+	var got Descriptor
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDescriptorUnmarshalBinaryFormat2(t *testing.T) {
+	want := Describe([]byte("marshal-format-2"), WithAccessory(AccessoryModerator))
+	data, _ := want.MarshalBinary()
+
+	// Format 2 has no theme
+	data = data[:len(data)-1]
+	data[0] = 2
+
+	var got Descriptor
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDescriptorUnmarshalBinaryInvalid(t *testing.T) {
+	data, _ := Describe([]byte("marshal-invalid")).MarshalBinary()
+
+	tests := []struct {
+		description string
+		data        []byte
+	}{
+		{"empty", nil},
+		{"unknown format", append([]byte{99}, data[1:]...)},
+		{"truncated", data[:len(data)-1]},
+		{"part out of range", append(append([]byte{}, data[:4]...), append([]byte{200}, data[5:]...)...)},
+		{"missing accessory length", data[:descriptorSize]},
+		{"unknown accessory", append(append([]byte{}, data[:descriptorSize]...), 3, 'h', 'a', 't')},
+	}
+
+	for _, test := range tests {
+		var d Descriptor
+		if err := d.UnmarshalBinary(test.data); err == nil {
+			t.Errorf("Expected an error for %s", test.description)
+		}
+	}
+}
+
+func TestDescriptorJSONRoundTrip(t *testing.T) {
+	for _, want := range []Descriptor{
+		Describe([]byte("marshal-json")),
+		Describe([]byte("marshal-json"), WithAlgorithmVersion(V2), WithJitter()),
+	} {
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var got Descriptor
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %+v, got %+v from %s", want, got, data)
+		}
+	}
+}
+
+func TestDescriptorUnmarshalJSON(t *testing.T) {
+	var d Descriptor
+	if err := json.Unmarshal([]byte(`{"legs":1,"hair":2,"arms":3,"body":4,"eyes":5,"mouth":6,"hue":0.5,"saturation":0.7}`), &d); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d.LegsHue != -1 || d.ArmsHue != -1 {
+		t.Errorf("Expected missing limb hues to keep the original color, got %v and %v", d.LegsHue, d.ArmsHue)
+	}
+
+	if err := json.Unmarshal([]byte(`{"legs":1,"hair":2,"arms":3,"body":0,"eyes":5,"mouth":6}`), &d); err == nil {
+		t.Error("Expected an error for a missing body")
+	}
+}
+
+func TestDescriptorRenderAfterUnmarshal(t *testing.T) {
+	hash := []byte("marshal-render")
+	data, _ := Describe(hash).MarshalBinary()
+
+	var d Descriptor
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := FromParts(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(img.(*image.RGBA).Pix, New(hash).(*image.RGBA).Pix) {
+		t.Error("Rendering the stored descriptor differs from New")
+	}
+}
@@ -0,0 +1,77 @@
+package monsterid
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"math"
+	"strings"
+)
+
+// A part can come with a recoloring mask named <category>_<n>_mask.png, such
+// as body_1_mask.png, of the same size. White areas of the mask are
+// colorized, black and transparent ones keep the colors of the artwork and
+// grey ones are blended, instead of skipping near-white pixels. This gives
+// clean tinting of artwork with highlights or details in several colors.
+
+// maskNames are the mask file names of the parts in partFileNames.
+var maskNames = func() map[string]string {
+	names := make(map[string]string)
+	for _, fileNames := range partFileNames {
+		for _, fileName := range fileNames {
+			names[fileName] = strings.TrimSuffix(fileName, ".png") + "_mask.png"
+		}
+	}
+	return names
+}()
+
+// Helper to get the file name of the mask of a part
+func maskName(fileName string) string {
+	if name, ok := maskNames[fileName]; ok {
+		return name
+	}
+
+	return strings.TrimSuffix(fileName, ".png") + "_mask.png"
+}
+
+// Helper to load the mask of a part at the size of the part, nil if there is
+// none
+func loadMask(p pack, fileName string, scale, size int) (*image.RGBA, error) {
+	mask, err := p.load(maskName(fileName), scale)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Masks without a variant at the scale of the part are scaled up
+	if mask.Bounds().Dx() != size {
+		mask = scaleImage(mask, size, bilinear)
+	}
+
+	return mask, nil
+}
+
+// Helper to colorize the masked areas of an image with HSL values, keeping
+// the lightness of each pixel
+func colorizeMasked(img, mask *image.RGBA, hue, saturation, lightnessShift float64) {
+	for i := 0; i+3 < len(img.Pix) && i+3 < len(mask.Pix); i += 4 {
+		a := img.Pix[i+3]
+		// Premultiplied, so transparent mask pixels count as black
+		m := (float64(mask.Pix[i]) + float64(mask.Pix[i+1]) + float64(mask.Pix[i+2])) / (3 * 255)
+		if a == 0 || m == 0 {
+			continue
+		}
+
+		// Work on straight colors of the premultiplied pixel
+		fa := float64(a) / 255
+		r, g, b := float64(img.Pix[i])/255/fa, float64(img.Pix[i+1])/255/fa, float64(img.Pix[i+2])/255/fa
+		_, _, l := rgbToHsl(r, g, b)
+		r2, g2, b2 := hslToRgb(hue, saturation, math.Max(0, math.Min(1, l+lightnessShift)))
+
+		img.Pix[i] = clampUint8((r + (r2-r)*m) * fa * 255)
+		img.Pix[i+1] = clampUint8((g + (g2-g)*m) * fa * 255)
+		img.Pix[i+2] = clampUint8((b + (b2-b)*m) * fa * 255)
+	}
+}
@@ -0,0 +1,75 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+	"testing/fstest"
+)
+
+func TestColorizeMasked(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 200, G: 200, B: 200, A: 255}), image.Point{}, draw.Src)
+
+	mask := image.NewRGBA(img.Bounds())
+	mask.SetRGBA(0, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	mask.SetRGBA(1, 0, color.RGBA{R: 128, G: 128, B: 128, A: 255})
+
+	colorizeMasked(img, mask, 0, 1, 0)
+
+	// Fully masked pixels are red with the same lightness, even if light
+	if c := img.RGBAAt(0, 0); c.R <= c.G || c.G != c.B {
+		t.Errorf("Expected a light red, got %v", c)
+	}
+	// Grey mask pixels are blended
+	if c, full := img.RGBAAt(1, 0), img.RGBAAt(0, 0); c.R <= c.G || c.G <= full.G {
+		t.Errorf("Expected a blend between grey and %v, got %v", full, c)
+	}
+	// Unmasked pixels keep their color
+	if c := img.RGBAAt(2, 0); c != (color.RGBA{R: 200, G: 200, B: 200, A: 255}) {
+		t.Errorf("Expected the original color, got %v", c)
+	}
+}
+
+func TestGeneratorMask(t *testing.T) {
+	// A solid light body, masked on its left half only
+	body := image.NewRGBA(image.Rect(0, 0, nativeSize, nativeSize))
+	draw.Draw(body, image.Rect(0, 0, nativeSize, nativeSize/2), image.NewUniform(color.RGBA{R: 230, G: 230, B: 230, A: 255}), image.Point{}, draw.Src)
+	mask := image.NewRGBA(body.Bounds())
+	draw.Draw(mask, image.Rect(0, 0, nativeSize/2, nativeSize), image.White, image.Point{}, draw.Src)
+
+	encode := func(img image.Image) []byte {
+		buf := new(bytes.Buffer)
+		png.Encode(buf, img)
+		return buf.Bytes()
+	}
+	fsys := testPack(t, 1)
+	fsys["body_1.png"] = &fstest.MapFile{Data: encode(body)}
+	fsys["body_1_mask.png"] = &fstest.MapFile{Data: encode(mask)}
+
+	g, err := NewGeneratorFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := ValidatePack(fsys); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	d := Descriptor{Legs: 1, Hair: 1, Arms: 1, Body: 1, Eyes: 1, Mouth: 1, Hue: 0.6, Saturation: 1, LegsHue: -1, ArmsHue: -1}
+	img, err := g.FromParts(d, WithTransparentBackground())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Sample the top row, above the other parts
+	rgba := img.(*image.RGBA)
+	if c := rgba.RGBAAt(5, 0); c.B <= c.R {
+		t.Errorf("Expected the masked half to be tinted blue, got %v", c)
+	}
+	if c := rgba.RGBAAt(nativeSize-5, 0); c != (color.RGBA{R: 230, G: 230, B: 230, A: 255}) {
+		t.Errorf("Expected the unmasked half to keep its color, got %v", c)
+	}
+}
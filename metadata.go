@@ -0,0 +1,241 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Keywords of the PNG tEXt chunks written with Options.Metadata.
+const (
+	metaVersion    = "monsterid:version"   // algorithm version, such as 2
+	metaParts      = "monsterid:parts"     // legs, hair, arms, body, eyes and mouth
+	metaHue        = "monsterid:hue"       // body hue and saturation
+	metaLimbsHue   = "monsterid:limbs-hue" // legs and arms hue, -1 if not recolored
+	metaMirrored   = "monsterid:mirrored"  // true if flipped horizontally
+	metaJitter     = "monsterid:jitter"    // legs, hair and arms jitter, only with Options.Jitter
+	metaAccessory  = "monsterid:accessory" // name of the accessory, only if there is one
+	metaTheme      = "monsterid:theme"     // theme of the parts, only other than ThemeClassic
+	metaSoftware   = "Software"            // registered keyword naming the encoder
+	softwareString = "monsterid"
+)
+
+// maxPNGText is the largest tEXt chunk ParsePNG reads, far more than the
+// monsterid keywords need, so a forged length can't exhaust memory.
+const maxPNGText = 64 << 10
+
+// pngText is a keyword and text pair of a PNG tEXt chunk.
+type pngText struct {
+	Keyword, Text string
+}
+
+// Helper to describe d as PNG tEXt chunks
+func pngMetadata(d Descriptor, o Options) []pngText {
+	texts := []pngText{
+		{metaSoftware, softwareString},
+		{metaVersion, strconv.Itoa(int(o.version()))},
+		{metaParts, fmt.Sprintf("%d,%d,%d,%d,%d,%d", d.Legs, d.Hair, d.Arms, d.Body, d.Eyes, d.Mouth)},
+		{metaHue, formatFloat(d.Hue) + "," + formatFloat(d.Saturation)},
+		{metaLimbsHue, formatFloat(d.LegsHue) + "," + formatFloat(d.ArmsHue)},
+		{metaMirrored, strconv.FormatBool(d.Mirrored)},
+	}
+
+	if o.Jitter {
+		var jitter []string
+		for _, j := range []Jitter{d.LegsJitter, d.HairJitter, d.ArmsJitter} {
+			jitter = append(jitter, fmt.Sprintf("%d,%d,%s", j.DX, j.DY, formatFloat(j.Angle)))
+		}
+		texts = append(texts, pngText{metaJitter, strings.Join(jitter, ";")})
+	}
+	if d.Accessory != AccessoryNone {
+		texts = append(texts, pngText{metaAccessory, string(d.Accessory)})
+	}
+	if d.Theme != "" {
+		texts = append(texts, pngText{metaTheme, string(d.Theme)})
+	}
+
+	return texts
+}
+
+// Helper to format a float with the fewest digits that round-trip
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// pngTextWriter inserts tEXt chunks into a PNG written through it right
+// after the IHDR chunk, so metadata needs no copy of the encoded image.
+type pngTextWriter struct {
+	w     io.Writer
+	texts []pngText
+	head  [pngIHDREnd]byte // signature and IHDR chunk until they are complete
+	n     int              // bytes of head written so far
+}
+
+// pngIHDREnd is the offset after the IHDR chunk, since the signature is 8
+// bytes and IHDR always has 13 bytes of data.
+const pngIHDREnd = 8 + 4 + 4 + 13 + 4
+
+func (pw *pngTextWriter) Write(p []byte) (int, error) {
+	if pw.n == len(pw.head) {
+		return pw.w.Write(p)
+	}
+
+	n := copy(pw.head[pw.n:], p)
+	pw.n += n
+	if pw.n < len(pw.head) {
+		return n, nil
+	}
+
+	if _, err := pw.w.Write(pngTextChunks(pw.head[:], pw.texts)); err != nil {
+		return 0, err
+	}
+	m, err := pw.w.Write(p[n:])
+
+	return n + m, err
+}
+
+// Helper to append tEXt chunks to data
+func pngTextChunks(data []byte, texts []pngText) []byte {
+	out := make([]byte, 0, len(data)+len(texts)*64)
+	out = append(out, data...)
+	for _, t := range texts {
+		chunk := append([]byte("tEXt"), t.Keyword...)
+		chunk = append(chunk, 0)
+		chunk = append(chunk, t.Text...)
+
+		out = binary.BigEndian.AppendUint32(out, uint32(len(chunk)-4))
+		out = append(out, chunk...)
+		out = binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(chunk))
+	}
+
+	return out
+}
+
+// ParsePNG reads the descriptor back from a PNG written with Options.Metadata,
+// so it can be rendered again with FromParts. Jitter is only restored if the
+// PNG was written with Options.Jitter.
+func ParsePNG(r io.Reader) (Descriptor, error) {
+	texts, err := readPNGText(r)
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("monsterid: read png: %w", err)
+	}
+	if texts[metaParts] == "" {
+		return Descriptor{}, errors.New("monsterid: png has no monsterid metadata")
+	}
+
+	d := Descriptor{LegsHue: -1, ArmsHue: -1}
+	parts := []*int{&d.Legs, &d.Hair, &d.Arms, &d.Body, &d.Eyes, &d.Mouth}
+	if err := parseList(texts[metaParts], ",", len(parts), func(i int, s string) (err error) {
+		*parts[i], err = strconv.Atoi(s)
+		return err
+	}); err != nil {
+		return Descriptor{}, fmt.Errorf("monsterid: parse %s: %w", metaParts, err)
+	}
+
+	for _, f := range []struct {
+		keyword string
+		values  []*float64
+	}{
+		{metaHue, []*float64{&d.Hue, &d.Saturation}},
+		{metaLimbsHue, []*float64{&d.LegsHue, &d.ArmsHue}},
+	} {
+		if err := parseList(texts[f.keyword], ",", len(f.values), func(i int, s string) (err error) {
+			*f.values[i], err = strconv.ParseFloat(s, 64)
+			return err
+		}); err != nil {
+			return Descriptor{}, fmt.Errorf("monsterid: parse %s: %w", f.keyword, err)
+		}
+	}
+
+	if s, ok := texts[metaMirrored]; ok {
+		if d.Mirrored, err = strconv.ParseBool(s); err != nil {
+			return Descriptor{}, fmt.Errorf("monsterid: parse %s: %w", metaMirrored, err)
+		}
+	}
+
+	if s, ok := texts[metaJitter]; ok {
+		jitter := []*Jitter{&d.LegsJitter, &d.HairJitter, &d.ArmsJitter}
+		if err := parseList(s, ";", len(jitter), func(i int, s string) (err error) {
+			j := jitter[i]
+			_, err = fmt.Sscanf(s, "%d,%d,%g", &j.DX, &j.DY, &j.Angle)
+			return err
+		}); err != nil {
+			return Descriptor{}, fmt.Errorf("monsterid: parse %s: %w", metaJitter, err)
+		}
+	}
+
+	d.Accessory = Accessory(texts[metaAccessory])
+	d.Theme = Theme(texts[metaTheme])
+
+	if err := d.validateTheme(); err != nil {
+		return Descriptor{}, err
+	}
+
+	return d, nil
+}
+
+// Helper to split s into exactly n fields and parse each of them
+func parseList(s, sep string, n int, parse func(i int, s string) error) error {
+	fields := strings.Split(s, sep)
+	if len(fields) != n {
+		return fmt.Errorf("got %d values, want %d", len(fields), n)
+	}
+
+	for i, f := range fields {
+		if err := parse(i, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Helper to collect the tEXt chunks of a PNG stream
+func readPNGText(r io.Reader) (map[string]string, error) {
+	var sig [8]byte
+	if _, err := io.ReadFull(r, sig[:]); err != nil {
+		return nil, err
+	}
+	if string(sig[:]) != "\x89PNG\r\n\x1a\n" {
+		return nil, errors.New("not a png file")
+	}
+
+	texts := make(map[string]string)
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, err
+		}
+		length, typ := binary.BigEndian.Uint32(header[:4]), string(header[4:])
+
+		if typ != "tEXt" {
+			if typ == "IEND" {
+				return texts, nil
+			}
+			if _, err := io.CopyN(io.Discard, r, int64(length)+4); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if length > maxPNGText {
+			return nil, fmt.Errorf("tEXt chunk of %d bytes is too large", length)
+		}
+		chunk := make([]byte, int64(length)+4)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, err
+		}
+		data, crc := chunk[:length], binary.BigEndian.Uint32(chunk[length:])
+		if crc32.Update(crc32.ChecksumIEEE(header[4:]), crc32.IEEETable, data) != crc {
+			return nil, errors.New("tEXt chunk checksum mismatch")
+		}
+
+		keyword, text, _ := bytes.Cut(data, []byte{0})
+		texts[string(keyword)] = string(text)
+	}
+}
@@ -0,0 +1,102 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestEncodePNGMetadata(t *testing.T) {
+	hash := []byte("png-metadata")
+
+	data, err := PNG(hash, WithMetadata(), WithAlgorithmVersion(V2), WithArtistic(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Failed to decode PNG with metadata: %v", err)
+	}
+
+	d := Describe(hash, WithAlgorithmVersion(V2), WithArtistic(true))
+	texts, err := readPNGText(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read tEXt chunks: %v", err)
+	}
+	for _, want := range pngMetadata(d, buildOptions([]Option{WithAlgorithmVersion(V2)})) {
+		if got := texts[want.Keyword]; got != want.Text {
+			t.Errorf("Expected %s to be %q, got %q", want.Keyword, want.Text, got)
+		}
+	}
+	if texts[metaVersion] != "2" {
+		t.Errorf("Expected version 2, got %q", texts[metaVersion])
+	}
+}
+
+func TestEncodePNGWithoutMetadata(t *testing.T) {
+	data, err := PNG([]byte("png-no-metadata"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if texts, _ := readPNGText(bytes.NewReader(data)); len(texts) != 0 {
+		t.Errorf("Expected no tEXt chunks by default, got %v", texts)
+	}
+}
+
+func TestParsePNG(t *testing.T) {
+	hash := []byte("parse-png")
+
+	for _, opts := range [][]Option{
+		{},
+		{WithAlgorithmVersion(V2), WithJitter()},
+	} {
+		data, err := PNG(hash, append(opts, WithMetadata())...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got, err := ParsePNG(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := Describe(hash, opts...); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestParsePNGErrors(t *testing.T) {
+	plain, _ := PNG([]byte("parse-png-plain"))
+	tagged, _ := PNG([]byte("parse-png-tagged"), WithMetadata())
+	corrupt := bytes.Clone(tagged)
+	corrupt[bytes.Index(corrupt, []byte(metaParts))+len(metaParts)+1] = 'x'
+
+	// A tEXt chunk claiming a length, without its data
+	forged := func(length uint32) []byte {
+		data := binary.BigEndian.AppendUint32([]byte("\x89PNG\r\n\x1a\n"), length)
+		return append(data, "tEXtmonsterid"...)
+	}
+	textStart := bytes.Index(tagged, []byte("tEXt")) - 4
+
+	tests := []struct {
+		description string
+		data        []byte
+	}{
+		{"no metadata", plain},
+		{"truncated tEXt chunk", tagged[:textStart+12]},
+		{"oversized tEXt chunk", forged(0xffffffff)},
+		{"huge tEXt chunk", forged(1 << 30)},
+		{"not a png", []byte("GIF89a")},
+		{"truncated", tagged[:40]},
+		{"bad checksum", corrupt},
+	}
+
+	for _, test := range tests {
+		if _, err := ParsePNG(bytes.NewReader(test.data)); err == nil {
+			t.Errorf("Expected an error for %s", test.description)
+		}
+	}
+}
@@ -1,47 +1,131 @@
 package monsterid
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
-	"hash/fnv"
+	"hash"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
-	"log"
+	"io/fs"
 	"math"
-	"math/rand/v2"
 	"path"
+	"slices"
+	"sync"
+	"time"
 )
 
+//go:generate go run gen_parts.go
 //go:embed all:parts/*
 var parts embed.FS
 
-var (
-	legs  = 5
-	hair  = 5
-	arms  = 5
-	body  = 15
-	eyes  = 15
-	mouth = 10
-)
-
 var bodyParts = []string{"legs", "hair", "arms", "body", "eyes", "mouth"}
 
-type MonsterID struct {
-	legs  int
-	hair  int
-	arms  int
-	body  int
-	eyes  int
-	mouth int
+// partFileNames are the file names of the first parts of each category,
+// formatted once instead of for every monster.
+var partFileNames = func() map[string][]string {
+	names := make(map[string][]string, len(bodyParts))
+	for _, part := range bodyParts {
+		for n := 1; n <= 64; n++ {
+			names[part] = append(names[part], fmt.Sprintf("%s_%d.png", part, n))
+		}
+	}
+	return names
+}()
+
+// Helper to get the file name of a part, such as body_3.png
+func partFileName(part string, n int) string {
+	if names := partFileNames[part]; n >= 1 && n <= len(names) {
+		return names[n-1]
+	}
+
+	return fmt.Sprintf("%s_%d.png", part, n)
 }
 
+// nativeSize is the width and height of the part artwork in pixels.
+const nativeSize = 120
+
 // Options represents configuration for monster generation
 type Options struct {
 	Artistic   bool       // use artistic rendering with colors
-	Greyscale  bool       // use greyscale for artistic rendering
+	Greyscale  bool       // use greyscale for artistic rendering, same as Tone: ToneGreyscale
 	Background color.RGBA // background color (transparent if Alpha=0)
+	Size       int        // width and height in pixels (120 if zero)
+	Padding    int        // space between the monster and the edges in pixels
+	PaddingPct float64    // padding as a percentage of Size, used if Padding is zero
+	Filter     Filter     // resampling filter when Size is not 120
+	HashFunc   HashFunc   // seeds generation from the hash input (FNV-64a if nil)
+
+	AlgorithmVersion Version // revision of the generation algorithm (V1 if zero)
+	Jitter           bool    // slightly move and rotate arms, legs and hair by hash
+	Theme            Theme   // built-in part artwork (ThemeClassic if empty), ignored by a Generator
+	Style            Style   // kind of avatar (StyleMonster if empty), other styles ignore the options about parts
+	Initials         string  // name or letters drawn by StyleInitials, such as "Ada Lovelace" (letters from the hash if empty)
+
+	Exclude []string // part categories left out, such as "hair" or "arms", without changing the other parts
+
+	Accessories bool      // give some monsters a hash-derived hat, glasses or bow tie
+	Accessory   Accessory // always draw this accessory, such as AccessoryModerator
+
+	Seasons []Season  // overlays drawn when Date is in their range, such as Seasons()
+	Date    time.Time // day selecting the season (today if zero)
+
+	Hue          *float64 // fixed body hue, 0.0-1.0 (random if nil)
+	HueTolerance float64  // maximum hash-derived deviation from Hue
+
+	SaturationRange [2]float64 // minimum and maximum saturation (0.5-1.0 if zero)
+	LightnessShift  float64    // added to the lightness of colorized parts, -1.0-1.0
+	MinContrast     float64    // adjust lightness until the body has this WCAG contrast ratio against Background
+
+	Palette []color.Color // snap generated colors to the closest entry in hue
+
+	Tone    Tone          // restrict colors to greyscale, sepia or duotone
+	Duotone [2]color.RGBA // shadow and highlight colors for ToneDuotone
+
+	BackgroundImage image.Image // drawn over Background, stretched to the avatar size
+	TileBackground  bool        // repeat BackgroundImage at its own size instead
+
+	Shape        Shape  // outline of the avatar, corners outside it are transparent
+	CornerRadius int    // corner radius in pixels for ShapeRounded (Size/8 if zero)
+	Border       Border // frame along the edge of the avatar
+
+	Shadow  Shadow  // soft shadow cast by the monster onto the background
+	Outline Outline // sticker-style outline around the monster
+
+	Pixelate    int // draw the monster as pixel art on a grid of this many pixels, such as 24
+	PixelColors int // maximum number of colors of the pixel art (16 if zero)
+
+	TrimTransparent bool // crop to the bounding box of non-transparent pixels
+	TrimSquare      bool // center the cropped image on a square canvas
+
+	Output OutputType // concrete type of the returned image
+	Colors int        // quantize to a paletted image of at most this many colors, full color if zero
+
+	Compression png.CompressionLevel // PNG compression level used by the encoders
+	Metadata    bool                 // embed the version, parts and colors in PNG tEXt chunks
+
+	Parallelism int // parts prepared concurrently for large sizes, one at a time if zero
+}
+
+// Border is a frame drawn along the inside edge of the avatar's Shape.
+type Border struct {
+	Width int        // width in pixels, no border if zero
+	Color color.RGBA // color of the border
+}
+
+// HashFunc reduces the hash input to the 64-bit seed used to select parts and colors.
+type HashFunc func(data []byte) uint64
+
+// Hash64 adapts a hash.Hash64 constructor such as fnv.New64a to a HashFunc.
+func Hash64(newHash func() hash.Hash64) HashFunc {
+	return func(data []byte) uint64 {
+		h := newHash()
+		h.Write(data) //nolint:errcheck // hash.Hash never returns an error
+		return h.Sum64()
+	}
 }
 
 // DefaultOptions provides common defaults
@@ -50,112 +134,330 @@ func DefaultOptions() Options {
 		Artistic:   true,
 		Greyscale:  false,
 		Background: color.RGBA{R: 240, G: 240, B: 240, A: 255}, // light grey
+		Size:       nativeSize,
 	}
 }
 
-// New creates a monsterid image based on the provided hash.
-func New(hash []byte, opts ...Options) image.Image {
-	if len(opts) == 0 {
-		opts = append(opts, DefaultOptions())
+// Helper to get the saturation range
+func (o Options) saturationRange() (float64, float64) {
+	if o.SaturationRange == [2]float64{} {
+		return 0.5, 1.0
+	}
+
+	return o.SaturationRange[0], o.SaturationRange[1]
+}
+
+// Helper to get the padding in pixels, leaving at least one pixel for the monster
+func (o Options) padding() int {
+	size := o.size()
+	pad := o.Padding
+	if pad <= 0 && o.PaddingPct > 0 {
+		pad = int(float64(size) * o.PaddingPct / 100)
 	}
-	h := fnv.New64a()
-	if _, err := h.Write(hash); err != nil {
+
+	return max(0, min(pad, (size-1)/2))
+}
+
+// Helper to check if a part category is left out of the monster
+func (o Options) excluded(part string) bool {
+	return slices.Contains(o.Exclude, part)
+}
+
+// Helper to get the image size in pixels
+func (o Options) size() int {
+	if o.Size <= 0 {
+		return nativeSize
+	}
+
+	return o.Size
+}
+
+// New creates a monsterid image based on the provided hash.
+// It panics if the image cannot be generated, use NewWithError to handle failures.
+//
+// New used to take ...Options and now takes ...Option. Calls passing an
+// Options value keep compiling and still replace the whole configuration, but
+// spreading a []Options with opts... doesn't compile anymore: only its first
+// element was ever used, so pass opts[0] instead, or New(hash) if it is
+// empty. Function values of the old type func([]byte, ...Options)
+// image.Image need a wrapper doing the same.
+func New(hash []byte, opts ...Option) image.Image {
+	img, err := NewWithError(hash, opts...)
+	if err != nil {
 		panic(err)
 	}
-	r := rand.New(rand.NewPCG(h.Sum64(), (h.Sum64()>>1)|1))
 
-	// Select monster parts
-	mid := &MonsterID{}
-	mid.legs = r.IntN(legs) + 1
-	mid.hair = r.IntN(hair) + 1
-	mid.arms = r.IntN(arms) + 1
-	mid.body = r.IntN(body) + 1
-	mid.eyes = r.IntN(eyes) + 1
-	mid.mouth = r.IntN(mouth) + 1
+	return img
+}
 
-	// Create base image
-	img := image.NewRGBA(image.Rect(0, 0, 120, 120))
+// NewWithError creates a monsterid image based on the provided hash,
+// returning an error instead of panicking if generation fails.
+func NewWithError(hash []byte, opts ...Option) (image.Image, error) {
+	return NewContext(context.Background(), hash, opts...)
+}
 
-	// Draw background
-	if opts[0].Background.A > 0 {
-		draw.Draw(img, img.Bounds(), &image.Uniform{C: opts[0].Background}, image.Point{}, draw.Src)
-	} else {
-		// Transparent background
-		for y := 0; y < img.Bounds().Dy(); y++ {
-			for x := 0; x < img.Bounds().Dx(); x++ {
-				img.SetRGBA(x, y, color.RGBA{})
-			}
+// NewContext is like NewWithError but abandons generation with the context's
+// error once ctx is done.
+func NewContext(ctx context.Context, hash []byte, opts ...Option) (image.Image, error) {
+	o := buildOptions(opts)
+	if err := o.checkVersion(); err != nil {
+		return nil, err
+	}
+	if o.style() != StyleMonster {
+		return newStyleImage(ctx, hash, o)
+	}
+
+	return newImage(ctx, describeHash(hash, o), o, o.theme().pack())
+}
+
+// DrawTo draws the monster for hash onto dst with its top-left corner at at,
+// composited over the existing content of dst.
+func DrawTo(dst draw.Image, at image.Point, hash []byte, opts ...Option) error {
+	o := buildOptions(opts)
+	if err := o.checkVersion(); err != nil {
+		return err
+	}
+	if o.style() != StyleMonster {
+		return drawStyle(context.Background(), dst, at, hash, o)
+	}
+
+	return render(context.Background(), dst, at, describeHash(hash, o), o, o.theme().pack())
+}
+
+// partLoader returns the decoded image for a part file at scale times the
+// native size, or at a lower resolution if there is no such variant. Callers
+// must not modify it.
+type partLoader func(fileName string, scale int) (*image.RGBA, error)
+
+// partScales are the resolutions part artwork can be provided at, as a
+// multiple of the native size.
+var partScales = []int{1, 2, 4}
+
+// Helper to pick the lowest part resolution that doesn't need upscaling to
+// size, or the highest one there is
+func partScale(size int) int {
+	for _, s := range partScales {
+		if nativeSize*s >= size {
+			return s
 		}
 	}
 
-	// Generate hue for body base color (for artistic mode)
-	hue := r.Float64()                  // 0.0-1.0
-	saturation := 0.5 + r.Float64()*0.5 // 0.5-1.0
+	return partScales[len(partScales)-1]
+}
 
-	// Draw each body part
-	for _, part := range bodyParts {
-		partNum := getPartNumber(mid, part)
-		fileName := fmt.Sprintf("%s_%d.png", part, partNum)
-		partImage, err := loadPart(fileName)
+// Helper to get the path of a part file at a scale, such as @2x/body_1.png
+func scaledPartPath(fileName string, scale int) string {
+	if scale == 1 {
+		return fileName
+	}
+
+	return path.Join(fmt.Sprintf("@%dx", scale), fileName)
+}
+
+// Helper to render the monster described by d into a new image
+func newImage(ctx context.Context, d Descriptor, o Options, p pack) (image.Image, error) {
+	size := o.size()
+	img := getRGBA(image.Rect(0, 0, size, size))
+	if err := render(ctx, img, image.Point{}, d, o, p); err != nil {
+		putRGBA(img)
+		return nil, err
+	}
+
+	return finishImage(img, o), nil
+}
+
+// Helper to trim and convert a rendered image as requested by o, releasing
+// img if it isn't returned
+func finishImage(img *image.RGBA, o Options) image.Image {
+	if o.TrimTransparent {
+		if trimmed := trimImage(img, o.TrimSquare); trimmed != img {
+			putRGBA(img)
+			img = trimmed
+		}
+	}
+
+	out := convertOutput(img, o)
+	if out != image.Image(img) {
+		putRGBA(img)
+	}
+
+	return out
+}
+
+// Helper to render the monster described by d onto dst using parts from p
+func render(ctx context.Context, dst draw.Image, at image.Point, d Descriptor, o Options, p pack) error {
+	return shaped(dst, at, o, func(dst draw.Image, at image.Point) error {
+		return compose(ctx, dst, at, d, o, p)
+	})
+}
+
+// Helper to draw an avatar with compose onto dst, masked by the shape and
+// framed by the border of o
+func shaped(dst draw.Image, at image.Point, o Options, compose func(dst draw.Image, at image.Point) error) error {
+	if o.Shape == ShapeSquare && o.Border.Width <= 0 {
+		return compose(dst, at)
+	}
+
+	// Render into a separate image so the shape also masks the background
+	size := o.size()
+	img := getRGBA(image.Rect(0, 0, size, size))
+	defer putRGBA(img)
+	if err := compose(img, image.Point{}); err != nil {
+		return err
+	}
+
+	if o.Border.Width > 0 {
+		mask := borderMask(o.Shape, size, o.CornerRadius, o.Border.Width)
+		draw.DrawMask(img, img.Bounds(), &image.Uniform{C: o.Border.Color}, image.Point{}, mask, image.Point{}, draw.Over)
+	}
+
+	rect := image.Rectangle{Min: at, Max: at.Add(image.Pt(size, size))}
+	draw.DrawMask(dst, rect, img, image.Point{}, shapeMask(o.Shape, size, o.CornerRadius), image.Point{}, draw.Over)
+
+	return nil
+}
+
+// Helper to draw the background and parts onto dst
+func compose(ctx context.Context, dst draw.Image, at image.Point, d Descriptor, o Options, p pack) error {
+	size := o.size()
+	rect := image.Rectangle{Min: at, Max: at.Add(image.Pt(size, size))}
+
+	drawBackground(dst, rect, o)
+
+	// The monster is drawn inside the padding
+	inner := rect.Inset(o.padding())
+	monsterSize := inner.Dx()
+
+	// Parts are composited on a separate layer at their native size when
+	// they need further processing
+	tone := o.tone()
+	toned := tone == ToneSepia || tone == ToneDuotone
+	layered := monsterSize != nativeSize || toned || d.Mirrored || o.Pixelate > 0 ||
+		o.Shadow.Opacity > 0 || o.Outline.Width > 0
+
+	// Prepare each body part, at a higher resolution for large sizes
+	scale := 1
+	if layered {
+		scale = partScale(monsterSize)
+	}
+	shift := lightnessShift(d, o)
+
+	// Copies of parts and the layer are reused by later renders
+	s := newScratch()
+	defer s.release()
+
+	// One per body part, with a constant capacity to stay off the heap
+	partImages := make([]*image.RGBA, 0, 6)
+	if o.Parallelism > 1 && monsterSize >= parallelSize {
+		prepared, err := prepareParts(ctx, d, o, shift, p, scale, s)
 		if err != nil {
-			log.Printf("Error loading part %s: %v", fileName, err)
-			continue
+			return err
 		}
+		partImages = append(partImages, prepared...)
+	} else {
+		for _, part := range bodyParts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// Parts are still selected, so excluding one doesn't change the others
+			if o.excluded(part) {
+				continue
+			}
 
-		// Apply colorization for artistic mode
-		if opts[0].Artistic {
-			if part == "body" {
-				colorizeImage(partImage, hue, saturation, !opts[0].Greyscale)
-			} else if part == "arms" || part == "legs" {
-				// Give arms and legs random colors with 30% probability
-				if r.Float64() < 0.3 {
-					colorizeImage(partImage, r.Float64(), saturation, !opts[0].Greyscale)
-				}
-			} else if opts[0].Greyscale {
-				// Apply greyscale to other parts too
-				colorizeImage(partImage, 0, 0, false)
+			partImage, err := preparePart(d, o, part, shift, p, scale, s)
+			if err != nil {
+				return err
 			}
+			partImages = append(partImages, partImage)
 		}
+	}
+	layerSize := 0
+	for _, partImage := range partImages {
+		layerSize = max(layerSize, partImage.Bounds().Dx())
+	}
+	if layerSize == 0 {
+		layerSize = nativeSize * scale
+	}
 
-		draw.Draw(img, img.Bounds(), partImage, image.Point{}, draw.Over)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	return img
-}
+	var canvas draw.Image = dst
+	canvasRect := inner
+	if layered {
+		canvas = s.image(image.Rect(0, 0, layerSize, layerSize))
+		canvasRect = canvas.Bounds()
+	}
 
-// Helper function to colorize an image with HSL values
-func colorizeImage(img *image.RGBA, hue, saturation float64, colorize bool) {
-	if !colorize {
-		// Convert to greyscale instead of just returning
-		bounds := img.Bounds()
-		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-			for x := bounds.Min.X; x < bounds.Max.X; x++ {
-				r, g, b, a := img.At(x, y).RGBA()
+	// Draw each body part, scaling up parts missing a high resolution variant
+	for _, partImage := range partImages {
+		if partImage.Bounds().Dx() != layerSize {
+			partImage = s.scale(partImage, layerSize, o.Filter.kernel())
+		}
+		draw.Draw(canvas, canvasRect, partImage, image.Point{}, draw.Over)
+	}
 
-				// Skip transparent pixels
-				if a < 100 {
-					continue
-				}
+	// Overlays are drawn after mirroring so badges keep their side
+	if layered && d.Mirrored {
+		mirrorImage(canvas.(*image.RGBA))
+	}
+	overlays, err := loadOverlays(d, o, scale)
+	if err != nil {
+		return err
+	}
+	for _, ov := range overlays {
+		img := ov.img
+		if img.Bounds().Dx() != layerSize {
+			img = s.scale(img, layerSize, o.Filter.kernel())
+		}
+		draw.Draw(canvas, canvasRect, img, image.Point{}, draw.Over)
+	}
 
-				// Convert to greyscale using luminance formula
-				grey := uint8((0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 256)
-				img.Set(x, y, color.RGBA{
-					R: grey,
-					G: grey,
-					B: grey,
-					A: uint8(a >> 8),
-				})
-			}
+	if layered {
+		layer := canvas.(*image.RGBA)
+		if toned {
+			toneImage(layer, tone, o.Duotone)
+		}
+		if o.Pixelate > 0 {
+			layer = scaleImage(pixelate(layer, o.Pixelate, o.PixelColors), monsterSize, nearestNeighbor)
+		} else if monsterSize != layerSize {
+			layer = s.scale(layer, monsterSize, o.Filter.kernel())
+		}
+		if o.Shadow.Opacity > 0 {
+			drawShadow(dst, rect, inner.Min, layer, o.Shadow)
+		}
+		if o.Outline.Width > 0 {
+			drawOutline(dst, rect, inner.Min, layer, o.Outline)
 		}
-		return
+		draw.Draw(dst, inner, layer, image.Point{}, draw.Over)
 	}
+
+	return nil
+}
+
+// Helper function to colorize an image with HSL values, working on the
+// premultiplied pixels of img directly
+func colorizeImage(img *image.RGBA, hue, saturation, lightnessShift float64, colorize bool) {
+	// Colors only depend on the lightness of the pixel, so each is computed once
+	lut := lightnessLUT{table: lightnesses(), hue: hue, saturation: saturation, shift: lightnessShift}
+
 	bounds := img.Bounds()
 	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			r, g, b, a := img.At(x, y).RGBA()
-
+		row := img.Pix[img.PixOffset(bounds.Min.X, y):img.PixOffset(bounds.Max.X, y)]
+		for i := 0; i+3 < len(row); i += 4 {
 			// Skip transparent pixels
-			if a < 100 {
+			if row[i+3] == 0 {
+				continue
+			}
+
+			// Channels on the 16-bit scale of color.Color
+			r, g, b := uint32(row[i])*0x101, uint32(row[i+1])*0x101, uint32(row[i+2])*0x101
+
+			if !colorize {
+				// Convert to greyscale using luminance formula
+				grey := uint8((0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 256)
+				row[i], row[i+1], row[i+2] = grey, grey, grey
 				continue
 			}
 
@@ -165,23 +467,64 @@ func colorizeImage(img *image.RGBA, hue, saturation float64, colorize bool) {
 				continue
 			}
 
-			// Convert pixel to HSL, modify hue/saturation, convert back
-			_, _, l := rgbToHsl(float64(r)/0xFFFF, float64(g)/0xFFFF, float64(b)/0xFFFF)
-			r2, g2, b2 := hslToRgb(hue, saturation, l)
-
-			img.Set(x, y, color.RGBA{
-				R: uint8(r2 * 255),
-				G: uint8(g2 * 255),
-				B: uint8(b2 * 255),
-				A: uint8(a >> 8),
-			})
+			// Replace the hue and saturation of the pixel, keeping its lightness
+			c := lut.color(max(row[i], row[i+1], row[i+2]), min(row[i], row[i+1], row[i+2]))
+			row[i], row[i+1], row[i+2] = c[0], c[1], c[2]
 		}
 	}
 }
 
+// embeddedPart is a decoded embedded part, or the error decoding it.
+type embeddedPart struct {
+	img *image.RGBA
+	err error
+}
+
+// embeddedParts caches the embedded parts by directory, scale and file name
+// once decoded, so they aren't decoded again for every monster.
+var embeddedParts sync.Map
+
 // Helper to load a part image from embedded resources
-func loadPart(fileName string) (*image.RGBA, error) {
-	asset, err := parts.Open(path.Join("parts", fileName))
+func loadPart(fileName string, scale int) (*image.RGBA, error) {
+	return loadEmbeddedPart("parts", fileName, scale)
+}
+
+// Helper to load an embedded part from dir like loadScaledPart, decoding it
+// only the first time. Callers must not modify it.
+func loadEmbeddedPart(dir, fileName string, scale int) (*image.RGBA, error) {
+	key := path.Join(dir, scaledPartPath(fileName, scale))
+	if p, ok := embeddedParts.Load(key); ok {
+		return p.(embeddedPart).img, p.(embeddedPart).err
+	}
+
+	img, err := loadScaledPart(parts, dir, fileName, scale)
+	embeddedParts.Store(key, embeddedPart{img, err})
+
+	return img, err
+}
+
+// Helper to load a part from dir in fsys at a scale, rasterizing an SVG part
+// or falling back to the native resolution if there is no variant at that
+// scale
+func loadScaledPart(fsys fs.FS, dir, fileName string, scale int) (*image.RGBA, error) {
+	if scale > 1 {
+		img, err := decodePart(fsys, path.Join(dir, scaledPartPath(fileName, scale)))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return img, err
+		}
+	}
+
+	img, err := decodeSVGPart(fsys, path.Join(dir, fileName), scale)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return img, err
+	}
+
+	return decodePart(fsys, path.Join(dir, fileName))
+}
+
+// Helper to decode a part image from fsys
+func decodePart(fsys fs.FS, fileName string) (*image.RGBA, error) {
+	asset, err := fsys.Open(fileName)
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +543,126 @@ func loadPart(fileName string) (*image.RGBA, error) {
 	return rgba, nil
 }
 
+// Helper to load a part and apply its colorization and jitter, the result
+// must not be modified. Copies are taken from s, if not nil.
+func preparePart(d Descriptor, o Options, part string, shift float64, p pack, scale int, s *scratch) (*image.RGBA, error) {
+	tone := o.tone()
+	partNum := getPartNumber(&d, part)
+	fileName := partFileName(part, partNum)
+	partImage, err := p.load(fileName, scale)
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: load part %s: %w", fileName, err)
+	}
+
+	// Apply colorization for artistic mode, on a copy of the loaded part
+	if o.Artistic {
+		_, ruled := p.colors.rules()[part]
+		if hue := p.colors.partHue(&d, part); hue >= 0 {
+			mask, err := loadMask(p, fileName, scale, partImage.Bounds().Dx())
+			if err != nil {
+				return nil, fmt.Errorf("monsterid: load mask of %s: %w", fileName, err)
+			}
+
+			if p.colorized != nil {
+				partImage = p.colorized.colorize(fileName, scale, partImage, mask, hue, d.Saturation, shift, tone == ToneGreyscale)
+			} else {
+				partImage = s.clone(partImage)
+				colorizePart(partImage, mask, hue, d.Saturation, shift, tone == ToneGreyscale)
+			}
+		} else if !ruled && tone == ToneGreyscale {
+			// Apply greyscale to other parts too
+			partImage = s.clone(partImage)
+			colorizeImage(partImage, 0, 0, 0, false)
+		}
+	}
+
+	if j := getPartJitter(&d, part); j != (Jitter{}) {
+		// Offsets are in native pixels
+		f := partImage.Bounds().Dx() / nativeSize
+		partImage = jitterImage(partImage, Jitter{DX: j.DX * f, DY: j.DY * f, Angle: j.Angle})
+	}
+
+	return partImage, nil
+}
+
+// Helper to colorize a part, only where mask is set if there is a mask
+func colorizePart(img, mask *image.RGBA, hue, saturation, shift float64, greyscale bool) {
+	if mask != nil && !greyscale {
+		colorizeMasked(img, mask, hue, saturation, shift)
+	} else {
+		colorizeImage(img, hue, saturation, shift, !greyscale)
+	}
+}
+
+// Helper to rotate an image around its center and translate it, sampling
+// bilinearly into a new image
+func jitterImage(img *image.RGBA, j Jitter) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	cx := float64(b.Min.X+b.Max.X) / 2
+	cy := float64(b.Min.Y+b.Max.Y) / 2
+	sin, cos := math.Sincos(-j.Angle)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// Map the destination pixel center back into the source
+			dx := float64(x-j.DX) + 0.5 - cx
+			dy := float64(y-j.DY) + 0.5 - cy
+			sx := cx + dx*cos - dy*sin - 0.5
+			sy := cy + dx*sin + dy*cos - 0.5
+
+			x0, y0 := int(math.Floor(sx)), int(math.Floor(sy))
+			fx, fy := sx-float64(x0), sy-float64(y0)
+
+			var p [4]float64
+			for _, s := range [4]struct {
+				x, y int
+				w    float64
+			}{
+				{x0, y0, (1 - fx) * (1 - fy)},
+				{x0 + 1, y0, fx * (1 - fy)},
+				{x0, y0 + 1, (1 - fx) * fy},
+				{x0 + 1, y0 + 1, fx * fy},
+			} {
+				if !(image.Point{X: s.x, Y: s.y}.In(b)) {
+					continue
+				}
+				off := img.PixOffset(s.x, s.y)
+				for i := range p {
+					p[i] += s.w * float64(img.Pix[off+i])
+				}
+			}
+
+			off := dst.PixOffset(x, y)
+			for i := range p {
+				dst.Pix[off+i] = clampUint8(p[i])
+			}
+		}
+	}
+
+	return dst
+}
+
+// Helper to flip an image horizontally in place
+func mirrorImage(img *image.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for l, r := b.Min.X, b.Max.X-1; l < r; l, r = l+1, r-1 {
+			lo, ro := img.PixOffset(l, y), img.PixOffset(r, y)
+			for i := 0; i < 4; i++ {
+				img.Pix[lo+i], img.Pix[ro+i] = img.Pix[ro+i], img.Pix[lo+i]
+			}
+		}
+	}
+}
+
+// Helper to copy an image before modifying it
+func cloneImage(img *image.RGBA) *image.RGBA {
+	c := *img
+	c.Pix = append([]uint8(nil), img.Pix...)
+	return &c
+}
+
 // RGB to HSL conversion
 func rgbToHsl(r, g, b float64) (float64, float64, float64) {
 	x := math.Max(math.Max(r, g), b)
@@ -277,20 +740,20 @@ func hueToRgb(p, q, t float64) float64 {
 	return p
 }
 
-func getPartNumber(mid *MonsterID, part string) int {
+func getPartNumber(d *Descriptor, part string) int {
 	switch part {
 	case "legs":
-		return mid.legs
+		return d.Legs
 	case "hair":
-		return mid.hair
+		return d.Hair
 	case "arms":
-		return mid.arms
+		return d.Arms
 	case "body":
-		return mid.body
+		return d.Body
 	case "eyes":
-		return mid.eyes
+		return d.Eyes
 	case "mouth":
-		return mid.mouth
+		return d.Mouth
 	}
 	return 0
 }
@@ -2,7 +2,11 @@ package monsterid
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"image"
 	"image/color"
+	"image/draw"
 	"image/png"
 	"math"
 	"testing"
@@ -221,3 +225,294 @@ func TestGreyscaleOption(t *testing.T) {
 		t.Error("Found non-greyscale pixel in greyscale mode")
 	}
 }
+
+func TestSizeOption(t *testing.T) {
+	hash := []byte("size-test")
+
+	for _, size := range []int{64, 256, 512} {
+		opts := DefaultOptions()
+		opts.Size = size
+
+		img := New(hash, opts)
+
+		bounds := img.Bounds()
+		if bounds.Dx() != size || bounds.Dy() != size {
+			t.Errorf("Expected image dimensions %dx%d, got %dx%d", size, size, bounds.Dx(), bounds.Dy())
+		}
+
+		// The background must still cover the whole canvas
+		r, g, b, a := img.At(size-1, size-1).RGBA()
+		if r>>8 != 240 || g>>8 != 240 || b>>8 != 240 || a>>8 != 255 {
+			t.Errorf("Size %d: expected light grey corner, got RGBA(%d,%d,%d,%d)", size, r>>8, g>>8, b>>8, a>>8)
+		}
+	}
+}
+
+func TestNewWithError(t *testing.T) {
+	hash := []byte("error-test")
+
+	img, err := NewWithError(hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buf1 := new(bytes.Buffer)
+	buf2 := new(bytes.Buffer)
+
+	if err := png.Encode(buf1, img); err != nil {
+		t.Fatalf("Failed to encode image 1: %v", err)
+	}
+	if err := png.Encode(buf2, New(hash)); err != nil {
+		t.Fatalf("Failed to encode image 2: %v", err)
+	}
+
+	if !bytes.Equal(buf1.Bytes(), buf2.Bytes()) {
+		t.Error("NewWithError and New produced different images")
+	}
+}
+
+func TestLoadPartReportsMissingFile(t *testing.T) {
+	if _, err := loadPart("body_0.png", 1); err == nil {
+		t.Error("Expected an error loading a missing part")
+	}
+}
+
+func TestLoadPartIsCached(t *testing.T) {
+	first, err := loadPart("body_1.png", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second, _ := loadPart("body_1.png", 1); second != first {
+		t.Error("Expected the decoded part to be reused")
+	}
+
+	// Shared parts are left unchanged by rendering
+	crown, err := loadAccessory(AccessoryCrown, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pix := bytes.Clone(crown.Pix)
+	New([]byte("cached"), WithGreyscale(), WithAccessory(AccessoryCrown))
+	if !bytes.Equal(crown.Pix, pix) {
+		t.Error("Expected the cached accessory to be left unchanged")
+	}
+}
+
+func TestNewContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	img, err := NewContext(ctx, []byte("context-test"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if img != nil {
+		t.Error("Expected no image for a canceled context")
+	}
+}
+
+func TestNewContextMatchesNew(t *testing.T) {
+	hash := []byte("context-test")
+
+	img, err := NewContext(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(img.(*image.RGBA).Pix, New(hash).(*image.RGBA).Pix) {
+		t.Error("NewContext and New produced different images")
+	}
+}
+
+func TestDrawToMatchesNew(t *testing.T) {
+	hash := []byte("draw-to-test")
+
+	for _, size := range []int{nativeSize, 64} {
+		want := New(hash, WithSize(size)).(*image.RGBA)
+
+		dst := image.NewRGBA(image.Rect(0, 0, 300, 200))
+		at := image.Pt(150, 40)
+		if err := DrawTo(dst, at, hash, WithSize(size)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got := dst.SubImage(image.Rectangle{Min: at, Max: at.Add(image.Pt(size, size))}).(*image.RGBA)
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if got.RGBAAt(at.X+x, at.Y+y) != want.RGBAAt(x, y) {
+					t.Fatalf("Size %d: pixel (%d,%d) differs from New", size, x, y)
+				}
+			}
+		}
+
+		if c := dst.RGBAAt(0, 0); c != (color.RGBA{}) {
+			t.Errorf("Size %d: DrawTo touched pixels outside the avatar: %v", size, c)
+		}
+	}
+}
+
+func TestDrawToTransparentKeepsDestination(t *testing.T) {
+	banner := color.RGBA{R: 0, G: 0, B: 255, A: 255}
+	dst := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: banner}, image.Point{}, draw.Src)
+
+	if err := DrawTo(dst, image.Pt(40, 40), []byte("draw-to-banner"), WithTransparentBackground()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The corner of the avatar has no monster parts, so the banner shows through
+	if c := dst.RGBAAt(40, 40); c != banner {
+		t.Errorf("Expected banner color under transparent background, got %v", c)
+	}
+}
+
+func TestLightnessShift(t *testing.T) {
+	hash := []byte("lightness-test")
+	d := Describe(hash)
+
+	// Compare the mean lightness of the colorized body on its own
+	lightness := func(shift float64) float64 {
+		img, err := FromParts(d, WithTransparentBackground(), WithLightnessShift(shift))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		sum := 0.0
+		pix := img.(*image.RGBA).Pix
+		for i := 0; i < len(pix); i += 4 {
+			sum += float64(pix[i]) + float64(pix[i+1]) + float64(pix[i+2])
+		}
+		return sum
+	}
+
+	base := lightness(0)
+	if lighter := lightness(0.2); lighter <= base {
+		t.Errorf("Positive lightness shift did not lighten the monster")
+	}
+	if darker := lightness(-0.2); darker >= base {
+		t.Errorf("Negative lightness shift did not darken the monster")
+	}
+}
+
+func TestJitterImageTranslation(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	src.SetRGBA(4, 4, color.RGBA{R: 255, A: 255})
+
+	dst := jitterImage(src, Jitter{DX: 2, DY: 3})
+	if c := dst.RGBAAt(6, 7); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("Expected the pixel to move to (6,7), got %v there", c)
+	}
+	if c := dst.RGBAAt(4, 4); c.A != 0 {
+		t.Errorf("Expected the original position to be empty, got %v", c)
+	}
+}
+
+func TestPadding(t *testing.T) {
+	hash := []byte("padding-test")
+	bg := DefaultOptions().Background
+
+	for _, opt := range []Option{WithPadding(20), WithPaddingPercent(16.7)} {
+		img := New(hash, opt).(*image.RGBA)
+
+		if img.Bounds().Dx() != nativeSize {
+			t.Fatalf("Padding changed the image size to %d", img.Bounds().Dx())
+		}
+
+		// Nothing but background within the padding
+		for y := 0; y < nativeSize; y++ {
+			for x := 0; x < nativeSize; x++ {
+				if x >= 20 && x < nativeSize-20 && y >= 20 && y < nativeSize-20 {
+					continue
+				}
+				if c := img.RGBAAt(x, y); c != bg {
+					t.Fatalf("Expected background in the padding at (%d,%d), got %v", x, y, c)
+				}
+			}
+		}
+	}
+}
+
+func TestPaddingIsClamped(t *testing.T) {
+	o := buildOptions([]Option{WithSize(64), WithPadding(100)})
+	if got := o.padding(); got != 31 {
+		t.Errorf("Expected padding clamped to 31, got %d", got)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	hash := []byte("exclude-test")
+
+	if Describe(hash) != Describe(hash, WithExclude("hair", "arms")) {
+		t.Error("Expected excluding parts to keep the selected parts")
+	}
+
+	full := New(hash).(*image.RGBA)
+	minimal := New(hash, WithExclude("hair", "arms")).(*image.RGBA)
+	if bytes.Equal(full.Pix, minimal.Pix) {
+		t.Error("Expected excluded parts to change the image")
+	}
+
+	// Without any part only the background is left
+	bg := DefaultOptions().Background
+	empty := New(hash, WithExclude(bodyParts...), WithSize(64)).(*image.RGBA)
+	for i := 0; i < len(empty.Pix); i += 4 {
+		if c := (color.RGBA{R: empty.Pix[i], G: empty.Pix[i+1], B: empty.Pix[i+2], A: empty.Pix[i+3]}); c != bg {
+			t.Fatalf("Expected only background, got %v", c)
+		}
+	}
+
+	svg, err := SVG(hash, WithExclude("hair"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.Contains(svg, []byte(`<g id="hair">`)) || !bytes.Contains(svg, []byte(`<g id="arms">`)) {
+		t.Error("Expected only the hair to be left out of the SVG")
+	}
+}
+
+func TestColorizeImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 100, G: 100, B: 100, A: 255}), image.Point{}, draw.Src)
+	img.SetRGBA(2, 1, color.RGBA{R: 250, G: 250, B: 250, A: 255})
+	img.SetRGBA(3, 1, color.RGBA{})
+
+	// Only the pixels of a sub-image are colorized
+	colorizeImage(img.SubImage(image.Rect(1, 1, 4, 2)).(*image.RGBA), 0, 1, 0, true)
+
+	r, g, b := hslToRgb(0, 1, 100.0/255)
+	red := color.RGBA{R: uint8(r * 255), G: uint8(g * 255), B: uint8(b * 255), A: 255}
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{1, 0, color.RGBA{R: 100, G: 100, B: 100, A: 255}},
+		{0, 1, color.RGBA{R: 100, G: 100, B: 100, A: 255}},
+		{1, 1, red},
+		{2, 1, color.RGBA{R: 250, G: 250, B: 250, A: 255}},
+		{3, 1, color.RGBA{}},
+	}
+	for _, test := range tests {
+		if got := img.RGBAAt(test.x, test.y); got != test.want {
+			t.Errorf("Expected %v at %d,%d, got %v", test.want, test.x, test.y, got)
+		}
+	}
+
+	colorizeImage(img, 0, 0, 0, false)
+	if c := img.RGBAAt(1, 1); c.R != c.G || c.G != c.B || c.A != 255 {
+		t.Errorf("Expected an opaque grey, got %v", c)
+	}
+}
+
+func BenchmarkColorizeImage(b *testing.B) {
+	part, err := loadPart("body_1.png", 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	img := cloneImage(part)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(img.Pix, part.Pix)
+		colorizeImage(img, 0.3, 0.8, 0, true)
+	}
+}
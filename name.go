@@ -0,0 +1,43 @@
+package monsterid
+
+import (
+	"math"
+	"strings"
+)
+
+// hueNames names twelve evenly spaced hues, starting at red.
+var hueNames = []string{
+	"Crimson", "Amber", "Golden", "Lime", "Green", "Jade",
+	"Teal", "Azure", "Cobalt", "Violet", "Magenta", "Rose",
+}
+
+// eyesNames has one adjective per eyes part.
+var eyesNames = []string{
+	"Wide-Eyed", "Sleepy", "Googly", "Squinty", "Starry-Eyed",
+	"Beady", "Bug-Eyed", "Wonky", "Curious", "Dreamy",
+	"Shifty", "Bright-Eyed", "Grumpy", "Cheeky", "Jolly",
+}
+
+// bodyNames has one noun per body part.
+var bodyNames = []string{
+	"Gloop", "Blob", "Muncher", "Grumble", "Wobbler",
+	"Snorkel", "Bumble", "Fuzzball", "Gobbler", "Squish",
+	"Noodle", "Thumper", "Snuffle", "Dumpling", "Goober",
+}
+
+// Name returns a stable, human-readable name for the monster of the provided
+// hash, such as "Crimson Sleepy Gloop", made of its body hue, eyes and body.
+func Name(hash []byte, opts ...Option) string {
+	d := Describe(hash, opts...)
+
+	return strings.Join([]string{
+		hueNames[hueIndex(d.Hue, len(hueNames))],
+		eyesNames[(d.Eyes-1)%len(eyesNames)],
+		bodyNames[(d.Body-1)%len(bodyNames)],
+	}, " ")
+}
+
+// Helper to get the closest of n evenly spaced hues starting at red
+func hueIndex(hue float64, n int) int {
+	return int(math.Round(wrapHue(hue)*float64(n))) % n
+}
@@ -0,0 +1,56 @@
+package monsterid
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	hash := []byte("name-test")
+
+	name := Name(hash)
+	if name != Name(hash) {
+		t.Fatal("Name is not deterministic")
+	}
+
+	words := strings.Fields(name)
+	if len(words) != 3 {
+		t.Fatalf("Expected three words, got %q", name)
+	}
+
+	d := Describe(hash)
+	if words[1] != eyesNames[d.Eyes-1] || words[2] != bodyNames[d.Body-1] {
+		t.Errorf("Name %q does not match eyes %d and body %d", name, d.Eyes, d.Body)
+	}
+}
+
+func TestNameVaries(t *testing.T) {
+	names := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		names[Name([]byte(fmt.Sprintf("name-%d", i)))] = true
+	}
+
+	if len(names) < 40 {
+		t.Errorf("Expected mostly distinct names, got %d of 50", len(names))
+	}
+}
+
+func TestHueIndex(t *testing.T) {
+	tests := []struct {
+		hue  float64
+		want string
+	}{
+		{0, "Crimson"},
+		{0.98, "Crimson"},
+		{1.0 / 3, "Green"},
+		{2.0 / 3, "Cobalt"},
+		{-0.25, "Violet"},
+	}
+
+	for _, test := range tests {
+		if got := hueNames[hueIndex(test.hue, len(hueNames))]; got != test.want {
+			t.Errorf("Expected %q for hue %v, got %q", test.want, test.hue, got)
+		}
+	}
+}
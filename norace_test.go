@@ -0,0 +1,7 @@
+//go:build !race
+
+package monsterid
+
+// raceEnabled skips allocation tests, as the race detector randomly drops
+// pooled values.
+const raceEnabled = false
@@ -0,0 +1,347 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+	"time"
+)
+
+// Option configures monster generation. Both an Options value, which replaces
+// the whole configuration, and the With* helpers below implement it.
+type Option interface {
+	apply(*Options)
+}
+
+// apply replaces the configuration with o.
+func (o Options) apply(dst *Options) {
+	*dst = o
+}
+
+type optionFunc func(*Options)
+
+func (f optionFunc) apply(o *Options) {
+	f(o)
+}
+
+// WithArtistic enables or disables colorized rendering.
+func WithArtistic(enabled bool) Option {
+	return optionFunc(func(o *Options) {
+		o.Artistic = enabled
+	})
+}
+
+// WithGreyscale renders the monster in shades of grey.
+func WithGreyscale() Option {
+	return optionFunc(func(o *Options) {
+		o.Greyscale = true
+	})
+}
+
+// WithTone restricts the colors of the monster.
+func WithTone(tone Tone) Option {
+	return optionFunc(func(o *Options) {
+		o.Tone = tone
+	})
+}
+
+// WithSepia renders the monster in sepia tones.
+func WithSepia() Option {
+	return WithTone(ToneSepia)
+}
+
+// WithDuotone renders the monster as a blend between a shadow and a highlight color.
+func WithDuotone(shadow, highlight color.RGBA) Option {
+	return optionFunc(func(o *Options) {
+		o.Tone = ToneDuotone
+		o.Duotone = [2]color.RGBA{shadow, highlight}
+	})
+}
+
+// WithBackground sets the background color, an Alpha of 0 gives a transparent background.
+func WithBackground(c color.RGBA) Option {
+	return optionFunc(func(o *Options) {
+		o.Background = c
+	})
+}
+
+// WithTransparentBackground leaves the background transparent.
+func WithTransparentBackground() Option {
+	return WithBackground(color.RGBA{})
+}
+
+// WithBackgroundImage draws img stretched over the whole avatar beneath the monster.
+func WithBackgroundImage(img image.Image) Option {
+	return optionFunc(func(o *Options) {
+		o.BackgroundImage = img
+		o.TileBackground = false
+	})
+}
+
+// WithBackgroundPattern repeats img beneath the monster.
+func WithBackgroundPattern(img image.Image) Option {
+	return optionFunc(func(o *Options) {
+		o.BackgroundImage = img
+		o.TileBackground = true
+	})
+}
+
+// WithShape masks the avatar to a square, circle or rounded rectangle.
+func WithShape(shape Shape) Option {
+	return optionFunc(func(o *Options) {
+		o.Shape = shape
+	})
+}
+
+// WithRoundedCorners masks the avatar to a rectangle with corners of the given radius in pixels.
+func WithRoundedCorners(radius int) Option {
+	return optionFunc(func(o *Options) {
+		o.Shape = ShapeRounded
+		o.CornerRadius = radius
+	})
+}
+
+// WithBorder draws a frame of the given width and color along the edge of the avatar.
+func WithBorder(width int, c color.RGBA) Option {
+	return optionFunc(func(o *Options) {
+		o.Border = Border{Width: width, Color: c}
+	})
+}
+
+// WithShadow casts a soft black shadow with the given blur radius, offset and opacity.
+func WithShadow(radius int, offset image.Point, opacity float64) Option {
+	return optionFunc(func(o *Options) {
+		o.Shadow = Shadow{Radius: radius, Offset: offset, Opacity: opacity}
+	})
+}
+
+// WithSize sets the width and height of the image in pixels.
+func WithSize(size int) Option {
+	return optionFunc(func(o *Options) {
+		o.Size = size
+	})
+}
+
+// WithPadding leaves the given number of pixels between the monster and the edges.
+func WithPadding(pixels int) Option {
+	return optionFunc(func(o *Options) {
+		o.Padding = pixels
+		o.PaddingPct = 0
+	})
+}
+
+// WithPaddingPercent leaves a percentage of the size between the monster and the edges.
+func WithPaddingPercent(pct float64) Option {
+	return optionFunc(func(o *Options) {
+		o.Padding = 0
+		o.PaddingPct = pct
+	})
+}
+
+// WithFilter sets the resampling filter used when scaling the artwork.
+func WithFilter(f Filter) Option {
+	return optionFunc(func(o *Options) {
+		o.Filter = f
+	})
+}
+
+// WithOutline traces a sticker-style outline of the given width and color
+// around the monster, white if c is zero.
+func WithOutline(width int, c color.RGBA) Option {
+	return optionFunc(func(o *Options) {
+		o.Outline = Outline{Width: width, Color: c}
+	})
+}
+
+// WithPixelArt draws the monster as retro pixel art on a grid x grid canvas
+// with at most colors colors, scaled up without smoothing.
+func WithPixelArt(grid, colors int) Option {
+	return optionFunc(func(o *Options) {
+		o.Pixelate = grid
+		o.PixelColors = colors
+	})
+}
+
+// WithTrim crops the image to the bounding box of its non-transparent pixels,
+// centering it on a transparent square canvas if square is set.
+func WithTrim(square bool) Option {
+	return optionFunc(func(o *Options) {
+		o.TrimTransparent = true
+		o.TrimSquare = square
+	})
+}
+
+// WithOutput selects the concrete type of the returned image.
+func WithOutput(t OutputType) Option {
+	return optionFunc(func(o *Options) {
+		o.Output = t
+	})
+}
+
+// WithColors quantizes the image to a paletted image of at most n colors,
+// which encodes to a much smaller PNG.
+func WithColors(n int) Option {
+	return optionFunc(func(o *Options) {
+		o.Colors = n
+	})
+}
+
+// WithCompression sets the PNG compression level used by the encoders.
+func WithCompression(level png.CompressionLevel) Option {
+	return optionFunc(func(o *Options) {
+		o.Compression = level
+	})
+}
+
+// WithParallelism prepares up to n parts concurrently when rendering at
+// parallelSize or larger, where colorizing them dominates.
+func WithParallelism(n int) Option {
+	return optionFunc(func(o *Options) {
+		o.Parallelism = n
+	})
+}
+
+// WithAccessories gives some monsters a hash-derived hat, glasses or bow tie.
+func WithAccessories() Option {
+	return optionFunc(func(o *Options) {
+		o.Accessories = true
+	})
+}
+
+// WithAccessory always draws the accessory a, such as a badge for moderators.
+func WithAccessory(a Accessory) Option {
+	return optionFunc(func(o *Options) {
+		o.Accessory = a
+	})
+}
+
+// WithSeasons draws the overlay of the first season containing today, or the
+// date set with WithDate.
+func WithSeasons(seasons ...Season) Option {
+	return optionFunc(func(o *Options) {
+		o.Seasons = seasons
+	})
+}
+
+// WithDate sets the day selecting the season of WithSeasons.
+func WithDate(t time.Time) Option {
+	return optionFunc(func(o *Options) {
+		o.Date = t
+	})
+}
+
+// WithExclude leaves the given part categories out of the monster, such as
+// "hair" and "arms" for a minimalist style. The other parts stay the same.
+func WithExclude(parts ...string) Option {
+	return optionFunc(func(o *Options) {
+		o.Exclude = parts
+	})
+}
+
+// WithTheme selects one of the built-in sets of part artwork.
+func WithTheme(t Theme) Option {
+	return optionFunc(func(o *Options) {
+		o.Theme = t
+	})
+}
+
+// WithStyle selects the kind of avatar, such as StyleIdenticon.
+func WithStyle(s Style) Option {
+	return optionFunc(func(o *Options) {
+		o.Style = s
+	})
+}
+
+// WithInitials sets the name whose initials StyleInitials draws, such as AL
+// for "Ada Lovelace" or an email address.
+func WithInitials(name string) Option {
+	return optionFunc(func(o *Options) {
+		o.Initials = name
+	})
+}
+
+// WithMetadata embeds the algorithm version, parts and colors in the PNG
+// files written by the encoders.
+func WithMetadata() Option {
+	return optionFunc(func(o *Options) {
+		o.Metadata = true
+	})
+}
+
+// WithHashFunc seeds generation with a custom hash of the input.
+func WithHashFunc(f HashFunc) Option {
+	return optionFunc(func(o *Options) {
+		o.HashFunc = f
+	})
+}
+
+// WithAlgorithmVersion selects the revision of the generation algorithm.
+func WithAlgorithmVersion(v Version) Option {
+	return optionFunc(func(o *Options) {
+		o.AlgorithmVersion = v
+	})
+}
+
+// WithJitter slightly moves and rotates arms, legs and hair based on the hash.
+func WithJitter() Option {
+	return optionFunc(func(o *Options) {
+		o.Jitter = true
+	})
+}
+
+// WithHue fixes the body hue (0.0-1.0), allowing a hash-derived deviation of
+// up to tolerance in either direction.
+func WithHue(hue, tolerance float64) Option {
+	return optionFunc(func(o *Options) {
+		o.Hue = &hue
+		o.HueTolerance = tolerance
+	})
+}
+
+// WithSaturationRange sets the range the body saturation is picked from.
+func WithSaturationRange(lo, hi float64) Option {
+	return optionFunc(func(o *Options) {
+		o.SaturationRange = [2]float64{lo, hi}
+	})
+}
+
+// WithLightnessShift lightens (positive) or darkens (negative) colorized parts.
+func WithLightnessShift(shift float64) Option {
+	return optionFunc(func(o *Options) {
+		o.LightnessShift = shift
+	})
+}
+
+// WithMinContrast darkens or lightens the body until it has at least the given
+// WCAG contrast ratio against the background color, such as 3 for graphics.
+func WithMinContrast(ratio float64) Option {
+	return optionFunc(func(o *Options) {
+		o.MinContrast = ratio
+	})
+}
+
+// WithPalette restricts generated colors to the closest entries of palette.
+func WithPalette(palette ...color.Color) Option {
+	return optionFunc(func(o *Options) {
+		o.Palette = palette
+	})
+}
+
+// Helper to resolve options on top of the defaults
+func buildOptions(opts []Option) Options {
+	// Options are applied through a reused pointer, which escapes
+	po := optionsPool.Get().(*Options)
+	*po = DefaultOptions()
+	for _, opt := range opts {
+		opt.apply(po)
+	}
+	o := *po
+	*po = Options{}
+	optionsPool.Put(po)
+
+	return o
+}
+
+// optionsPool reuses the Options that buildOptions applies options to.
+var optionsPool = sync.Pool{New: func() any { return new(Options) }}
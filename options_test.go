@@ -0,0 +1,88 @@
+package monsterid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestBuildOptionsDefaults(t *testing.T) {
+	if got := buildOptions(nil); !reflect.DeepEqual(got, DefaultOptions()) {
+		t.Errorf("Expected default options, got %+v", got)
+	}
+}
+
+func TestFunctionalOptions(t *testing.T) {
+	bg := color.RGBA{R: 10, G: 20, B: 30, A: 255}
+	got := buildOptions([]Option{WithBackground(bg), WithGreyscale(), WithSize(256)})
+
+	want := DefaultOptions()
+	want.Background = bg
+	want.Greyscale = true
+	want.Size = 256
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestOptionsValueReplacesDefaults(t *testing.T) {
+	got := buildOptions([]Option{Options{Greyscale: true}, WithSize(64)})
+
+	want := Options{Greyscale: true, Size: 64}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestFunctionalOptionsMatchStruct(t *testing.T) {
+	hash := []byte("functional-options")
+
+	opts := DefaultOptions()
+	opts.Background = color.RGBA{}
+
+	img1 := New(hash, opts).(*image.RGBA)
+	img2 := New(hash, WithTransparentBackground()).(*image.RGBA)
+
+	if !bytes.Equal(img1.Pix, img2.Pix) {
+		t.Error("Functional options and Options struct produced different images")
+	}
+}
+
+func TestWithHashFunc(t *testing.T) {
+	hash := []byte("hash-func")
+
+	fnvImg := New(hash, WithHashFunc(Hash64(fnv.New64a))).(*image.RGBA)
+	if !bytes.Equal(fnvImg.Pix, New(hash).(*image.RGBA).Pix) {
+		t.Error("Explicit FNV-64a hash func did not match the default")
+	}
+
+	sha := func(data []byte) uint64 {
+		sum := sha256.Sum256(data)
+		return binary.BigEndian.Uint64(sum[:8])
+	}
+	img1 := New(hash, WithHashFunc(sha)).(*image.RGBA)
+	img2 := New(hash, WithHashFunc(sha)).(*image.RGBA)
+	if !bytes.Equal(img1.Pix, img2.Pix) {
+		t.Error("Custom hash func is not deterministic")
+	}
+	if bytes.Equal(img1.Pix, fnvImg.Pix) {
+		t.Error("Custom hash func produced the same image as FNV-64a")
+	}
+}
+
+func TestNewAcceptsOptionsValue(t *testing.T) {
+	// Callers of New from before functional options pass an Options value
+	hash := []byte("options-compat")
+	old := DefaultOptions()
+	old.Size = 64
+	got := New(hash, old).(*image.RGBA)
+	if want := New(hash, WithSize(64)).(*image.RGBA); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("Expected an Options value to configure New like the With helpers")
+	}
+}
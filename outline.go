@@ -0,0 +1,64 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Outline is a sticker-style outline traced around the monster's silhouette.
+type Outline struct {
+	Width int        // width in pixels, no outline if zero
+	Color color.RGBA // color of the outline (white if zero)
+}
+
+// Helper to draw the outline of layer positioned at at into dst, clipped to rect
+func drawOutline(dst draw.Image, rect image.Rectangle, at image.Point, layer *image.RGBA, o Outline) {
+	w := o.Width
+	lb := layer.Bounds()
+
+	// Offsets within the outline's radius and their anti-aliased coverage
+	type offset struct {
+		dx, dy int
+		cover  float64
+	}
+	var disk []offset
+	for dy := -w; dy <= w; dy++ {
+		for dx := -w; dx <= w; dx++ {
+			cover := math.Min(1, float64(w)+0.5-math.Hypot(float64(dx), float64(dy)))
+			if cover > 0 {
+				disk = append(disk, offset{dx, dy, cover})
+			}
+		}
+	}
+
+	// Dilate the alpha channel onto a mask that extends w pixels past the layer
+	mask := image.NewAlpha(lb.Inset(-w))
+	mb := mask.Bounds()
+	for y := mb.Min.Y; y < mb.Max.Y; y++ {
+		for x := mb.Min.X; x < mb.Max.X; x++ {
+			a := 0.0
+			for _, off := range disk {
+				sx, sy := x+off.dx, y+off.dy
+				if !(image.Point{X: sx, Y: sy}.In(lb)) {
+					continue
+				}
+				a = math.Max(a, float64(layer.Pix[layer.PixOffset(sx, sy)+3])*off.cover)
+				if a >= 255 {
+					break
+				}
+			}
+			mask.Pix[mask.PixOffset(x, y)] = clampUint8(a)
+		}
+	}
+
+	c := o.Color
+	if c == (color.RGBA{}) {
+		c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+
+	origin := at.Sub(lb.Min)
+	target := mb.Add(origin).Intersect(rect)
+	draw.DrawMask(dst, target, &image.Uniform{C: c}, image.Point{}, mask, target.Min.Sub(origin), draw.Over)
+}
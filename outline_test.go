@@ -0,0 +1,62 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOutlineSurroundsMonster(t *testing.T) {
+	hash := []byte("outline-test")
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	plain := New(hash, WithTransparentBackground()).(*image.RGBA)
+	outlined := New(hash, WithTransparentBackground(), WithOutline(3, color.RGBA{})).(*image.RGBA)
+
+	added := 0
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			if plain.RGBAAt(x, y).A != 0 {
+				continue
+			}
+			c := outlined.RGBAAt(x, y)
+			if c.A == 0 {
+				continue
+			}
+			added++
+			if c.R != c.A || c.G != c.A || c.B != c.A {
+				t.Fatalf("Outline pixel (%d,%d) is not white: %v", x, y, c)
+			}
+		}
+	}
+	if added == 0 {
+		t.Fatal("Outline did not add any pixels")
+	}
+
+	// Pixels next to the monster are fully covered
+	for y := 1; y < nativeSize-1; y++ {
+		for x := 1; x < nativeSize-1; x++ {
+			if plain.RGBAAt(x, y).A == 255 && plain.RGBAAt(x+1, y).A == 0 {
+				if c := outlined.RGBAAt(x+1, y); c != white {
+					t.Fatalf("Expected white next to the monster at (%d,%d), got %v", x+1, y, c)
+				}
+			}
+		}
+	}
+}
+
+func TestOutlineColor(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	img := New([]byte("outline-color"), WithTransparentBackground(), WithOutline(4, red)).(*image.RGBA)
+
+	found := false
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] == 255 && img.Pix[i+1] == 0 && img.Pix[i+2] == 0 && img.Pix[i+3] == 255 {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Expected red outline pixels")
+	}
+}
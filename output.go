@@ -0,0 +1,33 @@
+package monsterid
+
+import (
+	"image"
+	"image/draw"
+)
+
+// OutputType selects the concrete type of the generated image.
+type OutputType int
+
+const (
+	OutputRGBA     OutputType = iota // *image.RGBA, premultiplied alpha
+	OutputNRGBA                      // *image.NRGBA, non-premultiplied alpha
+	OutputPaletted                   // *image.Paletted with at most Options.Colors colors
+)
+
+// Helper to convert the rendered image to the requested output type
+func convertOutput(img *image.RGBA, o Options) image.Image {
+	switch {
+	case o.Output == OutputPaletted || o.Colors > 0:
+		n := o.Colors
+		if n <= 0 {
+			n = 256
+		}
+		return Quantize(img, n)
+	case o.Output == OutputNRGBA:
+		dst := image.NewNRGBA(img.Bounds())
+		draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+		return dst
+	}
+
+	return img
+}
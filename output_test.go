@@ -0,0 +1,42 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOutputTypes(t *testing.T) {
+	hash := []byte("output-test")
+
+	if _, ok := New(hash).(*image.RGBA); !ok {
+		t.Error("Expected *image.RGBA by default")
+	}
+	if _, ok := New(hash, WithOutput(OutputNRGBA)).(*image.NRGBA); !ok {
+		t.Error("Expected *image.NRGBA for OutputNRGBA")
+	}
+
+	img, ok := New(hash, WithOutput(OutputPaletted)).(*image.Paletted)
+	if !ok {
+		t.Fatal("Expected *image.Paletted for OutputPaletted")
+	}
+	if len(img.Palette) > 256 {
+		t.Errorf("Expected at most 256 colors, got %d", len(img.Palette))
+	}
+}
+
+func TestNRGBAOutputIsNotPremultiplied(t *testing.T) {
+	hash := []byte("output-nrgba")
+
+	rgba := New(hash, WithTransparentBackground(), WithShadow(4, image.Pt(2, 2), 0.5)).(*image.RGBA)
+	nrgba := New(hash, WithTransparentBackground(), WithShadow(4, image.Pt(2, 2), 0.5), WithOutput(OutputNRGBA)).(*image.NRGBA)
+
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			want := color.NRGBAModel.Convert(rgba.RGBAAt(x, y)).(color.NRGBA)
+			if got := nrgba.NRGBAAt(x, y); got != want {
+				t.Fatalf("Pixel (%d,%d): expected %v, got %v", x, y, want, got)
+			}
+		}
+	}
+}
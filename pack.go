@@ -0,0 +1,181 @@
+package monsterid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// manifestName is the optional file at the root of a part pack that lists
+// how many parts it has of each category.
+const manifestName = "manifest.json"
+
+// manifest describes a part pack, such as
+//
+//	{"parts": {"legs": 5, "hair": 5, "arms": 5, "body": 15, "eyes": 15, "mouth": 10}}
+type manifest struct {
+	Parts    map[string]int `json:"parts"`    // number of parts per category
+	Colorize colorRules     `json:"colorize"` // colorization per category, the default rules if omitted
+}
+
+// pack is a set of part artwork with the rules to select and colorize it.
+type pack struct {
+	load      partLoader  // loads a part file at a scale
+	counts    partCounts  // number of parts per category
+	colors    colorRules  // colorization per category, nil for the default rules
+	colorized *colorCache // parts colorized with quantized colors, nil to colorize each render
+}
+
+// partCounts is the number of parts per category.
+type partCounts map[string]int
+
+// Helper to get the number of parts of a category
+func (c partCounts) count(part string) int {
+	return c[part]
+}
+
+// Helper to get the part counts and colorization rules of a pack from its
+// manifest, or count the part files named <category>_<n>.png if it has none.
+// The loader of the returned pack is left for the caller to set.
+func readPack(fsys fs.FS) (pack, error) {
+	data, err := fs.ReadFile(fsys, manifestName)
+	if errors.Is(err, fs.ErrNotExist) {
+		counts, err := countPartFiles(fsys)
+		return pack{counts: counts}, err
+	}
+	if err != nil {
+		return pack{}, fmt.Errorf("monsterid: read %s: %w", manifestName, err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return pack{}, fmt.Errorf("monsterid: parse %s: %w", manifestName, err)
+	}
+	for _, part := range bodyParts {
+		if m.Parts[part] < 1 {
+			return pack{}, fmt.Errorf("monsterid: %s declares no %s parts", manifestName, part)
+		}
+	}
+	if err := m.Colorize.validate(); err != nil {
+		return pack{}, err
+	}
+
+	return pack{counts: m.Parts, colors: m.Colorize}, nil
+}
+
+// Helper to count the consecutive part files of each category
+func countPartFiles(fsys fs.FS) (partCounts, error) {
+	counts := make(partCounts, len(bodyParts))
+	for _, part := range bodyParts {
+		for {
+			fileName := fmt.Sprintf("%s_%d", part, counts[part]+1)
+			if !partFileExists(fsys, fileName) {
+				break
+			}
+			counts[part]++
+		}
+		if counts[part] == 0 {
+			return nil, fmt.Errorf("monsterid: no %s parts, expected %s_1.png or .svg", part, part)
+		}
+	}
+
+	return counts, nil
+}
+
+// ValidatePack checks a part pack for NewGeneratorFromFS: that its manifest
+// is valid, and that every declared part exists, decodes as PNG and has the
+// size of its resolution and a transparent background. Parts left out of the
+// manifest are reported too. All problems are returned together, joined with
+// errors.Join.
+func ValidatePack(fsys fs.FS) error {
+	p, err := readPack(fsys)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for part := range p.counts {
+		if !slices.Contains(bodyParts, part) {
+			errs = append(errs, fmt.Errorf("monsterid: %s declares unknown category %q", manifestName, part))
+		}
+	}
+
+	for _, part := range bodyParts {
+		n := p.counts.count(part)
+		for i := 1; i <= n; i++ {
+			for _, scale := range partScales {
+				img, err := decodePackPart(fsys, partFileName(part, i), scale)
+				if scale > 1 && errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if img.Opaque() {
+					errs = append(errs, fmt.Errorf("monsterid: part %s has no transparent pixels", scaledPartPath(partFileName(part, i), scale)))
+				}
+				if _, err := decodePackPart(fsys, maskName(partFileName(part, i)), scale); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					errs = append(errs, err)
+				}
+			}
+		}
+
+		if fileName := fmt.Sprintf("%s_%d", part, n+1); partFileExists(fsys, fileName) {
+			errs = append(errs, fmt.Errorf("monsterid: part %s is not declared in %s", fileName, manifestName))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Helper to decode a part of a pack at a scale, or rasterize its SVG file,
+// and check its size
+func decodePackPart(fsys fs.FS, fileName string, scale int) (*image.RGBA, error) {
+	svgName := fileName
+	fileName = scaledPartPath(fileName, scale)
+	img, err := decodePart(fsys, fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		if svg, svgErr := decodeSVGPart(fsys, svgName, scale); !errors.Is(svgErr, fs.ErrNotExist) {
+			img, err, fileName = svg, svgErr, strings.TrimSuffix(svgName, ".png")+".svg"
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: load part %s: %w", fileName, err)
+	}
+
+	size := nativeSize * scale
+	if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+		return nil, fmt.Errorf("monsterid: part %s is %dx%d, want %dx%d", fileName, b.Dx(), b.Dy(), size, size)
+	}
+
+	return img, nil
+}
+
+// Helper to check whether a part exists as PNG or SVG file, by its name
+// without extension
+func partFileExists(fsys fs.FS, name string) bool {
+	for _, ext := range []string{".png", ".svg"} {
+		if _, err := fs.Stat(fsys, name+ext); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Helper to check whether a part file exists at a scale, as PNG or as SVG
+// rasterized at every scale
+func packFileExists(fsys fs.FS, fileName string, scale int) bool {
+	for _, name := range []string{scaledPartPath(fileName, scale), strings.TrimSuffix(fileName, ".png") + ".svg"} {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Helper to build a pack with the first n embedded parts of every category
+func testPack(t *testing.T, n int) fstest.MapFS {
+	t.Helper()
+
+	fsys := fstest.MapFS{}
+	for _, part := range bodyParts {
+		for i := 1; i <= n; i++ {
+			name := fmt.Sprintf("%s_%d.png", part, i)
+			data, err := fs.ReadFile(parts, "parts/"+name)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", name, err)
+			}
+			fsys[name] = &fstest.MapFile{Data: data}
+		}
+	}
+
+	return fsys
+}
+
+func TestReadPackFromFiles(t *testing.T) {
+	p, err := readPack(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counts := p.counts
+
+	want := partCounts{"legs": 2, "hair": 2, "arms": 2, "body": 2, "eyes": 2, "mouth": 2}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("Expected %v, got %v", want, counts)
+	}
+}
+
+func TestReadPackFromManifest(t *testing.T) {
+	fsys := testPack(t, 3)
+	fsys[manifestName] = &fstest.MapFile{Data: []byte(`{"parts": {"legs": 1, "hair": 2, "arms": 3, "body": 1, "eyes": 2, "mouth": 3}}`)}
+
+	p, err := readPack(fsys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counts := p.counts
+	if counts.count("arms") != 3 || counts.count("legs") != 1 {
+		t.Errorf("Expected the counts of the manifest, got %v", counts)
+	}
+}
+
+func TestReadPackErrors(t *testing.T) {
+	missing := testPack(t, 1)
+	delete(missing, "eyes_1.png")
+
+	badManifest := testPack(t, 1)
+	badManifest[manifestName] = &fstest.MapFile{Data: []byte(`{"parts": [`)}
+
+	incomplete := testPack(t, 1)
+	incomplete[manifestName] = &fstest.MapFile{Data: []byte(`{"parts": {"legs": 1}}`)}
+
+	tests := []struct {
+		description string
+		fsys        fs.FS
+	}{
+		{"missing category", missing},
+		{"invalid manifest", badManifest},
+		{"incomplete manifest", incomplete},
+	}
+
+	for _, test := range tests {
+		if _, err := readPack(test.fsys); err == nil {
+			t.Errorf("Expected an error for %s", test.description)
+		}
+	}
+}
+
+func TestEmbeddedPartCounts(t *testing.T) {
+	sub, _ := fs.Sub(parts, "parts")
+	p, err := readPack(sub)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counts, classic := p.counts, ThemeClassic.pack().counts
+
+	for _, part := range bodyParts {
+		if counts.count(part) != classic.count(part) {
+			t.Errorf("Expected %d %s parts, found %d", classic.count(part), part, counts.count(part))
+		}
+	}
+}
+
+func TestValidatePack(t *testing.T) {
+	if err := ValidatePack(testPack(t, 2)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, theme := range []Theme{ThemeRobot, ThemeCute} {
+		sub, _ := fs.Sub(parts, "parts/"+string(theme))
+		if err := ValidatePack(sub); err != nil {
+			t.Errorf("Unexpected error for %s: %v", theme, err)
+		}
+	}
+}
+
+func TestValidatePackErrors(t *testing.T) {
+	encode := func(img image.Image) []byte {
+		buf := new(bytes.Buffer)
+		png.Encode(buf, img)
+		return buf.Bytes()
+	}
+	opaque := image.NewRGBA(image.Rect(0, 0, nativeSize, nativeSize))
+	draw.Draw(opaque, opaque.Bounds(), image.White, image.Point{}, draw.Src)
+
+	manifest := []byte(`{"parts": {"legs": 2, "hair": 1, "arms": 1, "body": 1, "eyes": 1, "mouth": 1, "tail": 1}}`)
+	fsys := testPack(t, 2)
+	fsys[manifestName] = &fstest.MapFile{Data: manifest}
+	delete(fsys, "legs_2.png")
+	fsys["body_1.png"] = &fstest.MapFile{Data: encode(opaque)}
+	fsys["eyes_1.png"] = &fstest.MapFile{Data: []byte("not a png")}
+	fsys["@2x/arms_1.png"] = &fstest.MapFile{Data: encode(image.NewRGBA(image.Rect(0, 0, 64, 64)))}
+
+	err := ValidatePack(fsys)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	// Every problem is reported at once
+	for _, want := range []string{"tail", "legs_2.png", "body_1.png", "eyes_1.png", "@2x/arms_1.png", "hair_2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %s in the error, got %v", want, err)
+		}
+	}
+}
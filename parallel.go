@@ -0,0 +1,59 @@
+package monsterid
+
+import (
+	"context"
+	"image"
+	"sync"
+)
+
+// parallelSize is the smallest size parts are prepared concurrently at with
+// Options.Parallelism, below it goroutines cost more than they save.
+const parallelSize = 512
+
+// Helper to prepare the parts that aren't excluded concurrently, by up to
+// o.Parallelism at a time, in the order of bodyParts
+func prepareParts(ctx context.Context, d Descriptor, o Options, shift float64, p pack, scale int, s *scratch) ([]*image.RGBA, error) {
+	var parts []string
+	for _, part := range bodyParts {
+		if !o.excluded(part) {
+			parts = append(parts, part)
+		}
+	}
+
+	// Each part copies into its own scratch, which isn't safe for concurrent use
+	prepared := make([]*image.RGBA, len(parts))
+	copies := make([]*scratch, len(parts))
+	errs := make([]error, len(parts))
+	sem := make(chan struct{}, o.Parallelism)
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if errs[i] = ctx.Err(); errs[i] != nil {
+				return
+			}
+			copies[i] = newScratch()
+			prepared[i], errs[i] = preparePart(d, o, part, shift, p, scale, copies[i])
+		}()
+	}
+	wg.Wait()
+
+	// The copies are released with the rest of the render
+	for _, c := range copies {
+		if c != nil {
+			s.adopt(c)
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prepared, nil
+}
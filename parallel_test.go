@@ -0,0 +1,70 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"testing"
+)
+
+func TestParallelism(t *testing.T) {
+	opts := [][]Option{
+		{WithSize(512)},
+		{WithSize(512), WithAlgorithmVersion(V2), WithJitter()},
+		{WithSize(640), WithGreyscale(), WithExclude("hair")},
+		{WithSize(512), WithExclude(bodyParts...)},
+	}
+
+	for _, opt := range opts {
+		for i := 0; i < 5; i++ {
+			hash := []byte(fmt.Sprintf("parallel-%d", i))
+			want := New(hash, opt...).(*image.RGBA)
+			for _, n := range []int{2, 3, 6} {
+				got := New(hash, append(opt, WithParallelism(n))...).(*image.RGBA)
+				if !bytes.Equal(got.Pix, want.Pix) {
+					t.Errorf("Expected %q to render the same with a parallelism of %d", hash, n)
+				}
+			}
+		}
+	}
+}
+
+func TestParallelismErrors(t *testing.T) {
+	broken := testPack(t, 1)
+	broken["eyes_1.png"].Data = []byte("not a png")
+	g, err := NewGeneratorWithConfig(GeneratorConfig{Packs: []fs.FS{broken}, CacheBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if _, err := g.Generate([]byte("broken"), WithSize(512), WithParallelism(4)); err == nil {
+		t.Error("Expected an error for a broken part")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewContext(ctx, []byte("canceled"), WithSize(512), WithParallelism(4)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func BenchmarkParallelism(b *testing.B) {
+	g, err := NewGenerator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, n := range []int{1, 6} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				img, err := g.Generate([]byte("benchmark"), WithSize(1024), WithParallelism(n))
+				if err != nil {
+					b.Fatal(err)
+				}
+				ReleaseImage(img)
+			}
+		})
+	}
+}
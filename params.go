@@ -0,0 +1,130 @@
+package monsterid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Query parameters Handler maps to options when listed in
+// HandlerConfig.Params.
+const (
+	ParamTheme      = "theme" // built-in theme, such as robot
+	ParamStyle      = "style" // built-in style, such as identicon
+	ParamBackground = "bg"    // background color parsed by ParseColor, such as 00000000
+	ParamShape      = "shape" // square, circle or rounded
+	ParamGreyscale  = "grey"  // 1 or true for greyscale, 0 or false for color
+	ParamName       = "name"  // name whose initials StyleInitials draws, such as Ada Lovelace
+)
+
+// shapeNames are the values of ParamShape.
+var shapeNames = map[string]Shape{"square": ShapeSquare, "circle": ShapeCircle, "rounded": ShapeRounded}
+
+// paramParsers validate the value of each query parameter, returning the
+// option it sets and the value in canonical form.
+var paramParsers = map[string]func(v string) (Option, string, error){
+	ParamTheme: func(v string) (Option, string, error) {
+		t := Theme(strings.ToLower(v))
+		if !slices.Contains(Themes(), t) {
+			return nil, "", fmt.Errorf("unknown theme %q", v)
+		}
+		return WithTheme(t), string(t), nil
+	},
+	ParamStyle: func(v string) (Option, string, error) {
+		s := Style(strings.ToLower(v))
+		if !isStyle(s) {
+			return nil, "", fmt.Errorf("unknown style %q", v)
+		}
+		return WithStyle(s), string(s), nil
+	},
+	ParamBackground: func(v string) (Option, string, error) {
+		c, err := ParseColor(v)
+		if err != nil {
+			return nil, "", err
+		}
+		return WithBackground(c), strings.ToLower(strings.TrimPrefix(v, "#")), nil
+	},
+	ParamShape: func(v string) (Option, string, error) {
+		shape, ok := shapeNames[strings.ToLower(v)]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown shape %q", v)
+		}
+		return WithShape(shape), strings.ToLower(v), nil
+	},
+	ParamGreyscale: func(v string) (Option, string, error) {
+		grey, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid grey %q", v)
+		}
+		return withGreyscale(grey), strconv.FormatBool(grey), nil
+	},
+	// Only the initials are drawn, so names with the same initials share
+	// the cache
+	ParamName: func(v string) (Option, string, error) {
+		return WithInitials(v), url.QueryEscape(initialsOf(v)), nil
+	},
+}
+
+// Helper to turn greyscale on or off, overriding a greyscale tone
+func withGreyscale(grey bool) Option {
+	return optionFunc(func(o *Options) {
+		o.Greyscale = grey
+		if !grey && o.Tone == ToneGreyscale {
+			o.Tone = ToneNone
+		}
+	})
+}
+
+// ParseColor parses a hex RRGGBB or RRGGBBAA color with an optional leading
+// #, such as #ff8800 or 00000000, as accepted for backgrounds by Handler, the
+// command and the wasm module.
+func ParseColor(v string) (color.RGBA, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(v, "#"))
+	if err != nil || (len(b) != 3 && len(b) != 4) {
+		return color.RGBA{}, fmt.Errorf("monsterid: invalid color %q", v)
+	}
+	c := color.NRGBA{R: b[0], G: b[1], B: b[2], A: 0xff}
+	if len(b) == 4 {
+		c.A = b[3]
+	}
+
+	return color.RGBAModel.Convert(c).(color.RGBA), nil
+}
+
+// ValidateParams checks that params only lists query parameters supported by
+// HandlerConfig.Params, such as ParamTheme.
+func ValidateParams(params []string) error {
+	for _, name := range params {
+		if _, ok := paramParsers[name]; !ok {
+			return fmt.Errorf("monsterid: unknown query parameter %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Helper to map the allowed query parameters of a request to options,
+// returning them with a canonical form of their values
+func paramOptions(query url.Values, params []string) ([]Option, string, error) {
+	var opts []Option
+	var canonical []string
+	for _, name := range params {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		opt, value, err := paramParsers[name](v)
+		if err != nil {
+			return nil, "", err
+		}
+		opts = append(opts, opt)
+		canonical = append(canonical, name+"="+value)
+	}
+	slices.Sort(canonical)
+
+	return opts, strings.Join(canonical, "&"), nil
+}
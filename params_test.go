@@ -0,0 +1,150 @@
+package monsterid
+
+import (
+	"bytes"
+	"image/color"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandlerParams(t *testing.T) {
+	all := []string{ParamTheme, ParamBackground, ParamShape, ParamGreyscale}
+	h := Handler(HandlerConfig{Params: all, Options: []Option{WithSize(64)}})
+	tests := []struct {
+		target string
+		hash   string
+		opts   []Option
+	}{
+		{"/alice?theme=robot", "alice", []Option{WithTheme(ThemeRobot)}},
+		{"/alice?bg=ff000080", "alice", []Option{WithBackground(color.RGBA{R: 0x80, A: 0x80})}},
+		{"/alice?bg=00FF00", "alice", []Option{WithBackground(color.RGBA{G: 0xff, A: 0xff})}},
+		{"/alice?shape=circle&grey=1", "alice", []Option{WithShape(ShapeCircle), WithGreyscale()}},
+		{"/alice?theme=cute&bg=00000000&shape=rounded&grey=true", "alice", []Option{WithTheme(ThemeCute), WithBackground(color.RGBA{}), WithShape(ShapeRounded), WithGreyscale()}},
+		{"/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?s=64&shape=circle", "0bc83cb571cd1c50ba6f3e8a78ef1346", []Option{WithShape(ShapeCircle)}},
+	}
+
+	for _, test := range tests {
+		rec := serve(h, http.MethodGet, test.target)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected %s to be served, got %d: %s", test.target, rec.Code, rec.Body)
+		}
+
+		want := new(bytes.Buffer)
+		if err := Render(want, []byte(test.hash), FormatPNG, append([]Option{WithSize(64)}, test.opts...)...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+			t.Errorf("Expected %s to apply its parameters", test.target)
+		}
+	}
+
+	// The parameters are part of the ETag
+	plain := serve(h, http.MethodGet, "/alice").Header().Get("ETag")
+	if serve(h, http.MethodGet, "/alice?shape=circle").Header().Get("ETag") == plain {
+		t.Error("Expected the parameters to change the ETag")
+	}
+	if serve(h, http.MethodGet, "/alice?grey=1").Header().Get("ETag") != serve(h, http.MethodGet, "/alice?grey=true").Header().Get("ETag") {
+		t.Error("Expected equivalent parameters to share an ETag")
+	}
+}
+
+func TestHandlerParamsAllowed(t *testing.T) {
+	// Parameters that aren't allowed are ignored
+	h := Handler(HandlerConfig{Params: []string{ParamShape}})
+	want := serve(h, http.MethodGet, "/alice").Body.Bytes()
+	if got := serve(h, http.MethodGet, "/alice?grey=1&theme=robot").Body.Bytes(); !bytes.Equal(got, want) {
+		t.Error("Expected parameters that aren't allowed to be ignored")
+	}
+
+	// Greyscale is turned off over a greyscale default
+	h = Handler(HandlerConfig{Params: []string{ParamGreyscale}, Options: []Option{WithTone(ToneGreyscale)}})
+	if got := serve(h, http.MethodGet, "/alice?grey=0").Body.Bytes(); !bytes.Equal(got, want) {
+		t.Error("Expected grey=0 to render in color")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Handler to panic for an unknown parameter")
+		}
+	}()
+	Handler(HandlerConfig{Params: []string{"size"}})
+}
+
+func TestHandlerParamsErrors(t *testing.T) {
+	h := Handler(HandlerConfig{Params: []string{ParamTheme, ParamBackground, ParamShape, ParamGreyscale}})
+	for _, target := range []string{
+		"/alice?theme=space",
+		"/alice?bg=red",
+		"/alice?bg=fff",
+		"/alice?bg=ff00ff00ff",
+		"/alice?shape=star",
+		"/alice?grey=maybe",
+		"/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?shape=star",
+	} {
+		if rec := serve(h, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected %s to be rejected, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	all := []string{ParamTheme, ParamStyle, ParamBackground, ParamShape, ParamGreyscale, ParamName}
+	if err := ValidateParams(all); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := ValidateParams([]string{ParamTheme, "size"}); err == nil || !strings.Contains(err.Error(), "size") {
+		t.Errorf("Expected an error for size, got %v", err)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		v    string
+		want color.RGBA
+		ok   bool
+	}{
+		{"ff8800", color.RGBA{R: 0xff, G: 0x88, A: 0xff}, true},
+		{"#FF8800", color.RGBA{R: 0xff, G: 0x88, A: 0xff}, true},
+		{"#00000000", color.RGBA{}, true},
+		{"ff000080", color.RGBA{R: 0x80, A: 0x80}, true},
+		{"fff", color.RGBA{}, false},
+		{"##ff8800", color.RGBA{}, false},
+		{"red", color.RGBA{}, false},
+	}
+
+	for _, test := range tests {
+		c, err := ParseColor(test.v)
+		if (err == nil) != test.ok {
+			t.Errorf("Expected ok %v for %q, got %v", test.ok, test.v, err)
+			continue
+		}
+		if c != test.want {
+			t.Errorf("Expected %v for %q, got %v", test.want, test.v, c)
+		}
+	}
+
+	// Both forms are the same avatar
+	h := Handler(HandlerConfig{Params: []string{ParamBackground}})
+	if serve(h, http.MethodGet, "/alice?bg=%23FF8800").Header().Get("ETag") != serve(h, http.MethodGet, "/alice?bg=ff8800").Header().Get("ETag") {
+		t.Error("Expected a leading # to share the ETag")
+	}
+}
+
+func TestHandlerParamsGenerator(t *testing.T) {
+	g, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	h := Handler(HandlerConfig{Generator: g, Params: []string{ParamTheme, ParamGreyscale}})
+
+	// The generator draws its own parts, so the theme is ignored
+	plain := serve(h, http.MethodGet, "/alice")
+	robot := serve(h, http.MethodGet, "/alice?theme=robot")
+	if robot.Code != http.StatusOK || robot.Header().Get("ETag") != plain.Header().Get("ETag") || !bytes.Equal(robot.Body.Bytes(), plain.Body.Bytes()) {
+		t.Errorf("Expected the theme to be ignored, got %d", robot.Code)
+	}
+	if rec := serve(h, http.MethodGet, "/alice?grey=1"); rec.Header().Get("ETag") == plain.Header().Get("ETag") {
+		t.Error("Expected other parameters to still apply")
+	}
+}
@@ -0,0 +1,69 @@
+package monsterid
+
+import (
+	"container/list"
+	"image"
+	"sync"
+)
+
+// partCache keeps the most recently used decoded parts of a Generator within
+// a budget of pixel bytes, evicting the least recently used ones.
+type partCache[K comparable] struct {
+	mu      sync.Mutex
+	budget  int64               // maximum size of the cached pixels in bytes
+	size    int64               // current size of the cached pixels in bytes
+	entries map[K]*list.Element // cached parts
+	order   *list.List          // entries from most to least recently used
+}
+
+// partCacheEntry is a cached part.
+type partCacheEntry[K comparable] struct {
+	key K
+	img *image.RGBA
+}
+
+// Helper to create a part cache holding at most budget bytes of pixels
+func newPartCache[K comparable](budget int64) *partCache[K] {
+	return &partCache[K]{budget: budget, entries: make(map[K]*list.Element), order: list.New()}
+}
+
+// Helper to get a cached part, decoding and caching it if it isn't. Parts
+// larger than the whole budget are decoded on every use.
+func (c *partCache[K]) get(key K, decode func() (*image.RGBA, error)) (*image.RGBA, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*partCacheEntry[K]).img, nil
+	}
+	c.mu.Unlock()
+
+	// Decode without holding the lock, so other parts can be used meanwhile
+	img, err := decode()
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(img.Pix))
+	if size > c.budget {
+		return img, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have decoded the same part meanwhile
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*partCacheEntry[K]).img, nil
+	}
+	for c.size+size > c.budget {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*partCacheEntry[K]).key)
+		c.size -= int64(len(oldest.Value.(*partCacheEntry[K]).img.Pix))
+	}
+	c.entries[key] = c.order.PushFront(&partCacheEntry[K]{key: key, img: img})
+	c.size += size
+
+	return img, nil
+}
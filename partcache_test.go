@@ -0,0 +1,113 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPartCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// Room for two 1x1 parts
+	c := newPartCache[partKey](8)
+	decoded := map[string]int{}
+	get := func(key string) {
+		t.Helper()
+		_, err := c.get(partKey{fileName: key}, func() (*image.RGBA, error) {
+			decoded[key]++
+			return image.NewRGBA(image.Rect(0, 0, 1, 1)), nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	for _, key := range []string{"a", "b", "a", "c", "a", "b"} {
+		get(key)
+	}
+
+	want := map[string]int{"a": 1, "b": 2, "c": 1}
+	for key, n := range want {
+		if decoded[key] != n {
+			t.Errorf("Expected %s to be decoded %d times, got %d", key, n, decoded[key])
+		}
+	}
+	if c.size != 8 || c.order.Len() != 2 {
+		t.Errorf("Expected 2 parts of 8 bytes cached, got %d of %d bytes", c.order.Len(), c.size)
+	}
+}
+
+func TestPartCacheSkipsLargeParts(t *testing.T) {
+	c := newPartCache[partKey](8)
+	img, err := c.get(partKey{fileName: "large"}, func() (*image.RGBA, error) {
+		return image.NewRGBA(image.Rect(0, 0, 2, 2)), nil
+	})
+	if err != nil || img == nil {
+		t.Fatalf("Expected the part, got %v", err)
+	}
+	if c.size != 0 {
+		t.Errorf("Expected a part over the budget not to be cached, got %d bytes", c.size)
+	}
+
+	broken := errors.New("broken")
+	if _, err := c.get(partKey{fileName: "broken"}, func() (*image.RGBA, error) { return nil, broken }); err != broken {
+		t.Errorf("Expected %v, got %v", broken, err)
+	}
+}
+
+func TestGeneratorCacheBytes(t *testing.T) {
+	eager, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	// Room for three parts at the native resolution
+	budget := int64(3 * nativeSize * nativeSize * 4)
+	lazy, err := NewGeneratorWithConfig(GeneratorConfig{Packs: []fs.FS{testPack(t, 2)}, CacheBytes: budget})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := lazy.Warmup(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		hash := []byte(fmt.Sprintf("cache-%d", i))
+		want, _ := eager.Generate(hash)
+		got, err := lazy.Generate(hash)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(got.(*image.RGBA).Pix, want.(*image.RGBA).Pix) {
+			t.Errorf("Expected the cached parts to render like the decoded ones for %q", hash)
+		}
+	}
+
+	if c := lazy.set.Load().cache; c.size > budget || c.order.Len() != 3 {
+		t.Errorf("Expected 3 parts within %d bytes, got %d of %d bytes", budget, c.order.Len(), c.size)
+	}
+}
+
+func TestGeneratorCacheBytesErrors(t *testing.T) {
+	// Missing parts are still reported up front
+	missing := testPack(t, 1)
+	missing[manifestName] = &fstest.MapFile{Data: []byte(`{"parts": {"legs": 1, "hair": 1, "arms": 1, "body": 2, "eyes": 1, "mouth": 1}}`)}
+	if _, err := NewGeneratorWithConfig(GeneratorConfig{Packs: []fs.FS{missing}, CacheBytes: 1 << 20}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a missing part error, got %v", err)
+	}
+
+	// Broken parts when they are drawn
+	broken := testPack(t, 1)
+	broken["eyes_1.png"] = &fstest.MapFile{Data: []byte("not a png")}
+	g, err := NewGeneratorWithConfig(GeneratorConfig{Packs: []fs.FS{broken}, CacheBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if _, err := g.Generate([]byte("broken")); err == nil {
+		t.Error("Expected an error for a broken part")
+	}
+}
@@ -0,0 +1,65 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand/v2"
+)
+
+// pixelDir is the embedded directory of the pixel art parts, drawn by
+// gen_parts.go at pixelSize.
+const pixelDir = "parts/pixel"
+
+// pixelSize is the width and height of the pixel art.
+const pixelSize = 32
+
+// pixelParts are the categories of pixel art parts in drawing order.
+var pixelParts = []figurePart{
+	{"legs", 4, true},
+	{"arms", 4, true},
+	{"horns", 5, true},
+	{"body", 6, true},
+	{"eyes", 5, false},
+	{"mouth", 5, false},
+}
+
+// pixelPalette are the body colors of the pixel style, like the restricted
+// palette of an 8-bit console.
+var pixelPalette = color.Palette{
+	color.RGBA{0xe0, 0x3c, 0x28, 0xff},
+	color.RGBA{0xf8, 0x78, 0x58, 0xff},
+	color.RGBA{0xf8, 0xb8, 0x00, 0xff},
+	color.RGBA{0xb8, 0xf8, 0x18, 0xff},
+	color.RGBA{0x00, 0xb8, 0x00, 0xff},
+	color.RGBA{0x58, 0xd8, 0x54, 0xff},
+	color.RGBA{0x00, 0xe8, 0xd8, 0xff},
+	color.RGBA{0x3c, 0xbc, 0xfc, 0xff},
+	color.RGBA{0x00, 0x78, 0xf8, 0xff},
+	color.RGBA{0x68, 0x44, 0xfc, 0xff},
+	color.RGBA{0xd8, 0x00, 0xcc, 0xff},
+	color.RGBA{0xf8, 0x78, 0xf8, 0xff},
+	color.RGBA{0xac, 0x7c, 0x00, 0xff},
+	color.RGBA{0xbc, 0xbc, 0xbc, 0xff},
+}
+
+// Helper to draw an 8-bit monster at pixelSize and scale it up with nearest
+// neighbor sampling. The body color is snapped to pixelPalette, unless there
+// is an Options.Palette or the avatar is greyscale.
+func drawPixel(img *image.RGBA, r *rand.Rand, o Options) error {
+	c := figureColor(r, o, 0.5)
+	if len(o.Palette) == 0 && o.tone() != ToneGreyscale {
+		c = pixelPalette[nearestColor(pixelPalette, c)].(color.RGBA)
+	}
+
+	small := getRGBA(image.Rect(0, 0, pixelSize, pixelSize))
+	defer putRGBA(small)
+	if err := drawFigureParts(small, r, o, pixelDir, pixelParts, c); err != nil {
+		return err
+	}
+
+	scaled := scaleImage(small, img.Rect.Dx(), nearestNeighbor)
+	draw.Draw(img, img.Rect, scaled, image.Point{}, draw.Over)
+
+	return nil
+}
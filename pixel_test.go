@@ -0,0 +1,58 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestPixel(t *testing.T) {
+	const size = 4 * pixelSize
+	img := New([]byte("alice"), WithStyle(StylePixel), WithSize(size), WithTransparentBackground()).(*image.RGBA)
+
+	// Every pixel of the art is a uniform block of 4x4 pixels
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c, block := img.RGBAAt(x, y), img.RGBAAt(x/4*4, y/4*4); c != block {
+				t.Fatalf("Expected a uniform block at %d,%d, got %v and %v", x, y, c, block)
+			}
+		}
+	}
+
+	// A few colors, opaque or transparent
+	colors := map[color.RGBA]bool{}
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+		if c.A != 0 && c.A != 0xff {
+			t.Fatalf("Expected hard edges, got %v", c)
+		}
+		colors[c] = true
+	}
+	if len(colors) > 8 {
+		t.Errorf("Expected a restricted palette, got %d colors", len(colors))
+	}
+}
+
+func TestPixelPalette(t *testing.T) {
+	for _, hash := range []string{"alice", "bob", "carol", "dave"} {
+		img := New([]byte(hash), WithStyle(StylePixel), WithTransparentBackground()).(*image.RGBA)
+		found := false
+		for i := 0; i < len(img.Pix); i += 4 {
+			if slices.Contains(pixelPalette, color.Color(color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]})) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected the body of %s in a palette color", hash)
+		}
+	}
+
+	grey := New([]byte("alice"), WithStyle(StylePixel), WithGreyscale()).(*image.RGBA)
+	for i := 0; i < len(grey.Pix); i += 4 {
+		if c := grey.Pix[i : i+3]; c[0] != c[1] || c[1] != c[2] {
+			t.Fatalf("Expected a grey monster, got %v", c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package monsterid
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Helper to make every pixel either fully opaque or fully transparent
+func hardenAlpha(img *image.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		a := uint32(img.Pix[i+3])
+		if a < 128 {
+			img.Pix[i+0], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 0, 0, 0, 0
+			continue
+		}
+		img.Pix[i+0] = uint8(uint32(img.Pix[i+0]) * 255 / a)
+		img.Pix[i+1] = uint8(uint32(img.Pix[i+1]) * 255 / a)
+		img.Pix[i+2] = uint8(uint32(img.Pix[i+2]) * 255 / a)
+		img.Pix[i+3] = 255
+	}
+}
+
+// Helper to turn the monster layer into pixel art on a grid x grid canvas
+// with at most n colors and hard edges
+func pixelate(layer *image.RGBA, grid, n int) *image.RGBA {
+	small := resizeImage(layer, grid, grid, bilinear)
+	hardenAlpha(small)
+
+	// Restrict the palette, the transparent entry does not count
+	if n <= 0 {
+		n = 16
+	}
+	q := Quantize(small, n+1)
+	draw.Draw(small, small.Bounds(), q, q.Bounds().Min, draw.Src)
+
+	return small
+}
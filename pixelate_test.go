@@ -0,0 +1,46 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPixelArtBlocks(t *testing.T) {
+	img := New([]byte("pixel-art"), WithPixelArt(24, 8), WithTransparentBackground()).(*image.RGBA)
+
+	// 120 / 24 = 5, so every 5x5 block has a single color
+	for y := 0; y < nativeSize; y += 5 {
+		for x := 0; x < nativeSize; x += 5 {
+			want := img.RGBAAt(x, y)
+			for dy := 0; dy < 5; dy++ {
+				for dx := 0; dx < 5; dx++ {
+					if got := img.RGBAAt(x+dx, y+dy); got != want {
+						t.Fatalf("Block at (%d,%d) is not uniform: %v vs %v", x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPixelArtPalette(t *testing.T) {
+	img := New([]byte("pixel-art-palette"), WithPixelArt(32, 6), WithTransparentBackground()).(*image.RGBA)
+
+	colors := map[color.RGBA]bool{}
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			c := img.RGBAAt(x, y)
+			if c.A != 0 && c.A != 255 {
+				t.Fatalf("Pixel (%d,%d) is translucent: %v", x, y, c)
+			}
+			if c.A == 255 {
+				colors[c] = true
+			}
+		}
+	}
+
+	if len(colors) > 6 {
+		t.Errorf("Expected at most 6 colors, got %d", len(colors))
+	}
+}
@@ -0,0 +1,170 @@
+package monsterid
+
+import (
+	"image"
+	"sync"
+)
+
+// rgbaPools reuses the pixels of images of the same size between renders.
+var rgbaPools = struct {
+	sync.RWMutex
+	bySize map[image.Point]*sync.Pool // released *image.RGBA by size
+}{bySize: make(map[image.Point]*sync.Pool)}
+
+// rgbaTag follows the pixels of the images allocated by newRGBA, in their
+// capacity past their length, so only images of the package are pooled and
+// not images of callers that may still be in use.
+const rgbaTag = "monsterid"
+
+// ReleaseImage returns an image created by New, Generate or their variants
+// to be reused by later renders, saving allocations and garbage collection
+// on busy servers. The image must not be used after it was released, such as
+// once it was encoded into the response. Other images are ignored.
+func ReleaseImage(img image.Image) {
+	if rgba, ok := img.(*image.RGBA); ok {
+		putRGBA(rgba)
+	}
+}
+
+// Helper to allocate a transparent image tagged with rgbaTag
+func newRGBA(r image.Rectangle) *image.RGBA {
+	n := 4 * r.Dx() * r.Dy()
+	pix := make([]uint8, n, n+len(rgbaTag))
+	copy(pix[n:cap(pix)], rgbaTag)
+
+	return &image.RGBA{Pix: pix, Stride: 4 * r.Dx(), Rect: r}
+}
+
+// Helper to check if img was allocated by newRGBA
+func ownRGBA(img *image.RGBA) bool {
+	return string(img.Pix[len(img.Pix):cap(img.Pix)]) == rgbaTag
+}
+
+// Helper to get a transparent image, reusing the pixels of a released one of
+// the same size if there is one
+func getRGBA(r image.Rectangle) *image.RGBA {
+	img := getPooledRGBA(r)
+	if img == nil {
+		return newRGBA(r)
+	}
+	clear(img.Pix)
+
+	return img
+}
+
+// Helper to get a copy of an image into a pooled image
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	img := getPooledRGBA(src.Rect)
+	if img == nil {
+		img = newRGBA(src.Rect)
+	}
+	// Sub-images have the stride of their parent
+	if img.Stride != src.Stride || len(img.Pix) != len(src.Pix) {
+		putRGBA(img)
+		return cloneImage(src)
+	}
+	copy(img.Pix, src.Pix)
+
+	return img
+}
+
+// Helper to take an image of size r out of its pool, nil if there is none
+func getPooledRGBA(r image.Rectangle) *image.RGBA {
+	rgbaPools.RLock()
+	pool := rgbaPools.bySize[r.Size()]
+	rgbaPools.RUnlock()
+	if pool == nil {
+		return nil
+	}
+	img, _ := pool.Get().(*image.RGBA)
+	if img == nil {
+		return nil
+	}
+	img.Rect = r
+
+	return img
+}
+
+// Helper to put an image allocated by newRGBA back into the pool of its size
+func putRGBA(img *image.RGBA) {
+	size := img.Rect.Size()
+	if size.X <= 0 || size.Y <= 0 || img.Stride != 4*size.X || len(img.Pix) != img.Stride*size.Y || !ownRGBA(img) {
+		return
+	}
+
+	rgbaPools.RLock()
+	pool := rgbaPools.bySize[size]
+	rgbaPools.RUnlock()
+	if pool == nil {
+		rgbaPools.Lock()
+		if pool = rgbaPools.bySize[size]; pool == nil {
+			pool = new(sync.Pool)
+			rgbaPools.bySize[size] = pool
+		}
+		rgbaPools.Unlock()
+	}
+	pool.Put(img)
+}
+
+// scratch is the intermediate images of a render, released together once
+// the render is done. A nil scratch allocates images that are never reused.
+type scratch []*image.RGBA
+
+// scratches reuses the lists of intermediate images.
+var scratches = sync.Pool{New: func() any { return new(scratch) }}
+
+// Helper to get an empty list of intermediate images
+func newScratch() *scratch {
+	return scratches.Get().(*scratch)
+}
+
+// Helper to get a transparent intermediate image
+func (s *scratch) image(r image.Rectangle) *image.RGBA {
+	if s == nil {
+		return image.NewRGBA(r)
+	}
+	img := getRGBA(r)
+	*s = append(*s, img)
+
+	return img
+}
+
+// Helper to copy an image into an intermediate image
+func (s *scratch) clone(src *image.RGBA) *image.RGBA {
+	if s == nil {
+		return cloneImage(src)
+	}
+	img := cloneRGBA(src)
+	*s = append(*s, img)
+
+	return img
+}
+
+// Helper to resample an image into an intermediate image of size x size
+func (s *scratch) scale(src *image.RGBA, size int, k kernel) *image.RGBA {
+	if s == nil {
+		return scaleImage(src, size, k)
+	}
+	img := s.image(image.Rect(0, 0, size, size))
+	resizeInto(img, src, k)
+
+	return img
+}
+
+// Helper to take over the intermediate images of other, returning its list
+func (s *scratch) adopt(other *scratch) {
+	*s = append(*s, *other...)
+	clear(*other)
+	*other = (*other)[:0]
+	scratches.Put(other)
+}
+
+// Helper to release all intermediate images and the list itself
+func (s *scratch) release() {
+	for i, img := range *s {
+		putRGBA(img)
+		(*s)[i] = nil
+	}
+	*s = (*s)[:0]
+	scratches.Put(s)
+}
@@ -0,0 +1,98 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"testing"
+)
+
+func TestReleaseImageKeepsOutput(t *testing.T) {
+	opts := [][]Option{
+		nil,
+		{WithGreyscale()},
+		{WithSize(240), WithAlgorithmVersion(V2)},
+		{WithShape(ShapeCircle), WithTrim(false)},
+	}
+
+	for _, opt := range opts {
+		for i := 0; i < 10; i++ {
+			hash := []byte(fmt.Sprintf("release-%d", i))
+			want := bytes.Clone(New(hash, opt...).(*image.RGBA).Pix)
+
+			// Render again over released images dirtied by other monsters
+			for j := 0; j < 3; j++ {
+				other := New([]byte(fmt.Sprintf("other-%d", j)), opt...)
+				ReleaseImage(other)
+			}
+			img := New(hash, opt...)
+			if !bytes.Equal(img.(*image.RGBA).Pix, want) {
+				t.Errorf("Expected reused images to render %q like new ones", hash)
+			}
+			ReleaseImage(img)
+		}
+	}
+}
+
+func TestGetRGBAIsTransparent(t *testing.T) {
+	r := image.Rect(0, 0, 3, 2)
+	dirty := newRGBA(r)
+	for i := range dirty.Pix {
+		dirty.Pix[i] = 0xff
+	}
+	putRGBA(dirty)
+
+	img := getRGBA(r)
+	if img.Rect != r || len(img.Pix) != 3*2*4 {
+		t.Fatalf("Expected a %v image, got %v with %d bytes", r, img.Rect, len(img.Pix))
+	}
+	for _, v := range img.Pix {
+		if v != 0 {
+			t.Fatal("Expected a transparent image")
+		}
+	}
+
+	// Sub-images share their parent's pixels and are never pooled
+	sub := image.NewRGBA(image.Rect(0, 0, 4, 4)).SubImage(image.Rect(1, 1, 3, 3)).(*image.RGBA)
+	putRGBA(sub)
+	if img := getRGBA(image.Rect(0, 0, 2, 2)); &img.Pix[0] == &sub.Pix[0] {
+		t.Error("Expected a sub-image not to be reused")
+	}
+}
+
+func TestReleaseImageIgnoresOtherImages(t *testing.T) {
+	// Images of the caller may still be in use
+	r := image.Rect(0, 0, 7, 7)
+	own := image.NewRGBA(r)
+	own.Pix[0] = 0xff
+	ReleaseImage(own)
+	if img := getRGBA(r); &img.Pix[0] == &own.Pix[0] {
+		t.Fatal("Expected an image of the caller not to be reused")
+	}
+	if own.Pix[0] != 0xff {
+		t.Error("Expected an image of the caller to be left alone")
+	}
+
+	// Images of New are the package's own
+	if img := New([]byte("release-own"), WithSize(37)).(*image.RGBA); !ownRGBA(img) {
+		t.Error("Expected an image of New to be released")
+	}
+}
+
+func BenchmarkGeneratorRelease(b *testing.B) {
+	g, err := NewGenerator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	hash := []byte("benchmark")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		img, err := g.Generate(hash, WithSize(240))
+		if err != nil {
+			b.Fatal(err)
+		}
+		ReleaseImage(img)
+	}
+}
@@ -0,0 +1,153 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// PregenerateConfig configures Pregenerate.
+type PregenerateConfig struct {
+	Generator *Generator // parts to render with, the embedded parts if nil
+	Options   []Option   // applied to every avatar
+	Sizes     []int      // sizes of every hash, the size of Options if empty
+	Formats   []Format   // formats of every size, FormatPNG if empty
+	Workers   int        // avatars rendered at the same time, runtime.NumCPU if zero
+
+	// Cache keeps the rendered avatars by their Key, if not nil, so avatars
+	// already in it are stored without rendering them again, such as a
+	// FileCache kept between runs. It must only be shared by runs with the
+	// same Generator and Options.
+	Cache Cache
+
+	// Key returns the key of an avatar in the sink, such as
+	// "80/alice.png" by default.
+	Key func(hash []byte, size int, format Format) string
+	// Progress is called with the number of avatars stored so far, if not
+	// nil. It is called from several goroutines.
+	Progress func(stored int64)
+}
+
+// Pregenerate renders the avatar of every hash in every size and format of
+// cfg and stores it in sink, such as a CDN bucket populated with the avatars
+// of every active user overnight. Avatars are rendered by cfg.Workers
+// goroutines while hashes are read, so hashes can stream from a database.
+// It stops at the first error and returns it, along with the number of
+// avatars stored.
+func Pregenerate(ctx context.Context, sink Sink, hashes iter.Seq[[]byte], cfg PregenerateConfig) (int64, error) {
+	if len(cfg.Formats) == 0 {
+		cfg.Formats = []Format{FormatPNG}
+	}
+	for _, format := range cfg.Formats {
+		if format.ContentType() == "" {
+			return 0, fmt.Errorf("monsterid: unknown format %q", format)
+		}
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.Key == nil {
+		cfg.Key = pregenerateKey
+	}
+
+	// The options of every size are only built once
+	base := buildOptions(cfg.Options)
+	sized := []Options{base}
+	if len(cfg.Sizes) > 0 {
+		sized = sized[:0]
+		for _, size := range cfg.Sizes {
+			if size <= 0 {
+				return 0, fmt.Errorf("monsterid: invalid size %d", size)
+			}
+			o := base
+			o.Size = size
+			sized = append(sized, o)
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var stored atomic.Int64
+	jobs := make(chan []byte, cfg.Workers)
+	var wg sync.WaitGroup
+	for range cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := new(bytes.Buffer)
+			for hash := range jobs {
+				for _, o := range sized {
+					for _, format := range cfg.Formats {
+						if err := pregenerate(ctx, sink, buf, hash, format, o, cfg); err != nil {
+							cancel(err)
+							return
+						}
+						n := stored.Add(1)
+						if cfg.Progress != nil {
+							cfg.Progress(n)
+						}
+					}
+				}
+			}
+		}()
+	}
+
+feed:
+	for hash := range hashes {
+		select {
+		// Iterators may reuse the slice for the next hash
+		case jobs <- bytes.Clone(hash):
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return stored.Load(), context.Cause(ctx)
+}
+
+// Helper to render the avatar of hash in format with o and store it in the
+// sink, using buf for the encoded image unless it is in cfg.Cache
+func pregenerate(ctx context.Context, sink Sink, buf *bytes.Buffer, hash []byte, format Format, o Options, cfg PregenerateConfig) error {
+	key := cfg.Key(hash, o.size(), format)
+	var data []byte
+	if cfg.Cache != nil {
+		// Failures are treated as misses
+		data, _ = cfg.Cache.Get(ctx, key)
+	}
+	if data == nil {
+		buf.Reset()
+		var err error
+		if g := cfg.Generator; g != nil {
+			err = g.renderHash(ctx, buf, hash, format, o, nil)
+		} else {
+			err = renderHash(ctx, buf, hash, format, o, nil)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Sinks and caches may keep the data, so it can't be the reused buffer
+		data = bytes.Clone(buf.Bytes())
+		if cfg.Cache != nil {
+			cfg.Cache.Set(ctx, key, data, 0)
+		}
+	}
+
+	if err := sink.Put(ctx, key, format.ContentType(), data); err != nil {
+		return fmt.Errorf("monsterid: put %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Helper to get the default key of an avatar, its size and hash
+func pregenerateKey(hash []byte, size int, format Format) string {
+	return fmt.Sprintf("%d/%s.%s", size, hash, format)
+}
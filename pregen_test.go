@@ -0,0 +1,150 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// memorySink is a Sink keeping what is put in a map.
+type memorySink struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	fail    string // key to fail
+}
+
+func (s *memorySink) Put(_ context.Context, key, contentType string, data []byte) error {
+	if key == s.fail {
+		return errors.New("full")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	s.types[key] = contentType
+
+	return nil
+}
+
+func newMemorySink() *memorySink {
+	return &memorySink{objects: make(map[string][]byte), types: make(map[string]string)}
+}
+
+func TestPregenerate(t *testing.T) {
+	sink := newMemorySink()
+	// The iterator reuses its slice
+	hashes := func(yield func([]byte) bool) {
+		buf := make([]byte, 0, 8)
+		for _, name := range []string{"alice", "bob", "carol"} {
+			if !yield(append(buf[:0], name...)) {
+				return
+			}
+		}
+	}
+	var calls atomic.Int64
+	cfg := PregenerateConfig{
+		Options:  []Option{WithTheme(ThemeRobot)},
+		Sizes:    []int{32, 80},
+		Formats:  []Format{FormatPNG, FormatSVG},
+		Workers:  2,
+		Progress: func(int64) { calls.Add(1) },
+	}
+	n, err := Pregenerate(context.Background(), sink, hashes, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 12 || len(sink.objects) != 12 || calls.Load() != 12 {
+		t.Fatalf("Expected 12 avatars, got %d stored, %d objects and %d calls", n, len(sink.objects), calls.Load())
+	}
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		for _, size := range []int{32, 80} {
+			for _, format := range []Format{FormatPNG, FormatSVG} {
+				key := fmt.Sprintf("%d/%s.%s", size, name, format)
+				want := new(bytes.Buffer)
+				if err := Render(want, []byte(name), format, WithTheme(ThemeRobot), WithSize(size)); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !bytes.Equal(sink.objects[key], want.Bytes()) {
+					t.Errorf("Expected %s to be the avatar", key)
+				}
+				if sink.types[key] != format.ContentType() {
+					t.Errorf("Expected %s to be %s, got %s", key, format.ContentType(), sink.types[key])
+				}
+			}
+		}
+	}
+}
+
+func TestPregenerateGenerator(t *testing.T) {
+	g, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	sink := newMemorySink()
+	cfg := PregenerateConfig{
+		Generator: g,
+		Key:       func(hash []byte, size int, format Format) string { return "avatar/" + string(hash) },
+	}
+	if _, err := Pregenerate(context.Background(), sink, slices.Values([][]byte{[]byte("alice")}), cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := new(bytes.Buffer)
+	if err := g.Render(want, []byte("alice"), FormatPNG); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(sink.objects["avatar/alice"], want.Bytes()) {
+		t.Error("Expected the avatar of the generator at its key")
+	}
+}
+
+func TestPregenerateErrors(t *testing.T) {
+	sink := newMemorySink()
+	sink.fail = "120/bob.png"
+	names := slices.Values([][]byte{[]byte("alice"), []byte("bob"), []byte("carol"), []byte("dave")})
+	_, err := Pregenerate(context.Background(), sink, names, PregenerateConfig{Workers: 1})
+	if err == nil || err.Error() != "monsterid: put 120/bob.png: full" {
+		t.Errorf("Expected the error of the sink, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Pregenerate(ctx, newMemorySink(), names, PregenerateConfig{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+
+	for _, cfg := range []PregenerateConfig{{Sizes: []int{0}}, {Formats: []Format{"webp"}}} {
+		if _, err := Pregenerate(context.Background(), newMemorySink(), names, cfg); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestPregenerateCache(t *testing.T) {
+	cache := NewMemoryCache(1 << 20)
+	cache.Set(context.Background(), "120/bob.png", []byte("cached"), 0)
+
+	sink := newMemorySink()
+	names := slices.Values([][]byte{[]byte("alice"), []byte("bob")})
+	if _, err := Pregenerate(context.Background(), sink, names, PregenerateConfig{Cache: cache}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := string(sink.objects["120/bob.png"]); got != "cached" {
+		t.Errorf("Expected the cached avatar, got %q", got)
+	}
+
+	// Rendered avatars are cached for the next run
+	data, err := cache.Get(context.Background(), "120/alice.png")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, sink.objects["120/alice.png"]) {
+		t.Error("Expected the rendered avatar to be cached")
+	}
+}
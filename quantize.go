@@ -0,0 +1,197 @@
+package monsterid
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// Quantize reduces img to a paletted image of at most n colors (2-256) using
+// median cut, keeping fully transparent pixels transparent. PNG encodes the
+// result as an indexed image, which is a fraction of the size of a full color one.
+func Quantize(img image.Image, n int) *image.Paletted {
+	n = max(2, min(n, 256))
+
+	b := img.Bounds()
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(b)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				rgba.Set(x, y, img.At(x, y))
+			}
+		}
+	}
+
+	// Count the distinct colors, sorted so the palette is deterministic
+	counts := make(map[color.RGBA]int)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := rgba.RGBAAt(x, y); c.A > 0 {
+				counts[c]++
+			}
+		}
+	}
+	colors := make([]weightedColor, 0, len(counts))
+	for c, n := range counts {
+		colors = append(colors, weightedColor{c, n})
+	}
+	slices.SortFunc(colors, func(a, b weightedColor) int {
+		return cmp.Compare(packColor(a.c), packColor(b.c))
+	})
+
+	// Index 0 is reserved for transparency
+	palette := color.Palette{color.RGBA{}}
+	for _, box := range medianCut(colors, n-1) {
+		palette = append(palette, box.average())
+	}
+
+	dst := image.NewPaletted(b, palette)
+	lookup := make(map[color.RGBA]uint8)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := rgba.RGBAAt(x, y)
+			if c.A == 0 {
+				continue
+			}
+			i, ok := lookup[c]
+			if !ok {
+				i = uint8(nearestColor(palette[1:], c) + 1)
+				lookup[c] = i
+			}
+			dst.Pix[dst.PixOffset(x, y)] = i
+		}
+	}
+
+	return dst
+}
+
+// weightedColor is a distinct color and the number of pixels using it.
+type weightedColor struct {
+	c     color.RGBA
+	count int
+}
+
+// colorBox is a set of colors split by median cut.
+type colorBox []weightedColor
+
+// Helper to split colors into at most n boxes, always splitting the box
+// with the widest channel range
+func medianCut(colors []weightedColor, n int) []colorBox {
+	if len(colors) == 0 {
+		return nil
+	}
+
+	boxes := []colorBox{colors}
+	for len(boxes) < n {
+		best, bestRange, bestChannel := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			if ch, r := box.widestChannel(); r > bestRange {
+				best, bestRange, bestChannel = i, r, ch
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		box := boxes[best]
+		slices.SortStableFunc(box, func(a, b weightedColor) int {
+			return int(channel(a.c, bestChannel)) - int(channel(b.c, bestChannel))
+		})
+
+		// Split at the weighted median, keeping both halves non-empty
+		total := 0
+		for _, wc := range box {
+			total += wc.count
+		}
+		split, seen := 1, 0
+		for i, wc := range box[:len(box)-1] {
+			seen += wc.count
+			split = i + 1
+			if seen*2 >= total {
+				break
+			}
+		}
+
+		boxes[best] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	return boxes
+}
+
+// Helper to find the channel with the widest range in the box
+func (b colorBox) widestChannel() (int, int) {
+	bestChannel, bestRange := 0, -1
+	for ch := 0; ch < 4; ch++ {
+		lo, hi := uint8(255), uint8(0)
+		for _, wc := range b {
+			v := channel(wc.c, ch)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if r := int(hi) - int(lo); r > bestRange {
+			bestChannel, bestRange = ch, r
+		}
+	}
+
+	return bestChannel, bestRange
+}
+
+// Helper to compute the pixel weighted average color of the box
+func (b colorBox) average() color.RGBA {
+	var sum [4]int
+	total := 0
+	for _, wc := range b {
+		for ch := 0; ch < 4; ch++ {
+			sum[ch] += int(channel(wc.c, ch)) * wc.count
+		}
+		total += wc.count
+	}
+
+	return color.RGBA{
+		R: uint8((sum[0] + total/2) / total),
+		G: uint8((sum[1] + total/2) / total),
+		B: uint8((sum[2] + total/2) / total),
+		A: uint8((sum[3] + total/2) / total),
+	}
+}
+
+// Helper to find the index of the palette color closest to c
+func nearestColor(palette color.Palette, c color.RGBA) int {
+	best, bestDist := 0, -1
+	for i, p := range palette {
+		pc := p.(color.RGBA)
+		dist := 0
+		for ch := 0; ch < 4; ch++ {
+			d := int(channel(pc, ch)) - int(channel(c, ch))
+			dist += d * d
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+
+	return best
+}
+
+// Helper to get a channel of a color by index, in RGBA order
+func channel(c color.RGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	}
+	return c.A
+}
+
+// Helper to pack a color into a sortable integer
+func packColor(c color.RGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
@@ -0,0 +1,76 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestQuantizeLimitsColors(t *testing.T) {
+	src := New([]byte("quantize-test"), WithSize(256))
+
+	for _, n := range []int{2, 16, 64, 256} {
+		img := Quantize(src, n)
+		if len(img.Palette) > n {
+			t.Errorf("Expected at most %d colors, got %d", n, len(img.Palette))
+		}
+		if img.Bounds() != src.Bounds() {
+			t.Errorf("Quantize changed the bounds to %v", img.Bounds())
+		}
+	}
+}
+
+func TestQuantizeKeepsTransparency(t *testing.T) {
+	src := New([]byte("quantize-transparent"), WithTransparentBackground()).(*image.RGBA)
+	img := Quantize(src, 32)
+
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			if src.RGBAAt(x, y).A == 0 && img.ColorIndexAt(x, y) != 0 {
+				t.Fatalf("Transparent pixel (%d,%d) became opaque", x, y)
+			}
+		}
+	}
+}
+
+func TestQuantizeExactForFewColors(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	src := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	src.SetRGBA(0, 0, red)
+	src.SetRGBA(1, 0, blue)
+	src.SetRGBA(2, 0, red)
+
+	img := Quantize(src, 16)
+	for x, want := range []color.RGBA{red, blue, red, {}} {
+		if got := img.At(x, 0).(color.RGBA); got != want {
+			t.Errorf("Pixel %d: expected %v, got %v", x, want, got)
+		}
+	}
+}
+
+func TestColorsOptionShrinksPNG(t *testing.T) {
+	hash := []byte("quantize-size")
+
+	full := new(bytes.Buffer)
+	if err := png.Encode(full, New(hash)); err != nil {
+		t.Fatal(err)
+	}
+
+	img := New(hash, WithColors(64))
+	if _, ok := img.(*image.Paletted); !ok {
+		t.Fatalf("Expected a paletted image, got %T", img)
+	}
+
+	small := new(bytes.Buffer)
+	if err := png.Encode(small, img); err != nil {
+		t.Fatal(err)
+	}
+
+	if small.Len() >= full.Len() {
+		t.Errorf("Paletted PNG is not smaller: %d vs %d bytes", small.Len(), full.Len())
+	}
+}
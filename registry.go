@@ -0,0 +1,97 @@
+package monsterid
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// StyleGenerator draws the avatars of a style registered with RegisterStyle,
+// so styles from other packages are served by Handler, the commands and the
+// encoders like the built-in ones. It is called from several goroutines at
+// once.
+type StyleGenerator interface {
+	// Generate returns the figure of an avatar, drawn over the background
+	// inside the padding, shape and border of the options. r is seeded
+	// from the hash, so the same hash always gets the same figure.
+	// opts.Size is the size the figure is drawn at, images of another size
+	// are scaled to it.
+	Generate(r *rand.Rand, opts Options) image.Image
+}
+
+// StyleGeneratorFunc adapts a function to a StyleGenerator.
+type StyleGeneratorFunc func(r *rand.Rand, opts Options) image.Image
+
+// Generate calls f(r, opts).
+func (f StyleGeneratorFunc) Generate(r *rand.Rand, opts Options) image.Image {
+	return f(r, opts)
+}
+
+// registeredStyles are the styles registered with RegisterStyle.
+var registeredStyles = struct {
+	sync.RWMutex
+	byName map[Style]StyleGenerator
+}{byName: map[Style]StyleGenerator{}}
+
+// RegisterStyle makes a style available by name to WithStyle, the style
+// query parameter of Handler and the commands, usually from the init
+// function of the package providing it. It panics if the name is empty,
+// taken by a built-in style or registered twice.
+func RegisterStyle(name Style, g StyleGenerator) {
+	if name == "" || g == nil {
+		panic("monsterid: RegisterStyle with an empty name or nil generator")
+	}
+	if slices.Contains(builtinStyles(), name) {
+		panic(fmt.Sprintf("monsterid: RegisterStyle of built-in style %q", name))
+	}
+
+	registeredStyles.Lock()
+	defer registeredStyles.Unlock()
+	if _, ok := registeredStyles.byName[name]; ok {
+		panic(fmt.Sprintf("monsterid: RegisterStyle called twice for style %q", name))
+	}
+	registeredStyles.byName[name] = g
+}
+
+// Helper to get the registered styles in order of name
+func registeredStyleNames() []Style {
+	registeredStyles.RLock()
+	defer registeredStyles.RUnlock()
+
+	return slices.Sorted(maps.Keys(registeredStyles.byName))
+}
+
+// Helper to get the figure of a registered style
+func registeredFigure(s Style) (figureFunc, bool) {
+	registeredStyles.RLock()
+	g, ok := registeredStyles.byName[s]
+	registeredStyles.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	return func(img *image.RGBA, r *rand.Rand, o Options) error {
+		size := img.Rect.Dx()
+		o.Size = size
+		figure := g.Generate(r, o)
+		if figure == nil || figure.Bounds().Empty() {
+			return fmt.Errorf("monsterid: style %q generated no image", s)
+		}
+
+		src, ok := figure.(*image.RGBA)
+		if !ok {
+			src = image.NewRGBA(figure.Bounds())
+			draw.Draw(src, src.Bounds(), figure, figure.Bounds().Min, draw.Src)
+		}
+		if b := src.Bounds(); b.Dx() != size || b.Dy() != size {
+			src = resizeImage(src, size, size, o.Filter.kernel())
+		}
+		draw.Draw(img, img.Rect, src, src.Bounds().Min, draw.Over)
+
+		return nil
+	}, true
+}
@@ -0,0 +1,118 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// Styles registered once for the tests, registering twice panics
+const (
+	testStyleStripes Style = "test-stripes" // 4x4 image of one random color
+	testStyleNil     Style = "test-nil"     // no image
+)
+
+func init() {
+	RegisterStyle(testStyleStripes, StyleGeneratorFunc(func(r *rand.Rand, o Options) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		c := color.RGBA{uint8(r.IntN(256)), uint8(r.IntN(256)), uint8(r.IntN(256)), 0xff}
+		for x := 0; x < 4; x += 2 {
+			for y := 0; y < 4; y++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+		return img
+	}))
+	RegisterStyle(testStyleNil, StyleGeneratorFunc(func(*rand.Rand, Options) image.Image { return nil }))
+}
+
+func TestRegisterStyle(t *testing.T) {
+	if !slices.Contains(Styles(), testStyleStripes) || !isStyle(testStyleStripes) {
+		t.Fatalf("Expected the registered style in %v", Styles())
+	}
+	if got := Styles()[:len(builtinStyles())]; !slices.Equal(got, builtinStyles()) {
+		t.Errorf("Expected the built-in styles first, got %v", got)
+	}
+
+	tests := []struct {
+		name  string
+		style Style
+		g     StyleGenerator
+	}{
+		{"empty name", "", StyleGeneratorFunc(func(*rand.Rand, Options) image.Image { return nil })},
+		{"nil generator", "test-none", nil},
+		{"built-in style", StyleIdenticon, StyleGeneratorFunc(func(*rand.Rand, Options) image.Image { return nil })},
+		{"second registration", testStyleStripes, StyleGeneratorFunc(func(*rand.Rand, Options) image.Image { return nil })},
+	}
+
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for the %s", test.name)
+				}
+			}()
+			RegisterStyle(test.style, test.g)
+		}()
+	}
+}
+
+func TestRegisteredStyle(t *testing.T) {
+	opts := []Option{WithStyle(testStyleStripes), WithSize(40), WithPadding(4), WithTransparentBackground()}
+	img := New([]byte("alice"), opts...).(*image.RGBA)
+	if !bytes.Equal(img.Pix, New([]byte("alice"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected the same figure for the same hash")
+	}
+	if bytes.Equal(img.Pix, New([]byte("bob"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected the figure to differ by hash")
+	}
+
+	// The 4x4 figure is scaled into the padding, with nearest neighbor to
+	// keep the stripes sharp
+	img = New([]byte("alice"), append(opts, WithFilter(NearestNeighbor))...).(*image.RGBA)
+	if c := img.RGBAAt(2, 20); c.A != 0 {
+		t.Errorf("Expected the padding to stay transparent, got %v", c)
+	}
+	if c := img.RGBAAt(6, 20); c.A != 0xff {
+		t.Errorf("Expected a stripe, got %v", c)
+	}
+	if c := img.RGBAAt(14, 20); c.A != 0 {
+		t.Errorf("Expected a gap between stripes, got %v", c)
+	}
+
+	if _, err := NewContext(context.Background(), []byte("alice"), WithStyle(testStyleNil)); err == nil {
+		t.Error("Expected an error for a style without an image")
+	}
+	if _, err := NewContext(context.Background(), []byte("alice"), WithStyle("test-unknown")); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
+
+func TestHandlerRegisteredStyle(t *testing.T) {
+	h := Handler(HandlerConfig{Params: []string{ParamStyle}})
+	rec := serve(h, http.MethodGet, "/alice?style=test-stripes&s=32")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the registered style to be served, got %d: %s", rec.Code, rec.Body)
+	}
+
+	want := new(bytes.Buffer)
+	if err := Render(want, []byte("alice"), FormatPNG, WithStyle(testStyleStripes), WithSize(32)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+		t.Error("Expected the handler to serve the registered style")
+	}
+
+	svg, err := SVG([]byte("alice"), WithStyle(testStyleStripes))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Contains(svg, []byte(`<g id="test-stripes"`)) {
+		t.Errorf("Expected the SVG to name the style, got %.200s", svg)
+	}
+}
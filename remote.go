@@ -0,0 +1,224 @@
+package monsterid
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxPackSize is the largest pack archive FetchPack downloads.
+const maxPackSize = 64 << 20
+
+// RemotePack is a part pack archive to download with FetchPack.
+type RemotePack struct {
+	URL      string       // location of a .zip or .tar.gz archive of the pack
+	SHA256   string       // expected hex-encoded SHA-256 checksum of the archive
+	CacheDir string       // directory keeping verified packs, monsterid in os.UserCacheDir if empty
+	Client   *http.Client // client for the download, http.DefaultClient if nil
+}
+
+// FetchPack downloads a part pack archive, verifies its checksum and
+// extracts it into the cache directory, returning the pack to use with
+// NewGeneratorFromFS. Packs are cached by checksum, so servers only download
+// a pack once and keep starting when the URL is unreachable. Archives with a
+// single top-level directory are unwrapped.
+func FetchPack(ctx context.Context, r RemotePack) (fs.FS, error) {
+	sum, err := hex.DecodeString(r.SHA256)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("monsterid: invalid SHA-256 checksum %q", r.SHA256)
+	}
+
+	cacheDir := r.CacheDir
+	if cacheDir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("monsterid: pack cache: %w", err)
+		}
+		cacheDir = filepath.Join(userCache, "monsterid")
+	}
+	dir := filepath.Join(cacheDir, hex.EncodeToString(sum))
+
+	if _, err := os.Stat(dir); err != nil {
+		data, err := downloadPack(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if got := sha256.Sum256(data); !bytes.Equal(got[:], sum) {
+			return nil, fmt.Errorf("monsterid: pack %s has checksum %x, want %x", r.URL, got, sum)
+		}
+		if err := extractPack(data, cacheDir, dir); err != nil {
+			return nil, fmt.Errorf("monsterid: extract pack %s: %w", r.URL, err)
+		}
+	}
+
+	return packRoot(os.DirFS(dir))
+}
+
+// Helper to download a pack archive into memory
+func downloadPack(ctx context.Context, r RemotePack) ([]byte, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: download pack: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: download pack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("monsterid: download pack %s: %s", r.URL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: download pack %s: %w", r.URL, err)
+	}
+	if len(data) > maxPackSize {
+		return nil, fmt.Errorf("monsterid: pack %s is larger than %d bytes", r.URL, maxPackSize)
+	}
+
+	return data, nil
+}
+
+// Helper to extract a zip or tar.gz archive into dir, through a temporary
+// directory so an interrupted extraction is never used
+func extractPack(data []byte, cacheDir, dir string) error {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(cacheDir, "extract-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		err = extractZip(data, tmp)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		err = extractTarGz(data, tmp)
+	default:
+		err = errors.New("not a zip or tar.gz archive")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Another process may have extracted the same pack meanwhile
+	if err := os.Rename(tmp, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Helper to extract the files of a zip archive into dir
+func extractZip(data []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writePackFile(dir, f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Helper to extract the regular files of a tar.gz archive into dir
+func extractTarGz(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := writePackFile(dir, hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Helper to write a file of an archive below dir, rejecting names that would
+// escape it
+func writePackFile(dir, name string, r io.Reader) error {
+	name = path.Clean(name)
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Helper to get the root of an extracted pack, unwrapping a single top-level
+// directory
+func packRoot(fsys fs.FS) (fs.FS, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("monsterid: read pack: %w", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() && !strings.HasPrefix(entries[0].Name(), "@") {
+		return fs.Sub(fsys, entries[0].Name())
+	}
+
+	return fsys, nil
+}
@@ -0,0 +1,155 @@
+package monsterid
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Helper to archive a pack as zip, below prefix
+func zipPack(t *testing.T, fsys fstest.MapFS, prefix string) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, name := range sortedNames(fsys) {
+		w, err := zw.Create(prefix + name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		w.Write(fsys[name].Data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// Helper to archive a pack as tar.gz
+func tarGzPack(t *testing.T, fsys fstest.MapFS) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range sortedNames(fsys) {
+		data := fsys[name].Data
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		tw.Write(data)
+	}
+	tw.Close()
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+// Helper to list the file names of a pack in order
+func sortedNames(fsys fstest.MapFS) []string {
+	names := make([]string, 0, len(fsys))
+	for name := range fsys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Helper to serve an archive, counting the downloads
+func servePack(t *testing.T, data []byte, downloads *int) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*downloads++
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// Helper to get the hex SHA-256 checksum of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetchPack(t *testing.T) {
+	tests := []struct {
+		description string
+		data        []byte
+	}{
+		{"zip", zipPack(t, testPack(t, 2), "")},
+		{"zip with a top-level directory", zipPack(t, testPack(t, 2), "pack-1.0/")},
+		{"tar.gz", tarGzPack(t, testPack(t, 2))},
+	}
+
+	for _, test := range tests {
+		downloads := 0
+		srv := servePack(t, test.data, &downloads)
+		r := RemotePack{URL: srv.URL + "/pack", SHA256: checksum(test.data), CacheDir: t.TempDir()}
+
+		fsys, err := FetchPack(context.Background(), r)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.description, err)
+		}
+		g, err := NewGeneratorFromFS(fsys)
+		if err != nil {
+			t.Fatalf("Failed to create generator for %s: %v", test.description, err)
+		}
+		if n := g.pack().counts.count("body"); n != 2 {
+			t.Errorf("Expected 2 body parts for %s, got %d", test.description, n)
+		}
+
+		// The cached pack is used without downloading it again
+		srv.Close()
+		if _, err := FetchPack(context.Background(), r); err != nil {
+			t.Errorf("Unexpected error for cached %s: %v", test.description, err)
+		}
+		if downloads != 1 {
+			t.Errorf("Expected 1 download for %s, got %d", test.description, downloads)
+		}
+	}
+}
+
+func TestFetchPackErrors(t *testing.T) {
+	data := zipPack(t, testPack(t, 1), "")
+	downloads := 0
+	srv := servePack(t, data, &downloads)
+
+	escape := zipPack(t, fstest.MapFS{"../escape.png": &fstest.MapFile{Data: []byte("x")}}, "")
+	escapeSrv := servePack(t, escape, &downloads)
+
+	notArchive := []byte("just text")
+	textSrv := servePack(t, notArchive, &downloads)
+
+	tests := []struct {
+		description string
+		r           RemotePack
+		want        string
+	}{
+		{"invalid checksum", RemotePack{URL: srv.URL, SHA256: "abc"}, "invalid SHA-256"},
+		{"checksum mismatch", RemotePack{URL: srv.URL, SHA256: checksum([]byte("other"))}, "has checksum"},
+		{"path escaping the pack", RemotePack{URL: escapeSrv.URL, SHA256: checksum(escape)}, "invalid file name"},
+		{"not an archive", RemotePack{URL: textSrv.URL, SHA256: checksum(notArchive)}, "not a zip"},
+	}
+
+	for _, test := range tests {
+		test.r.CacheDir = t.TempDir()
+		_, err := FetchPack(context.Background(), test.r)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Expected an error containing %q for %s, got %v", test.want, test.description, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package monsterid
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"io"
+	"sync"
+	"time"
+)
+
+// Format is an image file format monsters can be rendered to.
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatGIF  Format = "gif"
+	FormatBMP  Format = "bmp"
+	FormatTIFF Format = "tiff"
+	FormatSVG  Format = "svg"
+)
+
+// ContentType returns the MIME type of the format, such as image/png, or an
+// empty string if the format is unknown.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatPNG, FormatGIF, FormatBMP, FormatTIFF:
+		return "image/" + string(f)
+	case FormatSVG:
+		return "image/svg+xml"
+	}
+
+	return ""
+}
+
+// Render creates a monsterid image based on the provided hash and encodes it
+// straight to w in format, such as the body of an HTTP response. The image
+// is reused by later renders once it is encoded, so busy servers need
+// neither an intermediate buffer nor a new image per request.
+func Render(w io.Writer, hash []byte, format Format, opts ...Option) error {
+	return renderHash(context.Background(), w, hash, format, buildOptions(opts), nil)
+}
+
+// Render creates a monsterid image based on the provided hash and encodes it
+// straight to w in format, like the package-level Render.
+func (g *Generator) Render(w io.Writer, hash []byte, format Format, opts ...Option) error {
+	return g.renderHash(context.Background(), w, hash, format, buildOptions(opts), nil)
+}
+
+// Helper to render the monster for hash to w in format with the embedded
+// parts, measuring how long it takes into times if not nil
+func renderHash(ctx context.Context, w io.Writer, hash []byte, format Format, o Options, times *renderTimes) error {
+	if err := o.checkVersion(); err != nil {
+		return err
+	}
+	if o.style() != StyleMonster {
+		return renderStyle(ctx, w, hash, format, o, times)
+	}
+
+	return renderFormat(ctx, w, format, describeHash(hash, o), o, o.theme().pack(), times)
+}
+
+// Helper to render the monster for hash to w in format with the parts of g,
+// measuring how long it takes into times if not nil
+func (g *Generator) renderHash(ctx context.Context, w io.Writer, hash []byte, format Format, o Options, times *renderTimes) error {
+	if err := o.checkVersion(); err != nil {
+		return err
+	}
+	if o.style() != StyleMonster {
+		return renderStyle(ctx, w, hash, format, o, times)
+	}
+
+	p := g.pack()
+	d := describeSeed(hash, o, p)
+	g.usage.record(d, p.counts)
+	return renderFormat(ctx, w, format, d, o, p, times)
+}
+
+// pngBuffers reuses the state of the PNG encoder between renders.
+var pngBuffers = &pngBufferPool{}
+
+// pngBufferPool is a png.EncoderBufferPool backed by a sync.Pool.
+type pngBufferPool struct {
+	pool sync.Pool
+}
+
+func (p *pngBufferPool) Get() *png.EncoderBuffer {
+	b, _ := p.pool.Get().(*png.EncoderBuffer)
+	return b
+}
+
+func (p *pngBufferPool) Put(b *png.EncoderBuffer) {
+	p.pool.Put(b)
+}
+
+// renderTimes is how long rendering an avatar took, split into composing
+// the image and encoding it.
+type renderTimes struct {
+	render, encode time.Duration
+}
+
+// Helper to render the monster described by d to w in format using parts
+// from p, measuring how long it takes into times if not nil
+func renderFormat(ctx context.Context, w io.Writer, format Format, d Descriptor, o Options, p pack, times *renderTimes) error {
+	start := time.Now()
+	switch format {
+	case FormatSVG:
+		// Parts are encoded while writing, which is all counted as rendering
+		err := writeSVG(w, d, o, p)
+		if times != nil {
+			times.render = time.Since(start)
+		}
+		return err
+	case FormatPNG, FormatGIF, FormatBMP, FormatTIFF:
+	default:
+		return fmt.Errorf("monsterid: unknown format %q", format)
+	}
+
+	// GIF quantizes on its own, after dropping partial transparency
+	colors := o.Colors
+	if format == FormatGIF {
+		o.Output, o.Colors = OutputRGBA, 0
+	}
+
+	img, err := newImage(ctx, d, o, p)
+	if err != nil {
+		return err
+	}
+	defer ReleaseImage(img)
+
+	encoded := time.Now()
+	err = encodeImage(w, format, img, d, o, colors)
+	if times != nil {
+		times.render, times.encode = encoded.Sub(start), time.Since(encoded)
+	}
+
+	return err
+}
+
+// Helper to encode the image of the monster described by d to w in a raster
+// format, quantizing GIF to colors
+func encodeImage(w io.Writer, format Format, img image.Image, d Descriptor, o Options, colors int) error {
+	switch format {
+	case FormatGIF:
+		return gif.Encode(w, palettedGIF(img.(*image.RGBA), colors), nil)
+	case FormatBMP:
+		return writeBMP(w, toNRGBA(img))
+	case FormatTIFF:
+		return writeTIFF(w, toNRGBA(img))
+	}
+
+	enc := png.Encoder{CompressionLevel: o.Compression, BufferPool: pngBuffers}
+	if o.Metadata {
+		w = &pngTextWriter{w: w, texts: pngMetadata(d, o)}
+	}
+
+	return enc.Encode(w, img)
+}
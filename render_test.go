@@ -0,0 +1,151 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+// byteWriter writes one byte per call, like a slow connection.
+type byteWriter struct {
+	w io.Writer
+}
+
+func (bw byteWriter) Write(p []byte) (int, error) {
+	for i := range p {
+		if _, err := bw.w.Write(p[i : i+1]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func TestRender(t *testing.T) {
+	hash := []byte("render")
+	tests := []struct {
+		format Format
+		magic  string
+	}{
+		{FormatPNG, "\x89PNG"},
+		{FormatGIF, "GIF89a"},
+		{FormatBMP, "BM"},
+		{FormatTIFF, "II*\x00"},
+		{FormatSVG, "<svg"},
+	}
+
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		if err := Render(buf, hash, test.format); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(buf.String(), test.magic) {
+			t.Errorf("Expected %s to start with %q, got %q", test.format, test.magic, buf.Bytes()[:8])
+		}
+	}
+
+	// PNG holds the pixels New creates
+	buf := new(bytes.Buffer)
+	if err := Render(buf, hash, FormatPNG, WithSize(64)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	got := image.NewRGBA(img.Bounds())
+	draw.Draw(got, got.Rect, img, image.Point{}, draw.Src)
+	if want := New(hash, WithSize(64)).(*image.RGBA); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("Expected the rendered PNG to match New")
+	}
+
+	if err := Render(io.Discard, hash, Format("jpeg")); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestRenderMetadata(t *testing.T) {
+	hash := []byte("render-metadata")
+	want, err := PNG(hash, WithMetadata())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The chunks are inserted however the encoder splits its writes
+	buf := new(bytes.Buffer)
+	if err := Render(byteWriter{buf}, hash, FormatPNG, WithMetadata()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("Expected byte-wise writes to produce the same PNG")
+	}
+
+	d, err := ParsePNG(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d != Describe(hash) {
+		t.Errorf("Expected %+v, got %+v", Describe(hash), d)
+	}
+}
+
+func TestGeneratorRender(t *testing.T) {
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	hash := []byte("generator-render")
+	for _, format := range []Format{FormatPNG, FormatSVG} {
+		got, want := new(bytes.Buffer), new(bytes.Buffer)
+		if err := g.Render(got, hash, format); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := Render(want, hash, format); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("Expected the generator to render %s like the package", format)
+		}
+	}
+}
+
+func TestFormatContentType(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatPNG, "image/png"},
+		{FormatGIF, "image/gif"},
+		{FormatBMP, "image/bmp"},
+		{FormatTIFF, "image/tiff"},
+		{FormatSVG, "image/svg+xml"},
+		{Format("jpeg"), ""},
+	}
+
+	for _, test := range tests {
+		if got := test.format.ContentType(); got != test.want {
+			t.Errorf("Expected %s to be %q, got %q", test.format, test.want, got)
+		}
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	g, err := NewGenerator()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	hash := []byte("benchmark")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := g.Render(io.Discard, hash, FormatPNG); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
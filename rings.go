@@ -0,0 +1,126 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+)
+
+// ringKind is the kind of a layer of a rings avatar.
+type ringKind int
+
+const (
+	ringFull    ringKind = iota // closed ring
+	ringArc                     // part of a ring with round ends
+	ringDashed                  // ring broken into evenly spaced dashes
+	ringPolygon                 // outline of a regular polygon
+)
+
+// ring is a layer of a rings avatar, in pixels and radians.
+type ring struct {
+	kind     ringKind
+	radius   float64 // distance from the center to the middle of the stroke
+	width    float64 // width of the stroke
+	start    float64 // angle of the arc, the first dash or a corner of the polygon
+	sweep    float64 // angle covered by the arc
+	segments int     // number of dashes or sides
+	color    color.RGBA
+}
+
+// Helper to draw concentric rings, arcs, dashes and polygons, each with its
+// own color and rotation, around a filled center, like an abstract logo
+func drawRings(img *image.RGBA, r *rand.Rand, o Options) error {
+	size := float64(img.Rect.Dx())
+	count := 3 + r.IntN(3)
+	step := size * 0.48 / (float64(count) + 0.5)
+
+	rings := make([]ring, 0, count+1)
+	for i := range count {
+		rg := ring{
+			kind:     ringKind(r.IntN(4)),
+			radius:   size*0.48 - (float64(i)+0.35)*step,
+			width:    step * (0.4 + 0.3*r.Float64()),
+			start:    r.Float64() * 2 * math.Pi,
+			sweep:    (0.5 + r.Float64()) * math.Pi,
+			segments: 3 + r.IntN(6),
+			color:    figureColor(r, o, 0.35+0.3*r.Float64()),
+		}
+		rings = append(rings, rg)
+	}
+	// The center is a filled polygon, a circle if it has many sides
+	center := ring{
+		kind:     ringPolygon,
+		radius:   step * 0.3,
+		width:    step * 0.6,
+		start:    r.Float64() * 2 * math.Pi,
+		segments: 3 + r.IntN(6),
+		color:    figureColor(r, o, 0.5),
+	}
+	if center.segments > 6 {
+		center.kind = ringFull
+	}
+	rings = append(rings, center)
+
+	c := size / 2
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			dx, dy := float64(x-img.Rect.Min.X)+0.5-c, float64(y-img.Rect.Min.Y)+0.5-c
+			i := img.PixOffset(x, y)
+			for _, rg := range rings {
+				// Coverage of the pixel by the stroke
+				a := max(0, min(1, 0.5-rg.distance(dx, dy)))
+				if a == 0 {
+					continue
+				}
+				for ch, v := range [4]uint8{rg.color.R, rg.color.G, rg.color.B, rg.color.A} {
+					img.Pix[i+ch] = uint8(float64(img.Pix[i+ch])*(1-a) + float64(v)*a + 0.5)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Helper to get the signed distance from dx, dy relative to the center to
+// the stroke of the ring, negative inside it
+func (rg ring) distance(dx, dy float64) float64 {
+	rho := math.Hypot(dx, dy)
+	theta := math.Atan2(dy, dx) - rg.start
+	// Angle from the start, in [0, 2π)
+	theta -= 2 * math.Pi * math.Floor(theta/(2*math.Pi))
+
+	switch rg.kind {
+	case ringArc:
+		if theta > rg.sweep {
+			return rg.capDistance(dx, dy, 0, rg.sweep)
+		}
+	case ringDashed:
+		// Dashes take up half of every segment
+		seg := 2 * math.Pi / float64(rg.segments)
+		if local := math.Mod(theta, seg); local > seg/2 {
+			n := math.Floor(theta / seg)
+			return rg.capDistance(dx, dy, n*seg+seg/2, (n+1)*seg)
+		}
+	case ringPolygon:
+		seg := 2 * math.Pi / float64(rg.segments)
+		local := math.Mod(theta, seg) - seg/2
+		apothem := rg.radius * math.Cos(seg/2)
+		return math.Abs(rho*math.Cos(local)-apothem) - rg.width/2
+	}
+
+	return math.Abs(rho-rg.radius) - rg.width/2
+}
+
+// Helper to get the distance to the nearer of the round ends of the stroke
+// at the angles a0 and a1 from the start
+func (rg ring) capDistance(dx, dy, a0, a1 float64) float64 {
+	d := math.Inf(1)
+	for _, a := range []float64{a0, a1} {
+		sin, cos := math.Sincos(rg.start + a)
+		d = min(d, math.Hypot(dx-rg.radius*cos, dy-rg.radius*sin))
+	}
+
+	return d - rg.width/2
+}
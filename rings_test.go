@@ -0,0 +1,51 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestRings(t *testing.T) {
+	opts := []Option{WithStyle(StyleRings), WithTransparentBackground()}
+	img := New([]byte("alice"), opts...).(*image.RGBA)
+	if !bytes.Equal(img.Pix, New([]byte("alice"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected the same rings for the same hash")
+	}
+	if bytes.Equal(img.Pix, New([]byte("bob"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected rings to differ by hash")
+	}
+
+	// The center is filled, the corners are outside the outer ring
+	if c := img.RGBAAt(nativeSize/2, nativeSize/2); c.A != 0xff {
+		t.Errorf("Expected a filled center, got %v", c)
+	}
+	if c := img.RGBAAt(0, 0); c.A != 0 {
+		t.Errorf("Expected a transparent corner, got %v", c)
+	}
+}
+
+func TestRingDistance(t *testing.T) {
+	tests := []struct {
+		name   string
+		rg     ring
+		dx, dy float64
+		want   float64
+	}{
+		{"on a ring", ring{kind: ringFull, radius: 10, width: 4}, 10, 0, -2},
+		{"inside a ring", ring{kind: ringFull, radius: 10, width: 4}, 0, 5, 3},
+		{"in an arc", ring{kind: ringArc, radius: 10, width: 4, sweep: math.Pi}, 0, 10, -2},
+		{"past an arc", ring{kind: ringArc, radius: 10, width: 4, sweep: math.Pi / 2}, -10, 0, math.Hypot(10, 10) - 2},
+		{"in a dash", ring{kind: ringDashed, radius: 10, width: 4, segments: 4}, 10 * math.Cos(math.Pi/8), 10 * math.Sin(math.Pi/8), -2},
+		{"between dashes", ring{kind: ringDashed, radius: 10, width: 4, segments: 4}, 10 * math.Cos(3*math.Pi/8), 10 * math.Sin(3*math.Pi/8), 20*math.Sin(math.Pi/16) - 2},
+		{"on the side of a square", ring{kind: ringPolygon, radius: 10, width: 2, segments: 4}, 5, 5, -1},
+		{"at a corner of a square", ring{kind: ringPolygon, radius: 10, width: 2, segments: 4}, 10, 0, -1},
+	}
+
+	for _, test := range tests {
+		if got := test.rg.distance(test.dx, test.dy); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Expected %v %s, got %v", test.want, test.name, got)
+		}
+	}
+}
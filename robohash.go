@@ -0,0 +1,28 @@
+package monsterid
+
+import (
+	"image"
+	"math/rand/v2"
+)
+
+// robohashDir is the embedded directory of the robohash parts, drawn by
+// gen_parts.go.
+const robohashDir = "parts/robohash"
+
+// robohashParts are the categories of robohash parts in drawing order, so
+// antennas go behind the head.
+var robohashParts = []figurePart{
+	{"body", 6, true},
+	{"antenna", 6, false},
+	{"head", 8, true},
+	{"eyes", 8, false},
+	{"mouth", 6, false},
+}
+
+// Helper to draw a RoboHash-style robot, a body and head in one chassis
+// color with an antenna, eyes and a mouth picked from the embedded parts
+func drawRobohash(img *image.RGBA, r *rand.Rand, o Options) error {
+	chassis := figureColor(r, o, 0.55)
+
+	return drawFigureParts(img, r, o, robohashDir, robohashParts, chassis)
+}
@@ -0,0 +1,61 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRobohash(t *testing.T) {
+	opts := []Option{WithStyle(StyleRobohash), WithTransparentBackground()}
+	img := New([]byte("alice"), opts...).(*image.RGBA)
+	if !bytes.Equal(img.Pix, New([]byte("alice"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected the same robot for the same hash")
+	}
+	if bytes.Equal(img.Pix, New([]byte("bob"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected robots to differ by hash")
+	}
+	if bytes.Equal(img.Pix, New([]byte("alice"), WithStyle(StyleWavatar), WithTransparentBackground()).(*image.RGBA).Pix) {
+		t.Error("Expected the robot to differ from the wavatar")
+	}
+
+	// The body reaches the bottom edge, the corners stay transparent
+	if img.RGBAAt(0, 0).A != 0 {
+		t.Errorf("Expected a transparent corner, got %v", img.RGBAAt(0, 0))
+	}
+	if c := img.RGBAAt(nativeSize/2, nativeSize-1); c.A != 0xff {
+		t.Errorf("Expected the body at the bottom, got %v", c)
+	}
+}
+
+func TestRobohashChassis(t *testing.T) {
+	hue := 0.0
+	img := New([]byte("alice"), WithStyle(StyleRobohash), WithHue(hue, 0), WithTransparentBackground()).(*image.RGBA)
+
+	// The chassis is tinted in the hue, the other parts are grey
+	red := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		r, g, b := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+		if g != b {
+			t.Fatalf("Expected red or grey pixels, got %v", img.Pix[i:i+4])
+		}
+		if r > g {
+			red++
+		}
+	}
+	if red < nativeSize*nativeSize/8 {
+		t.Errorf("Expected a red chassis, got %d red pixels", red)
+	}
+}
+
+func TestRobohashGreyscale(t *testing.T) {
+	for _, hash := range []string{"alice", "bob", "carol", "dave"} {
+		img := New([]byte(hash), WithStyle(StyleRobohash), WithGreyscale()).(*image.RGBA)
+		for i := 0; i < len(img.Pix); i += 4 {
+			if c := (color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}); c.R != c.G || c.G != c.B {
+				t.Fatalf("Expected a grey robot for %s, got %v", hash, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3Sink.
+type S3Config struct {
+	Endpoint string // base URL of the service, such as https://s3.us-east-1.amazonaws.com
+	Bucket   string // bucket the avatars are stored in
+	Region   string // region of the bucket, us-east-1 if empty
+	Prefix   string // prepended to every key, such as avatars/
+
+	AccessKeyID     string // credentials requests are signed with
+	SecretAccessKey string
+
+	CacheControl string       // Cache-Control of the objects served by the CDN, if not empty
+	Client       *http.Client // client of the requests, http.DefaultClient if nil
+}
+
+// S3Sink is a Sink storing avatars as objects of an S3 bucket, or of another
+// object store with an S3 compatible API such as MinIO, Cloudflare R2 or
+// Google Cloud Storage. Objects are uploaded at path-style URLs with requests
+// signed by AWS Signature Version 4.
+type S3Sink struct {
+	cfg S3Config
+	now func() time.Time // current time, replaced by tests
+}
+
+// NewS3Sink creates an S3Sink uploading to the bucket of cfg.
+func NewS3Sink(cfg S3Config) (*S3Sink, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("monsterid: s3 sink: missing endpoint or bucket")
+	}
+	if _, err := url.Parse(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("monsterid: s3 sink: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	return &S3Sink{cfg: cfg, now: time.Now}, nil
+}
+
+// Put uploads data as the object at key below the prefix.
+func (s *S3Sink) Put(ctx context.Context, key, contentType string, data []byte) error {
+	target := strings.TrimSuffix(s.cfg.Endpoint, "/") + "/" + s3Escape(s.cfg.Bucket) + "/" + s3Escape(s.cfg.Prefix+key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("monsterid: s3 sink: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.cfg.CacheControl != "" {
+		req.Header.Set("Cache-Control", s.cfg.CacheControl)
+	}
+	sum := sha256.Sum256(data)
+	signV4(req, hex.EncodeToString(sum[:]), s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.cfg.Region, s.now())
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("monsterid: s3 sink: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("monsterid: s3 sink: put %s: %s: %s", key, resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+// Helper to sign a request to S3 with AWS Signature Version 4, covering the
+// host and every header of the request
+func signV4(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + region + "/s3/aws4_request"
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range []string{now.Format("20060102"), region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		accessKeyID, scope, signedHeaders, hex.EncodeToString(key)))
+}
+
+// Helper to escape an object key for a path as S3 expects, everything but
+// unreserved characters and slashes
+func s3Escape(key string) string {
+	var sb strings.Builder
+	for _, b := range []byte(key) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', strings.IndexByte("-_.~/", b) >= 0:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,245 @@
+package monsterid
+
+import (
+	"image"
+	"math"
+	"sync"
+)
+
+// Filter selects the resampling filter used when the output size differs
+// from the native size of the part artwork.
+type Filter int
+
+const (
+	CatmullRom      Filter = iota // sharp cubic filter
+	NearestNeighbor               // crisp pixel look
+	Bilinear                      // smooth linear filter
+	Lanczos                       // sharpest filter, three lobes
+)
+
+// Helper to get the kernel implementing the filter
+func (f Filter) kernel() kernel {
+	switch f {
+	case NearestNeighbor:
+		return nearestNeighbor
+	case Bilinear:
+		return bilinear
+	case Lanczos:
+		return lanczos
+	}
+	return catmullRom
+}
+
+// kernel is a separable resampling filter.
+type kernel struct {
+	filter  Filter                  // filter implemented by the kernel
+	support float64                 // radius of the filter at a scale of 1
+	at      func(t float64) float64 // weight for a sample at distance t
+}
+
+// catmullRom is a sharp cubic filter that works well for both up- and
+// downscaling of the part artwork.
+var catmullRom = kernel{
+	filter:  CatmullRom,
+	support: 2,
+	at: func(t float64) float64 {
+		t = math.Abs(t)
+		if t < 1 {
+			return (3*t*t*t - 5*t*t + 2) / 2
+		}
+		if t < 2 {
+			return (-t*t*t + 5*t*t - 8*t + 4) / 2
+		}
+		return 0
+	},
+}
+
+// nearestNeighbor picks the single closest source sample, it is handled
+// separately by weights.
+var nearestNeighbor = kernel{filter: NearestNeighbor}
+
+// bilinear is a triangle filter.
+var bilinear = kernel{
+	filter:  Bilinear,
+	support: 1,
+	at: func(t float64) float64 {
+		return max(0, 1-math.Abs(t))
+	},
+}
+
+// lanczos is a windowed sinc filter with three lobes.
+var lanczos = kernel{
+	filter:  Lanczos,
+	support: 3,
+	at: func(t float64) float64 {
+		t = math.Abs(t)
+		if t == 0 {
+			return 1
+		}
+		if t >= 3 {
+			return 0
+		}
+		return 3 * math.Sin(math.Pi*t) * math.Sin(math.Pi*t/3) / (math.Pi * math.Pi * t * t)
+	},
+}
+
+// Helper to resample a premultiplied RGBA image to size x size pixels
+func scaleImage(src *image.RGBA, size int, k kernel) *image.RGBA {
+	return resizeImage(src, size, size, k)
+}
+
+// Helper to resample a premultiplied RGBA image to dw x dh pixels
+func resizeImage(src *image.RGBA, dw, dh int, k kernel) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	resizeInto(dst, src, k)
+
+	return dst
+}
+
+// resizeBuffers reuses the float buffers of the horizontal pass of resizeInto.
+var resizeBuffers = sync.Pool{New: func() any { return new([]float64) }}
+
+// Helper to resample a premultiplied RGBA image to the size of dst,
+// replacing all pixels of dst
+func resizeInto(dst, src *image.RGBA, k kernel) {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dw, dh := dst.Rect.Dx(), dst.Rect.Dy()
+
+	// Horizontal pass into a float buffer of dw x sh pixels
+	buf := resizeBuffers.Get().(*[]float64)
+	defer resizeBuffers.Put(buf)
+	if cap(*buf) < dw*sh*4 {
+		*buf = make([]float64, dw*sh*4)
+	}
+	tmp := (*buf)[:dw*sh*4]
+	for x, w := range weights(sw, dw, k) {
+		for y := 0; y < sh; y++ {
+			var p [4]float64
+			for i, c := range w.coeffs {
+				off := src.PixOffset(sb.Min.X+w.first+i, sb.Min.Y+y)
+				for j := range p {
+					p[j] += c * float64(src.Pix[off+j])
+				}
+			}
+			copy(tmp[(y*dw+x)*4:], p[:])
+		}
+	}
+
+	// Vertical pass into the destination
+	for y, w := range weights(sh, dh, k) {
+		for x := 0; x < dw; x++ {
+			var p [4]float64
+			for i, c := range w.coeffs {
+				off := ((w.first+i)*dw + x) * 4
+				for j := range p {
+					p[j] += c * tmp[off+j]
+				}
+			}
+
+			// Keep the premultiplied invariant (color <= alpha) despite
+			// the negative lobes of the filter
+			a := clampUint8(p[3])
+			off := dst.PixOffset(dst.Rect.Min.X+x, dst.Rect.Min.Y+y)
+			dst.Pix[off+0] = min(clampUint8(p[0]), a)
+			dst.Pix[off+1] = min(clampUint8(p[1]), a)
+			dst.Pix[off+2] = min(clampUint8(p[2]), a)
+			dst.Pix[off+3] = a
+		}
+	}
+}
+
+// contribution lists the source samples that make up one destination pixel.
+type contribution struct {
+	first  int       // index of the first source sample
+	coeffs []float64 // normalized weights starting at first
+}
+
+// weightsKey identifies the filter weights for a resampling.
+type weightsKey struct {
+	n, m   int
+	filter Filter
+}
+
+// maxCachedWeights bounds the number of resamplings whose weights are kept,
+// as sizes may come from requests.
+const maxCachedWeights = 256
+
+// cachedWeights keeps the filter weights of resamplings, which are the same
+// for every monster of a size.
+var cachedWeights = struct {
+	sync.RWMutex
+	byKey map[weightsKey][]contribution
+}{byKey: make(map[weightsKey][]contribution)}
+
+// Helper to get the filter weights for resampling n source samples to m,
+// computing them once. The result must not be modified.
+func weights(n, m int, k kernel) []contribution {
+	key := weightsKey{n, m, k.filter}
+	cachedWeights.RLock()
+	w, ok := cachedWeights.byKey[key]
+	cachedWeights.RUnlock()
+	if ok {
+		return w
+	}
+
+	w = computeWeights(n, m, k)
+	cachedWeights.Lock()
+	if len(cachedWeights.byKey) < maxCachedWeights {
+		cachedWeights.byKey[key] = w
+	}
+	cachedWeights.Unlock()
+
+	return w
+}
+
+// Helper to compute filter weights for resampling n source samples to m
+func computeWeights(n, m int, k kernel) []contribution {
+	scale := float64(n) / float64(m)
+	// Widen the filter when downscaling so every source sample contributes
+	filterScale := math.Max(scale, 1)
+	support := k.support * filterScale
+
+	out := make([]contribution, m)
+	if k.at == nil {
+		for i := range out {
+			first := min(int((float64(i)+0.5)*scale), n-1)
+			out[i] = contribution{first: first, coeffs: []float64{1}}
+		}
+		return out
+	}
+
+	for i := range out {
+		center := (float64(i)+0.5)*scale - 0.5
+		first := max(int(math.Ceil(center-support)), 0)
+		last := min(int(math.Floor(center+support)), n-1)
+
+		coeffs := make([]float64, 0, last-first+1)
+		sum := 0.0
+		for j := first; j <= last; j++ {
+			c := k.at((float64(j) - center) / filterScale)
+			coeffs = append(coeffs, c)
+			sum += c
+		}
+		if sum != 0 {
+			for j := range coeffs {
+				coeffs[j] /= sum
+			}
+		}
+
+		out[i] = contribution{first: first, coeffs: coeffs}
+	}
+
+	return out
+}
+
+// Helper to round and clamp a channel value to the 0-255 range
+func clampUint8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return uint8(v + 0.5)
+}
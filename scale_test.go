@@ -0,0 +1,103 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+)
+
+var filters = []Filter{CatmullRom, NearestNeighbor, Bilinear, Lanczos}
+
+func TestWeightsAreNormalized(t *testing.T) {
+	for _, f := range filters {
+		for _, m := range []int{16, 64, 120, 256, 512} {
+			for i, w := range weights(nativeSize, m, f.kernel()) {
+				sum := 0.0
+				for _, c := range w.coeffs {
+					sum += c
+				}
+				if math.Abs(sum-1) > 1e-9 {
+					t.Fatalf("Filter %d scaling to %d: weights for pixel %d sum to %f", f, m, i, sum)
+				}
+			}
+		}
+	}
+}
+
+func TestScaleImageIdentity(t *testing.T) {
+	src := New([]byte("scale-identity")).(*image.RGBA)
+
+	for _, f := range filters {
+		dst := scaleImage(src, nativeSize, f.kernel())
+		if !bytes.Equal(src.Pix, dst.Pix) {
+			t.Errorf("Filter %d: scaling to the native size changed the image", f)
+		}
+	}
+}
+
+func TestScaleImageKeepsPremultipliedInvariant(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Background.A = 0
+	src := New([]byte("scale-premultiplied"), opts).(*image.RGBA)
+
+	for _, f := range filters {
+		for _, size := range []int{48, 200} {
+			dst := scaleImage(src, size, f.kernel())
+			for i := 0; i < len(dst.Pix); i += 4 {
+				a := dst.Pix[i+3]
+				if dst.Pix[i] > a || dst.Pix[i+1] > a || dst.Pix[i+2] > a {
+					t.Fatalf("Filter %d size %d: color exceeds alpha at offset %d", f, size, i)
+				}
+			}
+		}
+	}
+}
+
+func TestNearestNeighborDuplicatesPixels(t *testing.T) {
+	src := New([]byte("scale-nearest")).(*image.RGBA)
+	dst := New([]byte("scale-nearest"), WithSize(2*nativeSize), WithFilter(NearestNeighbor)).(*image.RGBA)
+
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			want := src.RGBAAt(x, y)
+			for _, p := range []image.Point{{2 * x, 2 * y}, {2*x + 1, 2 * y}, {2 * x, 2*y + 1}, {2*x + 1, 2*y + 1}} {
+				if got := dst.RGBAAt(p.X, p.Y); got != want {
+					t.Fatalf("Pixel %v: expected %v, got %v", p, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestFiltersProduceDifferentImages(t *testing.T) {
+	hash := []byte("scale-filters")
+
+	seen := map[string]Filter{}
+	for _, f := range filters {
+		img := New(hash, WithSize(300), WithFilter(f)).(*image.RGBA)
+		if prev, ok := seen[string(img.Pix)]; ok {
+			t.Errorf("Filters %d and %d produced identical images", prev, f)
+		}
+		seen[string(img.Pix)] = f
+	}
+}
+
+func TestPartScale(t *testing.T) {
+	tests := []struct {
+		size, want int
+	}{
+		{64, 1},
+		{120, 1},
+		{121, 2},
+		{240, 2},
+		{480, 4},
+		{1024, 4},
+	}
+
+	for _, test := range tests {
+		if got := partScale(test.size); got != test.want {
+			t.Errorf("Expected scale %d for size %d, got %d", test.want, test.size, got)
+		}
+	}
+}
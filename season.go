@@ -0,0 +1,121 @@
+package monsterid
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"path"
+	"time"
+)
+
+// Season is an overlay drawn over every monster during a range of days of
+// the year, such as a santa hat in December. The monster itself is the same
+// in and out of season.
+type Season struct {
+	Name  string      // identifies the season in IDs, and the built-in artwork if Image is nil
+	From  Day         // first day of the season
+	To    Day         // last day of the season, before From to wrap around the new year
+	Image image.Image // drawn over the monster and scaled to its size
+}
+
+// Day is a day of the year, such as Day{time.December, 24}.
+type Day struct {
+	Month time.Month
+	Day   int
+}
+
+// Seasons returns the built-in seasons: a pumpkin in October and a santa hat
+// in December.
+func Seasons() []Season {
+	return []Season{
+		{Name: "pumpkin", From: Day{time.October, 1}, To: Day{time.October, 31}},
+		{Name: "santa-hat", From: Day{time.December, 1}, To: Day{time.December, 31}},
+	}
+}
+
+// Helper to check whether the date t falls in the season
+func (s Season) contains(t time.Time) bool {
+	day := func(m time.Month, d int) int { return int(m)*100 + d }
+	from, to, at := day(s.From.Month, s.From.Day), day(s.To.Month, s.To.Day), day(t.Month(), t.Day())
+	if from <= to {
+		return from <= at && at <= to
+	}
+
+	return at >= from || at <= to
+}
+
+// Helper to get the first season containing Options.Date, or today
+func (o Options) season() (Season, bool) {
+	if len(o.Seasons) == 0 {
+		return Season{}, false
+	}
+
+	date := o.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	for _, s := range o.Seasons {
+		if s.contains(date) {
+			return s, true
+		}
+	}
+
+	return Season{}, false
+}
+
+// Helper to load the overlay of a season at scale times the native size
+func loadSeason(s Season, scale int) (*image.RGBA, error) {
+	if s.Image == nil {
+		img, err := loadEmbeddedPart(path.Join("parts", "seasons"), s.Name+".png", scale)
+		if err != nil {
+			return nil, fmt.Errorf("monsterid: load season %s: %w", s.Name, err)
+		}
+		return img, nil
+	}
+
+	// Copy so the caller's image is never modified
+	b := s.Image.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Bounds(), s.Image, b.Min, draw.Src)
+	if b.Dx() != b.Dy() {
+		img = resizeImage(img, b.Dx(), b.Dx(), catmullRom)
+	}
+
+	return img, nil
+}
+
+// overlay is an image drawn over the monster after mirroring.
+type overlay struct {
+	name string
+	img  *image.RGBA
+}
+
+// Helper to load the accessory and seasonal overlay of a monster, in
+// drawing order
+func loadOverlays(d Descriptor, o Options, scale int) ([]overlay, error) {
+	var overlays []overlay
+	if d.Accessory != AccessoryNone {
+		img, err := loadAccessory(d.Accessory, scale)
+		if err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, overlay{"accessory", img})
+	}
+	if s, ok := o.season(); ok {
+		img, err := loadSeason(s, scale)
+		if err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, overlay{"season", img})
+	}
+
+	// Greyed on a copy, loaded overlays are shared
+	if o.tone() == ToneGreyscale {
+		for i := range overlays {
+			overlays[i].img = cloneImage(overlays[i].img)
+			colorizeImage(overlays[i].img, 0, 0, 0, false)
+		}
+	}
+
+	return overlays, nil
+}
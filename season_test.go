@@ -0,0 +1,92 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeasonContains(t *testing.T) {
+	winter := Season{Name: "winter", From: Day{time.December, 21}, To: Day{time.March, 20}}
+	october := Season{Name: "october", From: Day{time.October, 1}, To: Day{time.October, 31}}
+
+	tests := []struct {
+		season Season
+		date   time.Time
+		want   bool
+	}{
+		{october, time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC), true},
+		{october, time.Date(2024, time.October, 31, 23, 59, 0, 0, time.UTC), true},
+		{october, time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC), false},
+		{october, time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC), false},
+		{winter, time.Date(2024, time.December, 25, 0, 0, 0, 0, time.UTC), true},
+		{winter, time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC), true},
+		{winter, time.Date(2025, time.March, 21, 0, 0, 0, 0, time.UTC), false},
+		{winter, time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, test := range tests {
+		if got := test.season.contains(test.date); got != test.want {
+			t.Errorf("Expected %s to contain %s: %v, got %v", test.season.Name, test.date.Format(time.DateOnly), test.want, got)
+		}
+	}
+}
+
+func TestSeasonOverlay(t *testing.T) {
+	hash := []byte("season-overlay")
+	plain := New(hash).(*image.RGBA)
+
+	for _, s := range Seasons() {
+		in := New(hash, WithSeasons(Seasons()...), WithDate(time.Date(2024, s.From.Month, s.From.Day, 12, 0, 0, 0, time.UTC))).(*image.RGBA)
+		if bytes.Equal(plain.Pix, in.Pix) {
+			t.Errorf("Season %s did not change the image", s.Name)
+		}
+
+		// The monster stays the same
+		d := Describe(hash, WithSeasons(Seasons()...))
+		if d != Describe(hash) {
+			t.Errorf("Season %s changed the descriptor", s.Name)
+		}
+	}
+
+	out := New(hash, WithSeasons(Seasons()...), WithDate(time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC))).(*image.RGBA)
+	if !bytes.Equal(plain.Pix, out.Pix) {
+		t.Error("Out of season overlay changed the image")
+	}
+}
+
+func TestCustomSeason(t *testing.T) {
+	// A red square in the top left corner, at twice the native size
+	img := image.NewRGBA(image.Rect(0, 0, 2*nativeSize, 2*nativeSize))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	s := Season{Name: "red", From: Day{time.May, 1}, To: Day{time.May, 1}, Image: img}
+	date := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+
+	hash := []byte("season-custom")
+	got := New(hash, WithSeasons(s), WithDate(date)).(*image.RGBA)
+	if c := got.RGBAAt(5, 5); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("Expected the overlay color, got %v", c)
+	}
+
+	if id := ID(hash, WithSeasons(s), WithDate(date)); !strings.HasSuffix(id, "-red") {
+		t.Errorf("Expected an ID ending in -red, got %s", id)
+	}
+	if id := ID(hash, WithSeasons(s), WithDate(date.AddDate(0, 0, 1))); id != ID(hash) {
+		t.Errorf("Expected the plain ID out of season, got %s", id)
+	}
+}
+
+func TestUnknownSeason(t *testing.T) {
+	s := Season{Name: "easter", From: Day{time.April, 1}, To: Day{time.April, 1}}
+	_, err := NewWithError([]byte("season-unknown"), WithSeasons(s), WithDate(time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)))
+	if err == nil || !strings.Contains(err.Error(), "easter") {
+		t.Errorf("Expected a load error, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Shadow is a soft drop shadow cast by the monster's silhouette.
+type Shadow struct {
+	Radius  int         // blur radius in pixels
+	Offset  image.Point // displacement of the shadow in pixels
+	Opacity float64     // opacity of the shadow, no shadow if zero
+	Color   color.RGBA  // color of the shadow, alpha is ignored (black if zero)
+}
+
+// Helper to draw the shadow of layer positioned at at into dst, clipped to rect
+func drawShadow(dst draw.Image, rect image.Rectangle, at image.Point, layer *image.RGBA, s Shadow) {
+	mask := image.NewAlpha(layer.Bounds())
+	for i := range mask.Pix {
+		mask.Pix[i] = layer.Pix[i*4+3]
+	}
+
+	// Three box blurs approximate a gaussian blur
+	if s.Radius > 0 {
+		r := max(1, s.Radius/3)
+		for range 3 {
+			boxBlur(mask, r)
+		}
+	}
+
+	a := clampUint8(min(s.Opacity, 1) * 255)
+	c := color.RGBA{
+		R: uint8(uint32(s.Color.R) * uint32(a) / 255),
+		G: uint8(uint32(s.Color.G) * uint32(a) / 255),
+		B: uint8(uint32(s.Color.B) * uint32(a) / 255),
+		A: a,
+	}
+
+	// The shadow is clipped to the avatar
+	origin := at.Add(s.Offset)
+	target := mask.Bounds().Add(origin).Intersect(rect)
+	sp := target.Min.Sub(origin)
+	draw.DrawMask(dst, target, &image.Uniform{C: c}, image.Point{}, mask, sp, draw.Over)
+}
+
+// Helper to blur an alpha image in place with a box of the given radius
+func boxBlur(img *image.Alpha, r int) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	line := make([]uint8, max(w, h))
+
+	blur := func(n int, get func(i int) uint8, set func(i int, v uint8)) {
+		for i := 0; i < n; i++ {
+			line[i] = get(i)
+		}
+
+		// Sliding window sum, samples outside the image are transparent
+		sum := 0
+		for i := 0; i <= r && i < n; i++ {
+			sum += int(line[i])
+		}
+		for i := 0; i < n; i++ {
+			set(i, uint8(sum/(2*r+1)))
+			if j := i + r + 1; j < n {
+				sum += int(line[j])
+			}
+			if j := i - r; j >= 0 {
+				sum -= int(line[j])
+			}
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		blur(w, func(i int) uint8 { return row[i] }, func(i int, v uint8) { row[i] = v })
+	}
+	for x := 0; x < w; x++ {
+		blur(h, func(i int) uint8 { return img.Pix[i*img.Stride+x] }, func(i int, v uint8) { img.Pix[i*img.Stride+x] = v })
+	}
+}
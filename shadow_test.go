@@ -0,0 +1,69 @@
+package monsterid
+
+import (
+	"image"
+	"testing"
+)
+
+func TestShadowDarkensBackground(t *testing.T) {
+	hash := []byte("shadow-test")
+
+	plain := New(hash).(*image.RGBA)
+	shadowed := New(hash, WithShadow(6, image.Pt(4, 4), 0.5)).(*image.RGBA)
+
+	darker := 0
+	for i := 0; i < len(plain.Pix); i += 4 {
+		if shadowed.Pix[i] > plain.Pix[i] {
+			t.Fatalf("Shadow lightened the pixel at offset %d", i)
+		}
+		if shadowed.Pix[i] < plain.Pix[i] {
+			darker++
+		}
+	}
+	if darker == 0 {
+		t.Error("Shadow did not darken any pixel")
+	}
+
+	// The corner is far from the monster and stays untouched
+	if shadowed.RGBAAt(0, 0) != plain.RGBAAt(0, 0) {
+		t.Error("Shadow reached the corner of the avatar")
+	}
+}
+
+func TestShadowOnTransparentBackground(t *testing.T) {
+	hash := []byte("shadow-transparent")
+
+	plain := New(hash, WithTransparentBackground()).(*image.RGBA)
+	shadowed := New(hash, WithTransparentBackground(), WithShadow(4, image.Pt(3, 3), 0.6)).(*image.RGBA)
+
+	// The shadow adds translucent pixels around the monster
+	added := 0
+	for i := 3; i < len(plain.Pix); i += 4 {
+		if plain.Pix[i] == 0 && shadowed.Pix[i] > 0 {
+			added++
+			if shadowed.Pix[i] == 255 {
+				t.Fatalf("Shadow pixel at offset %d is fully opaque", i)
+			}
+		}
+	}
+	if added == 0 {
+		t.Error("Shadow did not add any translucent pixels")
+	}
+}
+
+func TestBoxBlurPreservesUniformImage(t *testing.T) {
+	img := image.NewAlpha(image.Rect(0, 0, 20, 20))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+
+	boxBlur(img, 2)
+
+	// Only the edges fade into the transparent surroundings
+	if a := img.Pix[img.PixOffset(10, 10)]; a != 200 {
+		t.Errorf("Expected center alpha 200, got %d", a)
+	}
+	if a := img.Pix[img.PixOffset(0, 0)]; a >= 200 {
+		t.Errorf("Expected corner to fade, got %d", a)
+	}
+}
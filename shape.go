@@ -0,0 +1,73 @@
+package monsterid
+
+import (
+	"image"
+	"math"
+)
+
+// Shape is the outline of the avatar.
+type Shape int
+
+const (
+	ShapeSquare  Shape = iota // the full square canvas
+	ShapeCircle               // a circle touching the edges
+	ShapeRounded              // a square with rounded corners
+)
+
+// Helper to build an anti-aliased alpha mask for the shape
+func shapeMask(shape Shape, size, radius int) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			// Coverage falls off linearly over one pixel around the edge
+			cover := 0.5 - shapeDistance(shape, size, radius, float64(x)+0.5, float64(y)+0.5)
+			mask.Pix[mask.PixOffset(x, y)] = clampUint8(cover * 255)
+		}
+	}
+
+	return mask
+}
+
+// Helper to build an anti-aliased alpha mask for a band of the given width
+// along the inside edge of the shape
+func borderMask(shape Shape, size, radius, width int) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dist := shapeDistance(shape, size, radius, float64(x)+0.5, float64(y)+0.5)
+			outer := math.Max(0, math.Min(1, 0.5-dist))
+			inner := math.Max(0, math.Min(1, 0.5-dist-float64(width)))
+			mask.Pix[mask.PixOffset(x, y)] = clampUint8((outer - inner) * 255)
+		}
+	}
+
+	return mask
+}
+
+// Helper to compute the signed distance from (px, py) to the shape's edge,
+// negative inside the shape
+func shapeDistance(shape Shape, size, radius int, px, py float64) float64 {
+	half := float64(size) / 2
+
+	var r float64
+	switch shape {
+	case ShapeCircle:
+		r = half
+	case ShapeRounded:
+		r = float64(radius)
+		if radius <= 0 {
+			r = float64(size) / 8
+		}
+		r = math.Min(r, half)
+	default:
+		r = 0
+	}
+
+	// Distance to a box of the given half size with rounded corners
+	qx := math.Abs(px-half) - (half - r)
+	qy := math.Abs(py-half) - (half - r)
+	outside := math.Hypot(math.Max(qx, 0), math.Max(qy, 0))
+	inside := math.Min(math.Max(qx, qy), 0)
+
+	return outside + inside - r
+}
@@ -0,0 +1,94 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCircleShape(t *testing.T) {
+	img := New([]byte("circle-test"), WithShape(ShapeCircle)).(*image.RGBA)
+
+	if a := img.RGBAAt(0, 0).A; a != 0 {
+		t.Errorf("Expected transparent corner, got alpha %d", a)
+	}
+	if c := img.RGBAAt(nativeSize/2, 1); c.A != 255 {
+		t.Errorf("Expected opaque top edge center, got %v", c)
+	}
+
+	// The edge is anti-aliased
+	partial := false
+	for x := 0; x < nativeSize; x++ {
+		if a := img.RGBAAt(x, 10).A; a > 0 && a < 255 {
+			partial = true
+		}
+	}
+	if !partial {
+		t.Error("Expected anti-aliased pixels along the circle edge")
+	}
+}
+
+func TestRoundedShape(t *testing.T) {
+	img := New([]byte("rounded-test"), WithRoundedCorners(20)).(*image.RGBA)
+
+	if a := img.RGBAAt(0, 0).A; a != 0 {
+		t.Errorf("Expected transparent corner, got alpha %d", a)
+	}
+	if a := img.RGBAAt(30, 0).A; a != 255 {
+		t.Errorf("Expected opaque edge past the corner radius, got alpha %d", a)
+	}
+	if a := img.RGBAAt(0, nativeSize/2).A; a != 255 {
+		t.Errorf("Expected opaque left edge center, got alpha %d", a)
+	}
+}
+
+func TestShapeKeepsDestinationCorners(t *testing.T) {
+	banner := color.RGBA{R: 0, G: 0, B: 255, A: 255}
+	dst := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	for i := 0; i < len(dst.Pix); i += 4 {
+		dst.Pix[i+2], dst.Pix[i+3] = 255, 255
+	}
+
+	if err := DrawTo(dst, image.Pt(20, 20), []byte("shape-banner"), WithShape(ShapeCircle)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if c := dst.RGBAAt(20, 20); c != banner {
+		t.Errorf("Expected banner color outside the circle, got %v", c)
+	}
+}
+
+func TestSquareShapeIsUnmasked(t *testing.T) {
+	mask := shapeMask(ShapeSquare, 16, 0)
+	for i, a := range mask.Pix {
+		if a != 255 {
+			t.Fatalf("Square mask is not opaque at offset %d: %d", i, a)
+		}
+	}
+}
+
+func TestBorder(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+
+	img := New([]byte("border-test"), WithBorder(4, red)).(*image.RGBA)
+	for _, p := range []image.Point{{0, 0}, {3, 60}, {60, nativeSize - 1}, {nativeSize - 4, 60}} {
+		if c := img.RGBAAt(p.X, p.Y); c != red {
+			t.Errorf("Expected border color at %v, got %v", p, c)
+		}
+	}
+	if c := img.RGBAAt(5, 5); c == red {
+		t.Errorf("Border is wider than requested")
+	}
+}
+
+func TestBorderFollowsShape(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+
+	img := New([]byte("border-circle"), WithShape(ShapeCircle), WithBorder(4, red)).(*image.RGBA)
+	if a := img.RGBAAt(0, 0).A; a != 0 {
+		t.Errorf("Expected transparent corner, got alpha %d", a)
+	}
+	if c := img.RGBAAt(nativeSize/2, 1); c != red {
+		t.Errorf("Expected border color at the top of the circle, got %v", c)
+	}
+}
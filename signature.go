@@ -0,0 +1,56 @@
+package monsterid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"net/url"
+)
+
+// signatureParam is the query parameter of the signature of signed avatars.
+const signatureParam = "sig"
+
+// Sign returns the signature of an avatar URL for a Handler with
+// HandlerConfig.Secret, to add to the query as the sig parameter. The path is
+// the unescaped path as the Handler sees it, after any http.StripPrefix, such
+// as /alice or /avatar/ followed by the digest of a Gravatar URL with its
+// extension, and query the other parameters, in any order.
+func Sign(secret []byte, path string, query url.Values) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signedMessage(path, query)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Helper to get the signed form of an avatar URL, with the parameters sorted
+// and without the signature
+func signedMessage(path string, query url.Values) string {
+	if query.Has(signatureParam) {
+		query = maps.Clone(query)
+		query.Del(signatureParam)
+	}
+
+	return path + "?" + query.Encode()
+}
+
+// Helper to only pass requests with a valid signature on to next, if the
+// handler has a secret
+func (h *handler) signed(next http.HandlerFunc) http.HandlerFunc {
+	if len(h.cfg.Secret) == 0 {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		sig, err := hex.DecodeString(query.Get(signatureParam))
+		want, _ := hex.DecodeString(Sign(h.cfg.Secret, r.URL.Path, query))
+		if err != nil || !hmac.Equal(sig, want) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
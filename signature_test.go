@@ -0,0 +1,82 @@
+package monsterid
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestHandlerSecret(t *testing.T) {
+	secret := []byte("secret")
+	h := Handler(HandlerConfig{Secret: secret})
+
+	tests := []struct {
+		hash  string
+		query url.Values
+		route string
+	}{
+		{"alice", url.Values{}, "/"},
+		{"alice", url.Values{"s": {"64"}, "format": {"svg"}}, "/"},
+		{"0bc83cb571cd1c50ba6f3e8a78ef1346.png", url.Values{"s": {"40"}, "d": {"monsterid"}}, "/avatar/"},
+	}
+
+	for _, test := range tests {
+		path := test.route + test.hash
+		signed := url.Values{signatureParam: {Sign(secret, path, test.query)}}
+		for k, v := range test.query {
+			signed[k] = v
+		}
+		target := test.route + test.hash + "?" + signed.Encode()
+		if rec := serve(h, http.MethodGet, target); rec.Code != http.StatusOK {
+			t.Errorf("Expected %s to be served, got %d", target, rec.Code)
+		}
+
+		// Anything else is forbidden
+		unsigned := test.route + test.hash + "?" + test.query.Encode()
+		signed.Set("s", "1024")
+		tampered := test.route + test.hash + "?" + signed.Encode()
+		other := test.route + "bob?" + url.Values{signatureParam: {Sign(secret, path, test.query)}}.Encode()
+		wrongKey := test.route + test.hash + "?sig=" + Sign([]byte("other"), path, test.query) + "&" + test.query.Encode()
+		for _, target := range []string{unsigned, tampered, other, wrongKey, test.route + test.hash + "?sig=zz"} {
+			if rec := serve(h, http.MethodGet, target); rec.Code != http.StatusForbidden {
+				t.Errorf("Expected %s to be forbidden, got %d", target, rec.Code)
+			}
+		}
+	}
+
+	// Without a secret signatures are ignored
+	if rec := serve(Handler(HandlerConfig{}), http.MethodGet, "/alice?sig=zz"); rec.Code != http.StatusOK {
+		t.Errorf("Expected unsigned handlers to ignore signatures, got %d", rec.Code)
+	}
+}
+
+func TestSignIgnoresOrder(t *testing.T) {
+	secret := []byte("secret")
+	a := Sign(secret, "/alice", url.Values{"s": {"64"}, "format": {"png"}})
+	b := Sign(secret, "/alice", url.Values{"format": {"png"}, "s": {"64"}, signatureParam: {"ignored"}})
+	if a != b || len(a) != 64 {
+		t.Errorf("Expected the same signature regardless of order, got %s and %s", a, b)
+	}
+}
+
+func TestSignCoversPath(t *testing.T) {
+	secret := []byte("secret")
+	h := Handler(HandlerConfig{Secret: secret})
+	const hash = "0bc83cb571cd1c50ba6f3e8a78ef1346"
+
+	// A signature is only valid for the path it was made for
+	sig := url.Values{signatureParam: {Sign(secret, "/"+hash, url.Values{})}}.Encode()
+	if rec := serve(h, http.MethodGet, "/"+hash+"?"+sig); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the signed path to be served, got %d", rec.Code)
+	}
+	if rec := serve(h, http.MethodGet, "/avatar/"+hash+"?"+sig); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the signature not to carry over to another path, got %d", rec.Code)
+	}
+
+	// Paths are signed as the handler sees them under a prefix
+	mux := http.NewServeMux()
+	mux.Handle("/avatars/", http.StripPrefix("/avatars", h))
+	if rec := serve(mux, http.MethodGet, "/avatars/"+hash+"?"+sig); rec.Code != http.StatusOK {
+		t.Errorf("Expected the signed path to be served under a prefix, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package monsterid
+
+import (
+	"math"
+)
+
+// Weights of the parts and the body color in Similarity, the larger and more
+// colorful parts dominate how a monster looks.
+const (
+	similarityBody  = 0.25
+	similarityEyes  = 0.15
+	similarityMouth = 0.10
+	similarityHair  = 0.10
+	similarityArms  = 0.10
+	similarityLegs  = 0.10
+	similarityHue   = 0.20
+)
+
+// Similarity returns how alike the monsters for two hashes look, from 0.0
+// for entirely different to 1.0 for identical parts and body color.
+func Similarity(hashA, hashB []byte, opts ...Option) float64 {
+	o := buildOptions(opts)
+	return similarity(describeHash(hashA, o), describeHash(hashB, o), o)
+}
+
+// Helper to compare the parts and body hue of two descriptors
+func similarity(a, b Descriptor, o Options) float64 {
+	s := 0.0
+	for _, p := range []struct {
+		a, b   int
+		weight float64
+	}{
+		{a.Body, b.Body, similarityBody},
+		{a.Eyes, b.Eyes, similarityEyes},
+		{a.Mouth, b.Mouth, similarityMouth},
+		{a.Hair, b.Hair, similarityHair},
+		{a.Arms, b.Arms, similarityArms},
+		{a.Legs, b.Legs, similarityLegs},
+	} {
+		if p.a == p.b {
+			s += p.weight
+		}
+	}
+
+	// Without colorization the body color only depends on the body part
+	if !o.Artistic || o.tone() != ToneNone {
+		if a.Body == b.Body {
+			s += similarityHue
+		}
+		return s
+	}
+
+	// Hues wrap around, so the largest distance is half a turn
+	d := math.Abs(a.Hue - b.Hue)
+	d = math.Min(d, 1-d)
+	return s + similarityHue*(1-2*d)
+}
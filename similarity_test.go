@@ -0,0 +1,50 @@
+package monsterid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarityIdentical(t *testing.T) {
+	hash := []byte("similarity")
+	if s := Similarity(hash, hash); math.Abs(s-1) > 1e-9 {
+		t.Errorf("Expected 1 for the same hash, got %v", s)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a := Descriptor{Legs: 1, Hair: 1, Arms: 1, Body: 1, Eyes: 1, Mouth: 1, Hue: 0.95}
+
+	tests := []struct {
+		description string
+		modify      func(d *Descriptor)
+		want        float64
+	}{
+		{"opposite hue", func(d *Descriptor) { d.Hue = 0.45 }, 0.8},
+		{"close hue across red", func(d *Descriptor) { d.Hue = 0.05 }, 0.96},
+		{"different body", func(d *Descriptor) { d.Body = 2 }, 0.75},
+		{"nothing shared", func(d *Descriptor) { *d = Descriptor{Legs: 2, Hair: 2, Arms: 2, Body: 2, Eyes: 2, Mouth: 2, Hue: 0.45} }, 0},
+	}
+
+	o := DefaultOptions()
+	for _, test := range tests {
+		b := a
+		test.modify(&b)
+		if got := similarity(a, b, o); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Expected %v for %s, got %v", test.want, test.description, got)
+		}
+		if got := similarity(b, a, o); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Similarity is not symmetric for %s", test.description)
+		}
+	}
+}
+
+func TestSimilarityWithoutColors(t *testing.T) {
+	a := Descriptor{Legs: 1, Hair: 1, Arms: 1, Body: 1, Eyes: 1, Mouth: 1, Hue: 0}
+	b := a
+	b.Hue = 0.5
+
+	if got := similarity(a, b, Options{}); got != 1 {
+		t.Errorf("Expected hue to be ignored without artistic mode, got %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package monsterid
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Sink stores pre-rendered avatars by key, such as DirSink, S3Sink or a client
+// of another object store. Pregenerate calls Put from several goroutines, so
+// it must be safe for concurrent use. Data passed to Put must not be
+// modified.
+type Sink interface {
+	// Put stores data of contentType, such as image/png, at key, replacing
+	// what is stored there.
+	Put(ctx context.Context, key, contentType string, data []byte) error
+}
+
+// DirSink is a Sink of files in a directory, such as the document root of a
+// web server or a directory synchronized to a CDN. Keys are slash-separated
+// paths below the directory.
+type DirSink struct {
+	dir string // directory of the files
+}
+
+// NewDirSink creates a DirSink storing files in dir, which is created if it
+// doesn't exist.
+func NewDirSink(dir string) (*DirSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+
+	return &DirSink{dir: dir}, nil
+}
+
+// Put stores data at the file of key, creating its directories. The file is
+// replaced at once, so a web server never serves part of it. The content type
+// is left to the server, which knows it from the extension of the key.
+func (s *DirSink) Put(_ context.Context, key, _ string, data []byte) error {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("monsterid: dir sink: invalid key %q", key)
+	}
+	path := filepath.Join(s.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+	// Temporary files are private, but avatars are served to anyone
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("monsterid: dir sink: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package monsterid
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirSink(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "avatars")
+	s, err := NewDirSink(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := s.Put(ctx, "80/alice.png", "image/png", []byte("first")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.Put(ctx, "80/alice.png", "image/png", []byte("second")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "80", "alice.png"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("Expected the file to be replaced, got %q", data)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "80", "alice.png"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o644 {
+		t.Errorf("Expected a readable file, got %v", perm)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "80"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to remain, got %d files", len(entries))
+	}
+
+	for _, key := range []string{"../alice.png", "/alice.png", ""} {
+		if err := s.Put(ctx, key, "image/png", []byte("x")); err == nil {
+			t.Errorf("Expected an error for key %q", key)
+		}
+	}
+}
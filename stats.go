@@ -0,0 +1,64 @@
+package monsterid
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// hueBuckets is the number of equal hue ranges counted by Generator.Stats.
+const hueBuckets = 12
+
+// Stats is how often a Generator selected each part and body hue, to check
+// that monsters are spread evenly over a pack.
+type Stats struct {
+	Monsters int64              // number of monsters generated
+	Parts    map[string][]int64 // selections per category, indexed by part number - 1
+	Hues     [hueBuckets]int64  // selections per body hue range of 30 degrees, starting at red
+}
+
+// usage counts the selections of a Generator.
+type usage struct {
+	mu       sync.Mutex
+	monsters int64
+	parts    map[string][]int64
+	hues     [hueBuckets]int64
+}
+
+// Stats returns how often each part and body hue was selected by Generate,
+// GenerateContext and DrawTo since the Generator was created.
+func (g *Generator) Stats() Stats {
+	g.usage.mu.Lock()
+	defer g.usage.mu.Unlock()
+
+	s := Stats{Monsters: g.usage.monsters, Parts: maps.Clone(g.usage.parts), Hues: g.usage.hues}
+	for part, counts := range s.Parts {
+		s.Parts[part] = slices.Clone(counts)
+	}
+	if s.Parts == nil {
+		s.Parts = make(map[string][]int64)
+	}
+
+	return s
+}
+
+// Helper to count the parts and body hue selected for a monster
+func (u *usage) record(d Descriptor, counts partCounts) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.parts == nil {
+		u.parts = make(map[string][]int64, len(bodyParts))
+	}
+	u.monsters++
+	for _, part := range bodyParts {
+		// Reloaded packs may have more parts
+		if n := counts.count(part); len(u.parts[part]) < n {
+			u.parts[part] = append(u.parts[part], make([]int64, n-len(u.parts[part]))...)
+		}
+		if n := getPartNumber(&d, part); n >= 1 && n <= len(u.parts[part]) {
+			u.parts[part][n-1]++
+		}
+	}
+	u.hues[min(int(d.Hue*hueBuckets), hueBuckets-1)]++
+}
@@ -0,0 +1,60 @@
+package monsterid
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+func TestGeneratorStats(t *testing.T) {
+	g, err := NewGeneratorFromFS(testPack(t, 2))
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		hash := []byte(fmt.Sprintf("stats-%d", i))
+		if i%2 == 0 {
+			_, err = g.Generate(hash, WithSize(16))
+		} else {
+			err = g.DrawTo(image.NewRGBA(image.Rect(0, 0, 16, 16)), image.Point{}, hash, WithSize(16))
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	g.Describe([]byte("not counted"))
+
+	s := g.Stats()
+	if s.Monsters != n {
+		t.Errorf("Expected %d monsters, got %d", n, s.Monsters)
+	}
+	for _, part := range bodyParts {
+		counts := s.Parts[part]
+		if len(counts) != 2 {
+			t.Fatalf("Expected counts for 2 %s parts, got %v", part, counts)
+		}
+		if counts[0]+counts[1] != n || counts[0] < n/4 || counts[1] < n/4 {
+			t.Errorf("Expected %d %s parts spread over both parts, got %v", n, part, counts)
+		}
+	}
+
+	var hues int64
+	for _, count := range s.Hues {
+		if count == 0 {
+			t.Errorf("Expected every hue range to be selected, got %v", s.Hues)
+			break
+		}
+		hues += count
+	}
+	if hues != n {
+		t.Errorf("Expected %d hues, got %d", n, hues)
+	}
+
+	// The returned stats are a copy
+	s.Parts["body"][0] = -1
+	if g.Stats().Parts["body"][0] == -1 {
+		t.Error("Expected Stats to return a copy")
+	}
+}
@@ -0,0 +1,296 @@
+package monsterid
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Style selects the kind of avatar drawn for a hash. Every style gives a
+// different but stable avatar for the same hash, so apps can let users pick
+// the style of their default avatar. Other packages can add styles with
+// RegisterStyle.
+type Style string
+
+const (
+	StyleMonster   Style = "monster"   // monsters composed of part artwork
+	StyleIdenticon Style = "identicon" // symmetric 5x5 blocks like GitHub identicons
+	StyleWavatar   Style = "wavatar"   // faces with eyes, brows and a mouth like Wavatar
+	StyleRobohash  Style = "robohash"  // robots with colored chassis like RoboHash
+	StyleCat       Style = "cat"       // cats with fur patterns, whiskers and accessories
+	StylePixel     Style = "pixel"     // 8-bit monsters in a restricted palette
+	StyleInitials  Style = "initials"  // one or two letters of Options.Initials on a colored square
+	StyleBlockies  Style = "blockies"  // 8x8 mirrored cells like Ethereum blockies of the address
+	StyleRings     Style = "rings"     // concentric rings, arcs and polygons in rotated layers
+)
+
+// figureFunc draws the figure of a style onto a transparent square image,
+// with a random source seeded from the hash.
+type figureFunc func(img *image.RGBA, r *rand.Rand, o Options) error
+
+// styleFigures draw the figure of each style other than StyleMonster.
+var styleFigures = map[Style]figureFunc{
+	StyleIdenticon: drawIdenticon,
+	StyleWavatar:   drawWavatar,
+	StyleRobohash:  drawRobohash,
+	StyleCat:       drawCat,
+	StylePixel:     drawPixel,
+	StyleInitials:  drawInitials,
+	StyleBlockies:  drawBlockies,
+	StyleRings:     drawRings,
+}
+
+// styleSources seed the random source of the styles that are compatible
+// with another generator, from the hash itself rather than HashFunc.
+var styleSources = map[Style]func(hash []byte) rand.Source{
+	StyleBlockies: newBlockiesSource,
+}
+
+// Styles returns the built-in styles, followed by the styles registered with
+// RegisterStyle in order of name.
+func Styles() []Style {
+	return append(builtinStyles(), registeredStyleNames()...)
+}
+
+// Helper to get the built-in styles
+func builtinStyles() []Style {
+	return []Style{StyleMonster, StyleIdenticon, StyleWavatar, StyleRobohash, StyleCat, StylePixel, StyleInitials, StyleBlockies, StyleRings}
+}
+
+// Helper to get the style, StyleMonster unless set
+func (o Options) style() Style {
+	if o.Style == "" {
+		return StyleMonster
+	}
+
+	return o.Style
+}
+
+// Helper to get the figure of a built-in or registered style other than
+// StyleMonster
+func styleFigure(s Style) (figureFunc, error) {
+	figure, ok := styleFigures[s]
+	if !ok {
+		figure, ok = registeredFigure(s)
+	}
+	if !ok {
+		return nil, fmt.Errorf("monsterid: unknown style %q", s)
+	}
+
+	return figure, nil
+}
+
+// Helper to render the avatar of a style other than StyleMonster for hash
+// into a new image
+func newStyleImage(ctx context.Context, hash []byte, o Options) (image.Image, error) {
+	size := o.size()
+	img := getRGBA(image.Rect(0, 0, size, size))
+	if err := drawStyle(ctx, img, image.Point{}, hash, o); err != nil {
+		putRGBA(img)
+		return nil, err
+	}
+
+	return finishImage(img, o), nil
+}
+
+// Helper to draw the avatar of a style other than StyleMonster for hash onto
+// dst, over the background and inside the padding, shape and border of o
+func drawStyle(ctx context.Context, dst draw.Image, at image.Point, hash []byte, o Options) error {
+	figure, err := styleFigure(o.style())
+	if err != nil {
+		return err
+	}
+
+	return shaped(dst, at, o, func(dst draw.Image, at image.Point) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size := o.size()
+		rect := image.Rectangle{Min: at, Max: at.Add(image.Pt(size, size))}
+		drawBackground(dst, rect, o)
+
+		inner := rect.Inset(o.padding())
+		layer, err := styleLayer(hash, inner.Dx(), figure, o)
+		if err != nil {
+			return err
+		}
+		defer putRGBA(layer)
+		draw.Draw(dst, inner, layer, image.Point{}, draw.Over)
+
+		return nil
+	})
+}
+
+// Helper to draw the figure for hash onto a transparent layer of size pixels,
+// in the tone of o
+func styleLayer(hash []byte, size int, figure figureFunc, o Options) (*image.RGBA, error) {
+	layer := getRGBA(image.Rect(0, 0, size, size))
+
+	var err error
+	if newSource, ok := styleSources[o.style()]; ok {
+		err = figure(layer, rand.New(newSource(hash)), o)
+	} else {
+		r := rands.Get().(*seededRand)
+		r.pcg.Seed(pcgSeed(hash, o))
+		err = figure(layer, r.Rand, o)
+		rands.Put(r)
+	}
+	if err != nil {
+		putRGBA(layer)
+		return nil, err
+	}
+
+	if tone := o.tone(); tone == ToneSepia || tone == ToneDuotone {
+		toneImage(layer, tone, o.Duotone)
+	}
+
+	return layer, nil
+}
+
+// Helper to render the avatar of a style other than StyleMonster for hash to
+// w in format, measuring how long it takes into times if not nil
+func renderStyle(ctx context.Context, w io.Writer, hash []byte, format Format, o Options, times *renderTimes) error {
+	start := time.Now()
+	switch format {
+	case FormatSVG:
+		err := writeStyleSVG(w, hash, o)
+		if times != nil {
+			times.render = time.Since(start)
+		}
+		return err
+	case FormatPNG, FormatGIF, FormatBMP, FormatTIFF:
+	default:
+		return fmt.Errorf("monsterid: unknown format %q", format)
+	}
+
+	colors := o.Colors
+	if format == FormatGIF {
+		o.Output, o.Colors = OutputRGBA, 0
+	}
+	img, err := newStyleImage(ctx, hash, o)
+	if err != nil {
+		return err
+	}
+	defer ReleaseImage(img)
+
+	// There are no parts to describe in the metadata
+	o.Metadata = false
+	encoded := time.Now()
+	err = encodeImage(w, format, img, Descriptor{}, o, colors)
+	if times != nil {
+		times.render, times.encode = encoded.Sub(start), time.Since(encoded)
+	}
+
+	return err
+}
+
+// Helper to write the avatar of a style other than StyleMonster for hash to
+// w as SVG, embedding the figure drawn at the size inside the padding
+func writeStyleSVG(w io.Writer, hash []byte, o Options) error {
+	if err := checkSVG(o); err != nil {
+		return err
+	}
+	figure, err := styleFigure(o.style())
+	if err != nil {
+		return err
+	}
+	inner := image.Rect(0, 0, o.size(), o.size()).Inset(o.padding())
+	layer, err := styleLayer(hash, inner.Dx(), figure, o)
+	if err != nil {
+		return err
+	}
+	defer putRGBA(layer)
+
+	bw := bufio.NewWriter(w)
+	svgOpen(bw, o)
+	// Registered style names may need escaping
+	fmt.Fprintf(bw, `<g id="%s" transform="translate(%d %d)">`, html.EscapeString(string(o.style())), inner.Min.X, inner.Min.Y)
+	if err := svgImage(bw, layer, inner.Dx()); err != nil {
+		return err
+	}
+	bw.WriteString(`</g>`)
+	svgClose(bw, o)
+
+	return bw.Flush()
+}
+
+// Helper to pick the color of a figure like the body of a monster, honoring
+// the hue, saturation, palette, lightness and greyscale options. It always
+// draws the same random numbers so options don't change the rest of the
+// figure.
+func figureColor(r *rand.Rand, o Options, lightness float64) color.RGBA {
+	h := r.Float64()
+	if o.Hue != nil {
+		h = wrapHue(*o.Hue + (h*2-1)*o.HueTolerance)
+	}
+	minSat, maxSat := o.saturationRange()
+	s := minSat + r.Float64()*(maxSat-minSat)
+
+	if len(o.Palette) > 0 {
+		h, s = nearestPaletteColor(o.Palette, h)
+	}
+	if o.tone() == ToneGreyscale {
+		s = 0
+	}
+
+	return hslColor(h, s, max(0, min(1, lightness+o.LightnessShift)))
+}
+
+// figurePart is a category of the embedded parts of a style drawn from
+// artwork, such as the faces of StyleWavatar.
+type figurePart struct {
+	name  string // category, the parts are <name>_<n>.png
+	count int    // number of parts
+	tint  bool   // multiply the part by the color of the figure
+}
+
+// Helper to draw one part of every category in order onto img, picked from
+// the embedded parts in dir with r and scaled to the size of img
+func drawFigureParts(img *image.RGBA, r *rand.Rand, o Options, dir string, figureParts []figurePart, c color.RGBA) error {
+	size := img.Rect.Dx()
+	scale := partScale(size)
+	for _, part := range figureParts {
+		fileName := fmt.Sprintf("%s_%d.png", part.name, r.IntN(part.count)+1)
+		partImage, err := loadEmbeddedPart(dir, fileName, scale)
+		if err != nil {
+			return fmt.Errorf("monsterid: load part %s: %w", fileName, err)
+		}
+
+		if part.tint {
+			partImage = tintRGBA(partImage, c)
+		}
+		if partImage.Rect.Dx() != size {
+			partImage = scaleImage(partImage, size, o.Filter.kernel())
+		}
+		draw.Draw(img, img.Rect, partImage, image.Point{}, draw.Over)
+	}
+
+	return nil
+}
+
+// Helper to multiply the colors of an image by c into a new image, so white
+// becomes c and black stays black
+func tintRGBA(src *image.RGBA, c color.RGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Rect)
+	for i := 0; i < len(src.Pix); i += 4 {
+		dst.Pix[i] = uint8(uint32(src.Pix[i]) * uint32(c.R) / 0xff)
+		dst.Pix[i+1] = uint8(uint32(src.Pix[i+1]) * uint32(c.G) / 0xff)
+		dst.Pix[i+2] = uint8(uint32(src.Pix[i+2]) * uint32(c.B) / 0xff)
+		dst.Pix[i+3] = src.Pix[i+3]
+	}
+
+	return dst
+}
+
+// Helper to check if s is a built-in or registered style
+func isStyle(s Style) bool {
+	return slices.Contains(Styles(), s)
+}
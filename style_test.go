@@ -0,0 +1,180 @@
+package monsterid
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStyleEntryPoints(t *testing.T) {
+	opts := []Option{WithStyle(StyleIdenticon), WithSize(64), WithShape(ShapeCircle)}
+	want := New([]byte("alice"), opts...).(*image.RGBA)
+	if bytes.Equal(want.Pix, New([]byte("alice"), WithSize(64), WithShape(ShapeCircle)).(*image.RGBA).Pix) {
+		t.Fatal("Expected the identicon to differ from the monster")
+	}
+	if bytes.Equal(want.Pix, New([]byte("bob"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected identicons to differ by hash")
+	}
+	if _, _, _, a := want.At(0, 0).RGBA(); a != 0 {
+		t.Error("Expected the shape to mask the corners")
+	}
+
+	g, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	generated, err := g.Generate([]byte("alice"), opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(generated.(*image.RGBA).Pix, want.Pix) {
+		t.Error("Expected the generator to draw the same identicon")
+	}
+
+	for _, drawTo := range []func(dst draw.Image, at image.Point, hash []byte, opts ...Option) error{DrawTo, g.DrawTo} {
+		dst := image.NewRGBA(image.Rect(0, 0, 80, 80))
+		if err := drawTo(dst, image.Pt(8, 8), []byte("alice"), opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(dst.SubImage(image.Rect(8, 8, 72, 72)).(*image.RGBA).Pix[:64*4], want.Pix[:64*4]) {
+			t.Error("Expected DrawTo to draw the identicon")
+		}
+	}
+
+	for _, render := range []func(w io.Writer, hash []byte, format Format, opts ...Option) error{Render, g.Render} {
+		buf := new(bytes.Buffer)
+		if err := render(buf, []byte("alice"), FormatPNG, append(opts, WithMetadata())...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		img, err := png.Decode(buf)
+		if err != nil {
+			t.Fatalf("Failed to decode PNG: %v", err)
+		}
+		if !bytes.Equal(toNRGBA(img).Pix, toNRGBA(want).Pix) {
+			t.Error("Expected Render to encode the identicon")
+		}
+	}
+}
+
+func TestStyleFormats(t *testing.T) {
+	for _, format := range []Format{FormatGIF, FormatBMP, FormatTIFF, FormatSVG} {
+		buf := new(bytes.Buffer)
+		if err := Render(buf, []byte("alice"), format, WithStyle(StyleIdenticon), WithSize(48)); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", format, err)
+		}
+		if buf.Len() == 0 {
+			t.Errorf("Expected %s data", format)
+		}
+	}
+
+	svg, err := SVG([]byte("alice"), WithStyle(StyleIdenticon), WithSize(240), WithPadding(12))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Contains(svg, []byte(`<g id="identicon" transform="translate(12 12)"><image width="216" height="216"`)) {
+		t.Errorf("Expected the identicon embedded inside the padding, got %.200s", svg)
+	}
+}
+
+func TestStyleUnknown(t *testing.T) {
+	if _, err := NewWithError([]byte("alice"), WithStyle("cubist")); err == nil || !strings.Contains(err.Error(), "unknown style") {
+		t.Errorf("Expected an unknown style error, got %v", err)
+	}
+	if err := Render(new(bytes.Buffer), []byte("alice"), FormatSVG, WithStyle("cubist")); err == nil {
+		t.Error("Expected an error rendering an unknown style")
+	}
+}
+
+func TestHandlerStyle(t *testing.T) {
+	h := Handler(HandlerConfig{Params: []string{ParamStyle}})
+	rec := serve(h, http.MethodGet, "/alice?style=Identicon&s=32")
+	want := new(bytes.Buffer)
+	if err := Render(want, []byte("alice"), FormatPNG, WithStyle(StyleIdenticon), WithSize(32)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+		t.Error("Expected the style parameter to select the identicon")
+	}
+	if rec.Header().Get("ETag") == serve(h, http.MethodGet, "/alice?s=32").Header().Get("ETag") {
+		t.Error("Expected the style to change the ETag")
+	}
+	if rec := serve(h, http.MethodGet, "/alice?style=cubist"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown style to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestFigureParts(t *testing.T) {
+	tests := []struct {
+		dir    string
+		parts  []figurePart
+		scaled bool // drawn at every part scale
+	}{
+		{wavatarDir, wavatarParts, true},
+		{robohashDir, robohashParts, true},
+		{catDir, append(append(slices.Clone(catFur), catEyes...), catDetails...), true},
+		{pixelDir, pixelParts, false},
+	}
+
+	for _, test := range tests {
+		dirs := []string{test.dir}
+		if test.scaled {
+			dirs = append(dirs, path.Join(test.dir, "@2x"), path.Join(test.dir, "@4x"))
+		}
+		for _, dir := range dirs {
+			files, err := fs.Glob(parts, path.Join(dir, "*.png"))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			total := 0
+			for _, part := range test.parts {
+				total += part.count
+				for n := 1; n <= part.count; n++ {
+					if _, err := fs.Stat(parts, path.Join(dir, fmt.Sprintf("%s_%d.png", part.name, n))); err != nil {
+						t.Errorf("Expected part %s_%d in %s: %v", part.name, n, dir, err)
+					}
+				}
+			}
+			if len(files) != total {
+				t.Errorf("Expected %d parts in %s, got %d", total, dir, len(files))
+			}
+		}
+	}
+}
+
+func TestTintRGBA(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	src.SetRGBA(0, 0, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	src.SetRGBA(1, 0, color.RGBA{A: 0xff})
+	src.SetRGBA(2, 0, color.RGBA{0x80, 0x80, 0x80, 0x80})
+
+	c := color.RGBA{R: 0xff, G: 0x80, A: 0xff}
+	dst := tintRGBA(src, c)
+	tests := []struct {
+		x    int
+		want color.RGBA
+	}{
+		{0, c},
+		{1, color.RGBA{A: 0xff}},
+		{2, color.RGBA{R: 0x80, G: 0x40, A: 0x80}},
+	}
+
+	for _, test := range tests {
+		if got := dst.RGBAAt(test.x, 0); got != test.want {
+			t.Errorf("Expected %v at %d, got %v", test.want, test.x, got)
+		}
+	}
+	if src.RGBAAt(0, 0) != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Error("Expected the source to be left untouched")
+	}
+}
@@ -0,0 +1,208 @@
+package monsterid
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// SVG creates a monsterid image based on the provided hash and returns it
+// encoded as SVG.
+func SVG(hash []byte, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeSVG(buf, hash, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeSVG creates a monsterid image based on the provided hash and writes
+// it to w as SVG. The background, shape and border are vector elements, and
+// each part is embedded as a PNG image at the resolution of the part artwork
+// for the size. Background color, padding and tones are supported; background
+// images, shadows, outlines, pixel art and trimming are raster only and
+// return an error.
+func EncodeSVG(w io.Writer, hash []byte, opts ...Option) error {
+	return Render(w, hash, FormatSVG, opts...)
+}
+
+// Helper to write the monster described by d to w as SVG using parts from p
+func writeSVG(w io.Writer, d Descriptor, o Options, p pack) error {
+	if err := checkSVG(o); err != nil {
+		return err
+	}
+	size := o.size()
+	inner := image.Rect(0, 0, size, size).Inset(o.padding())
+	res := partScale(inner.Dx())
+
+	bw := bufio.NewWriter(w)
+	svgOpen(bw, o)
+
+	// Parts are placed at their native size and scaled into the padding
+	scale := float64(inner.Dx()) / nativeSize
+	fmt.Fprintf(bw, `<g transform="translate(%d %d) scale(%s)">`, inner.Min.X, inner.Min.Y, svgNumber(scale))
+	if d.Mirrored {
+		fmt.Fprintf(bw, `<g transform="matrix(-1 0 0 1 %d 0)">`, nativeSize)
+	}
+
+	tone := o.tone()
+	shift := lightnessShift(d, o)
+	for _, part := range bodyParts {
+		if o.excluded(part) {
+			continue
+		}
+		img, err := preparePart(d, o, part, shift, p, res, nil)
+		if err != nil {
+			return err
+		}
+		if tone == ToneSepia || tone == ToneDuotone {
+			img = cloneImage(img)
+			toneImage(img, tone, o.Duotone)
+		}
+
+		fmt.Fprintf(bw, `<g id="%s">`, part)
+		if err := svgImage(bw, img, nativeSize); err != nil {
+			return err
+		}
+		bw.WriteString(`</g>`)
+	}
+
+	if d.Mirrored {
+		bw.WriteString(`</g>`)
+	}
+
+	// Overlays are drawn outside the mirror so badges keep their side
+	overlays, err := loadOverlays(d, o, res)
+	if err != nil {
+		return err
+	}
+	for _, ov := range overlays {
+		img := ov.img
+		if tone == ToneSepia || tone == ToneDuotone {
+			// Loaded overlays are shared
+			img = cloneImage(img)
+			toneImage(img, tone, o.Duotone)
+		}
+
+		fmt.Fprintf(bw, `<g id="%s">`, ov.name)
+		if err := svgImage(bw, img, nativeSize); err != nil {
+			return err
+		}
+		bw.WriteString(`</g>`)
+	}
+	bw.WriteString(`</g>`)
+	svgClose(bw, o)
+
+	return bw.Flush()
+}
+
+// Helper to check that o has no options that only apply to raster images
+func checkSVG(o Options) error {
+	var option string
+	switch {
+	case o.BackgroundImage != nil:
+		option = "BackgroundImage"
+	case o.Shadow.Opacity > 0:
+		option = "Shadow"
+	case o.Outline.Width > 0:
+		option = "Outline"
+	case o.Pixelate > 0:
+		option = "Pixelate"
+	case o.TrimTransparent:
+		option = "TrimTransparent"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("monsterid: SVG doesn't support %s", option)
+}
+
+// Helper to start an SVG of the avatar size with the shape and background
+// of o, to be ended by svgClose
+func svgOpen(w *bufio.Writer, o Options) {
+	size := o.size()
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, size, size)
+
+	if clip := svgShape(o.Shape, size, o.CornerRadius, 0); clip != "" {
+		fmt.Fprintf(w, `<defs><clipPath id="shape">%s</clipPath></defs><g clip-path="url(#shape)">`, clip)
+	}
+
+	if o.Background.A > 0 {
+		fmt.Fprintf(w, `<rect width="%d" height="%d"%s/>`, size, size, svgFill("fill", o.Background))
+	}
+}
+
+// Helper to end an SVG started by svgOpen with the border of o
+func svgClose(w *bufio.Writer, o Options) {
+	size := o.size()
+	if o.Border.Width > 0 {
+		// Strokes are centered on the outline, so inset it by half the width
+		half := float64(o.Border.Width) / 2
+		border := svgShape(o.Shape, size, o.CornerRadius, half)
+		w.WriteString(border[:len(border)-2])
+		fmt.Fprintf(w, ` fill="none"%s stroke-width="%d"/>`, svgFill("stroke", o.Border.Color), o.Border.Width)
+	}
+
+	if svgShape(o.Shape, size, o.CornerRadius, 0) != "" {
+		w.WriteString(`</g>`)
+	}
+	w.WriteString(`</svg>`)
+}
+
+// Helper to embed img as a PNG image of size by size user units
+func svgImage(w *bufio.Writer, img *image.RGBA, size int) error {
+	fmt.Fprintf(w, `<image width="%d" height="%d" href="data:image/png;base64,`, size, size)
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if err := png.Encode(enc, img); err != nil {
+		return err
+	}
+	enc.Close()
+	w.WriteString(`"/>`)
+
+	return nil
+}
+
+// Helper to format a premultiplied color as a fill or stroke attribute
+func svgFill(attr string, c color.RGBA) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	s := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, n.R, n.G, n.B)
+	if n.A < 255 {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, svgNumber(float64(n.A)/255))
+	}
+
+	return s
+}
+
+// Helper to describe the shape as an SVG element inset by inset pixels,
+// or an empty string for the full square
+func svgShape(shape Shape, size, radius int, inset float64) string {
+	side := float64(size) - 2*inset
+	switch shape {
+	case ShapeCircle:
+		return fmt.Sprintf(`<circle cx="%s" cy="%s" r="%s"/>`, svgNumber(float64(size)/2), svgNumber(float64(size)/2), svgNumber(side/2))
+	case ShapeRounded:
+		r := float64(radius)
+		if radius <= 0 {
+			r = float64(size) / 8
+		}
+		r = max(0, min(r, float64(size)/2)-inset)
+		return fmt.Sprintf(`<rect x="%s" y="%s" width="%s" height="%s" rx="%s"/>`, svgNumber(inset), svgNumber(inset), svgNumber(side), svgNumber(side), svgNumber(r))
+	}
+
+	if inset == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`<rect x="%s" y="%s" width="%s" height="%s"/>`, svgNumber(inset), svgNumber(inset), svgNumber(side), svgNumber(side))
+}
+
+// Helper to format a number compactly
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
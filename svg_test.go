@@ -0,0 +1,149 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSVGIsWellFormed(t *testing.T) {
+	opts := [][]Option{
+		nil,
+		{WithShape(ShapeCircle), WithBorder(4, DefaultOptions().Background)},
+		{WithRoundedCorners(10), WithPadding(10), WithSepia()},
+		{WithBorder(2, DefaultOptions().Background), WithTransparentBackground()},
+	}
+
+	for i, o := range opts {
+		data, err := SVG([]byte("svg-test"), o...)
+		if err != nil {
+			t.Fatalf("Options %d: unexpected error: %v", i, err)
+		}
+
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Options %d: invalid SVG: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestSVGContainsParts(t *testing.T) {
+	data, err := SVG([]byte("svg-parts"), WithSize(512))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	svg := string(data)
+	if !strings.Contains(svg, `width="512" height="512"`) {
+		t.Error("SVG does not have the requested size")
+	}
+	for _, part := range bodyParts {
+		if !strings.Contains(svg, `<g id="`+part+`">`) {
+			t.Errorf("SVG is missing the %s group", part)
+		}
+	}
+}
+
+func TestSVGMirrored(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		hash := []byte{byte(i)}
+		d := Describe(hash, WithAlgorithmVersion(V2))
+
+		data, err := SVG(hash, WithAlgorithmVersion(V2))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := strings.Contains(string(data), "matrix(-1 0 0 1"); got != d.Mirrored {
+			t.Fatalf("Hash %d: mirrored %v but SVG mirror transform %v", i, d.Mirrored, got)
+		}
+	}
+}
+
+func TestSVGToneKeepsOverlays(t *testing.T) {
+	crown, err := loadAccessory(AccessoryCrown, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := bytes.Clone(crown.Pix)
+
+	for _, opt := range []Option{WithSepia(), WithDuotone(color.RGBA{A: 255}, color.RGBA{R: 255, A: 255})} {
+		if _, err := SVG([]byte("svg-tone"), WithAccessory(AccessoryCrown), opt); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	crown, err = loadAccessory(AccessoryCrown, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(crown.Pix, want) {
+		t.Error("Expected a toned SVG to leave the loaded accessory unchanged")
+	}
+}
+
+func TestSVGEmbedsParts(t *testing.T) {
+	data, err := SVG([]byte("svg-embed"), WithSize(480), WithAccessory(AccessoryCrown))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "<path") {
+		t.Error("Expected no traced paths")
+	}
+
+	// Parts are PNGs at the best resolution of their artwork for the size
+	tests := []struct {
+		group string
+		width int
+	}{
+		{"body", nativeSize},
+		{"accessory", 4 * nativeSize},
+	}
+
+	for _, test := range tests {
+		prefix := `<g id="` + test.group + `"><image width="120" height="120" href="data:image/png;base64,`
+		_, rest, ok := strings.Cut(string(data), prefix)
+		if !ok {
+			t.Fatalf("Expected an embedded %s, got %.300s", test.group, data)
+		}
+		encoded, _, _ := strings.Cut(rest, `"`)
+		img, err := png.Decode(base64.NewDecoder(base64.StdEncoding, strings.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := img.Bounds().Dx(); got != test.width {
+			t.Errorf("Expected a %s of %d pixels, got %d", test.group, test.width, got)
+		}
+	}
+}
+
+func TestSVGRasterOptions(t *testing.T) {
+	tests := []struct {
+		option string
+		opt    Option
+	}{
+		{"BackgroundImage", WithBackgroundImage(image.NewRGBA(image.Rect(0, 0, 1, 1)))},
+		{"Shadow", WithShadow(4, image.Pt(2, 2), 0.5)},
+		{"Outline", WithOutline(2, color.RGBA{})},
+		{"Pixelate", WithPixelArt(24, 16)},
+		{"TrimTransparent", WithTrim(false)},
+	}
+
+	for _, test := range tests {
+		for _, style := range []Style{StyleMonster, StyleIdenticon} {
+			_, err := SVG([]byte("svg-raster"), test.opt, WithStyle(style))
+			if err == nil || !strings.Contains(err.Error(), test.option) {
+				t.Errorf("Expected an error for %s with %s, got %v", test.option, style, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,845 @@
+package monsterid
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"io/fs"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Parts can be provided as SVG files instead of PNG, such as body_1.svg,
+// which are rasterized at the resolution they are drawn at, so a single file
+// gives crisp parts at every size. The subset of SVG written by common
+// drawing tools is supported: the path, rect, circle, ellipse, line, polygon
+// and polyline elements in groups with transforms, filled and stroked with
+// flat colors. Gradients, text, images, clipping and masks are not.
+
+// svgPoint is a point in SVG user space or pixels.
+type svgPoint struct {
+	x, y float64
+}
+
+// svgMatrix is an affine transform [a b c d e f] as in the SVG transform
+// attribute, mapping x, y to a*x + c*y + e, b*x + d*y + f.
+type svgMatrix [6]float64
+
+var svgIdentity = svgMatrix{1, 0, 0, 1, 0, 0}
+
+// Helper to transform a point
+func (m svgMatrix) apply(p svgPoint) svgPoint {
+	return svgPoint{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// Helper to combine transforms, n is applied first
+func (m svgMatrix) mul(n svgMatrix) svgMatrix {
+	return svgMatrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+// svgStyle is the inherited presentation state of an element.
+type svgStyle struct {
+	ctm         svgMatrix
+	fill        color.NRGBA
+	stroke      color.NRGBA
+	strokeWidth float64
+	evenOdd     bool
+	opacity     float64
+}
+
+// Helper to decode an SVG part next to the PNG fileName, rasterized at scale
+// times the native size, fs.ErrNotExist if there is none
+func decodeSVGPart(fsys fs.FS, fileName string, scale int) (*image.RGBA, error) {
+	asset, err := fsys.Open(strings.TrimSuffix(fileName, ".png") + ".svg")
+	if err != nil {
+		return nil, err
+	}
+	defer asset.Close()
+
+	return rasterizeSVG(asset, nativeSize*scale)
+}
+
+// Helper to rasterize an SVG document into a size x size image
+func rasterizeSVG(r io.Reader, size int) (*image.RGBA, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	dec := xml.NewDecoder(r)
+
+	var stack []svgStyle
+	skip := 0 // depth inside elements that are not drawn, such as defs
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("svg: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || slices.Contains([]string{"defs", "clipPath", "mask", "symbol", "pattern", "marker"}, t.Name.Local) {
+				skip++
+				continue
+			}
+
+			parent := svgStyle{ctm: svgIdentity, fill: color.NRGBA{A: 0xff}, strokeWidth: 1, opacity: 1}
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			} else if t.Name.Local != "svg" {
+				return nil, errors.New("svg: root element is not svg")
+			} else {
+				parent.ctm = svgViewBox(t, size)
+			}
+
+			style, err := parseSVGStyle(t, parent)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, style)
+
+			paths, err := svgShapePaths(t)
+			if err != nil {
+				return nil, err
+			}
+			if paths != nil {
+				drawSVGPaths(dst, paths, style, t.Name.Local != "line")
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	return dst, nil
+}
+
+// Helper to get the transform from the viewBox or size of the root element
+// to size x size pixels
+func svgViewBox(root xml.StartElement, size int) svgMatrix {
+	if vb := svgNumbers(svgAttr(root, "viewBox")); len(vb) == 4 && vb[2] > 0 && vb[3] > 0 {
+		sx, sy := float64(size)/vb[2], float64(size)/vb[3]
+		return svgMatrix{sx, 0, 0, sy, -vb[0] * sx, -vb[1] * sy}
+	}
+
+	w, errW := svgLength(svgAttr(root, "width"))
+	h, errH := svgLength(svgAttr(root, "height"))
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return svgIdentity
+	}
+
+	return svgMatrix{float64(size) / w, 0, 0, float64(size) / h, 0, 0}
+}
+
+// Helper to get an attribute of an element
+func svgAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// Helper to apply the presentation attributes and style of an element to the
+// inherited style
+func parseSVGStyle(e xml.StartElement, s svgStyle) (svgStyle, error) {
+	props := map[string]string{}
+	for _, a := range e.Attr {
+		props[a.Name.Local] = a.Value
+	}
+	for _, decl := range strings.Split(props["style"], ";") {
+		if k, v, ok := strings.Cut(decl, ":"); ok {
+			props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	var err error
+	if v, ok := props["transform"]; ok {
+		var m svgMatrix
+		if m, err = parseSVGTransform(v); err != nil {
+			return s, err
+		}
+		s.ctm = s.ctm.mul(m)
+	}
+	if v, ok := props["fill"]; ok {
+		if s.fill, err = parseSVGColor(v); err != nil {
+			return s, err
+		}
+	}
+	if v, ok := props["stroke"]; ok {
+		if s.stroke, err = parseSVGColor(v); err != nil {
+			return s, err
+		}
+	}
+	if v, ok := props["stroke-width"]; ok {
+		if s.strokeWidth, err = svgLength(v); err != nil {
+			return s, err
+		}
+	}
+	if v, ok := props["fill-rule"]; ok {
+		s.evenOdd = v == "evenodd"
+	}
+
+	for name, target := range map[string]*uint8{"fill-opacity": &s.fill.A, "stroke-opacity": &s.stroke.A} {
+		if v, ok := props[name]; ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return s, fmt.Errorf("svg: invalid %s %q", name, v)
+			}
+			*target = uint8(float64(*target)*math.Max(0, math.Min(1, f)) + 0.5)
+		}
+	}
+	if v, ok := props["opacity"]; ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return s, fmt.Errorf("svg: invalid opacity %q", v)
+		}
+		s.opacity *= math.Max(0, math.Min(1, f))
+	}
+
+	return s, nil
+}
+
+// svgColors are the named colors accepted in fill and stroke.
+var svgColors = map[string]color.NRGBA{
+	"black":  {A: 0xff},
+	"white":  {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	"red":    {R: 0xff, A: 0xff},
+	"green":  {G: 0x80, A: 0xff},
+	"blue":   {B: 0xff, A: 0xff},
+	"yellow": {R: 0xff, G: 0xff, A: 0xff},
+	"orange": {R: 0xff, G: 0xa5, A: 0xff},
+	"gray":   {R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+	"grey":   {R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+
+	"currentColor": {A: 0xff},
+	"none":         {},
+	"transparent":  {},
+}
+
+// Helper to parse a flat color
+func parseSVGColor(v string) (color.NRGBA, error) {
+	v = strings.TrimSpace(v)
+	if c, ok := svgColors[v]; ok {
+		return c, nil
+	}
+
+	if hex, ok := strings.CutPrefix(v, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if n, err := strconv.ParseUint(hex, 16, 32); err == nil && len(hex) == 6 {
+			return color.NRGBA{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), A: 0xff}, nil
+		}
+	}
+
+	if args, ok := strings.CutPrefix(v, "rgb("); ok {
+		if rgb := svgNumbers(strings.TrimSuffix(args, ")")); len(rgb) == 3 {
+			c := color.NRGBA{A: 0xff}
+			c.R, c.G, c.B = clampUint8(rgb[0]), clampUint8(rgb[1]), clampUint8(rgb[2])
+			return c, nil
+		}
+	}
+
+	return color.NRGBA{}, fmt.Errorf("svg: unsupported color %q", v)
+}
+
+// Helper to parse a length in user units, with an optional px suffix
+func svgLength(v string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "px"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("svg: unsupported length %q", v)
+	}
+
+	return f, nil
+}
+
+// Helper to parse a list of numbers separated by spaces or commas
+func svgNumbers(v string) []float64 {
+	p := svgPathParser{s: v}
+	var nums []float64
+	for p.skipSeparators(); p.i < len(p.s); p.skipSeparators() {
+		n, err := p.number()
+		if err != nil {
+			return nums
+		}
+		nums = append(nums, n)
+	}
+
+	return nums
+}
+
+// Helper to parse a transform attribute into a matrix
+func parseSVGTransform(v string) (svgMatrix, error) {
+	m := svgIdentity
+	for rest := strings.TrimSpace(v); rest != ""; rest = strings.TrimLeft(rest, " ,\t\n") {
+		name, after, ok := strings.Cut(rest, "(")
+		args, tail, ok2 := strings.Cut(after, ")")
+		if !ok || !ok2 {
+			return m, fmt.Errorf("svg: invalid transform %q", v)
+		}
+		rest = tail
+		a := svgNumbers(args)
+
+		var t svgMatrix
+		switch name = strings.TrimSpace(name); {
+		case name == "matrix" && len(a) == 6:
+			t = svgMatrix{a[0], a[1], a[2], a[3], a[4], a[5]}
+		case name == "translate" && len(a) == 1:
+			t = svgMatrix{1, 0, 0, 1, a[0], 0}
+		case name == "translate" && len(a) == 2:
+			t = svgMatrix{1, 0, 0, 1, a[0], a[1]}
+		case name == "scale" && len(a) == 1:
+			t = svgMatrix{a[0], 0, 0, a[0], 0, 0}
+		case name == "scale" && len(a) == 2:
+			t = svgMatrix{a[0], 0, 0, a[1], 0, 0}
+		case name == "rotate" && (len(a) == 1 || len(a) == 3):
+			sin, cos := math.Sincos(a[0] * math.Pi / 180)
+			t = svgMatrix{cos, sin, -sin, cos, 0, 0}
+			if len(a) == 3 {
+				t = svgMatrix{1, 0, 0, 1, a[1], a[2]}.mul(t).mul(svgMatrix{1, 0, 0, 1, -a[1], -a[2]})
+			}
+		case name == "skewX" && len(a) == 1:
+			t = svgMatrix{1, 0, math.Tan(a[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(a) == 1:
+			t = svgMatrix{1, math.Tan(a[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return m, fmt.Errorf("svg: invalid transform %q", v)
+		}
+		m = m.mul(t)
+	}
+
+	return m, nil
+}
+
+// Helper to get the outline of a shape element as flattened subpaths in user
+// space, nil for elements that are not shapes
+func svgShapePaths(e xml.StartElement) ([][]svgPoint, error) {
+	num := func(name string) float64 {
+		f, _ := svgLength(svgAttr(e, name))
+		return f
+	}
+
+	switch e.Name.Local {
+	case "path":
+		return parseSVGPath(svgAttr(e, "d"))
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		rx, ry := num("rx"), num("ry")
+		if rx == 0 {
+			rx = ry
+		}
+		if ry == 0 {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx <= 0 {
+			return [][]svgPoint{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}, {x, y}}}, nil
+		}
+		var pts []svgPoint
+		pts = svgEllipseArc(pts, x+w-rx, y+ry, rx, ry, -math.Pi/2, 0)
+		pts = svgEllipseArc(pts, x+w-rx, y+h-ry, rx, ry, 0, math.Pi/2)
+		pts = svgEllipseArc(pts, x+rx, y+h-ry, rx, ry, math.Pi/2, math.Pi)
+		pts = svgEllipseArc(pts, x+rx, y+ry, rx, ry, math.Pi, 3*math.Pi/2)
+		return [][]svgPoint{append(pts, pts[0])}, nil
+	case "circle":
+		r := num("r")
+		return [][]svgPoint{svgEllipseArc(nil, num("cx"), num("cy"), r, r, 0, 2*math.Pi)}, nil
+	case "ellipse":
+		return [][]svgPoint{svgEllipseArc(nil, num("cx"), num("cy"), num("rx"), num("ry"), 0, 2*math.Pi)}, nil
+	case "line":
+		return [][]svgPoint{{{num("x1"), num("y1")}, {num("x2"), num("y2")}}}, nil
+	case "polygon", "polyline":
+		a := svgNumbers(svgAttr(e, "points"))
+		pts := make([]svgPoint, 0, len(a)/2)
+		for i := 0; i+1 < len(a); i += 2 {
+			pts = append(pts, svgPoint{a[i], a[i+1]})
+		}
+		if e.Name.Local == "polygon" && len(pts) > 0 {
+			pts = append(pts, pts[0])
+		}
+		return [][]svgPoint{pts}, nil
+	}
+
+	return nil, nil
+}
+
+// svgCurveSegments is the number of line segments a curve is flattened into.
+const svgCurveSegments = 16
+
+// Helper to append points along an elliptical arc from angle a0 to a1
+func svgEllipseArc(pts []svgPoint, cx, cy, rx, ry, a0, a1 float64) []svgPoint {
+	n := max(2, int(math.Ceil(math.Abs(a1-a0)/(math.Pi/32))))
+	for i := 0; i <= n; i++ {
+		sin, cos := math.Sincos(a0 + (a1-a0)*float64(i)/float64(n))
+		pts = append(pts, svgPoint{cx + rx*cos, cy + ry*sin})
+	}
+
+	return pts
+}
+
+// svgPathParser reads the commands and numbers of path data.
+type svgPathParser struct {
+	s string
+	i int
+}
+
+// Helper to skip whitespace and commas
+func (p *svgPathParser) skipSeparators() {
+	for p.i < len(p.s) && strings.IndexByte(" ,\t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// Helper to check whether a number follows
+func (p *svgPathParser) hasNumber() bool {
+	p.skipSeparators()
+	return p.i < len(p.s) && strings.IndexByte("+-.0123456789", p.s[p.i]) >= 0
+}
+
+// Helper to read a number, such as -1.5e3 or .5
+func (p *svgPathParser) number() (float64, error) {
+	p.skipSeparators()
+	start := p.i
+	if p.i < len(p.s) && (p.s[p.i] == '+' || p.s[p.i] == '-') {
+		p.i++
+	}
+	digits := func() {
+		for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+			p.i++
+		}
+	}
+	digits()
+	if p.i < len(p.s) && p.s[p.i] == '.' {
+		p.i++
+		digits()
+	}
+	if p.i < len(p.s) && (p.s[p.i] == 'e' || p.s[p.i] == 'E') {
+		p.i++
+		if p.i < len(p.s) && (p.s[p.i] == '+' || p.s[p.i] == '-') {
+			p.i++
+		}
+		digits()
+	}
+
+	f, err := strconv.ParseFloat(p.s[start:p.i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("svg: invalid number at %d in path %q", start, p.s)
+	}
+
+	return f, nil
+}
+
+// Helper to read an arc flag, which needs no separator
+func (p *svgPathParser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.i < len(p.s) && (p.s[p.i] == '0' || p.s[p.i] == '1') {
+		p.i++
+		return p.s[p.i-1] == '1', nil
+	}
+
+	return false, fmt.Errorf("svg: invalid arc flag at %d in path %q", p.i, p.s)
+}
+
+// Helper to parse path data into flattened subpaths
+func parseSVGPath(d string) ([][]svgPoint, error) {
+	p := &svgPathParser{s: d}
+	var paths [][]svgPoint
+	var cur []svgPoint
+	var pos, start, ctrl svgPoint // ctrl is the last control point, for S and T
+	var cmd, prev byte
+
+	nums := func(n int) ([]float64, error) {
+		a := make([]float64, n)
+		for i := range a {
+			var err error
+			if a[i], err = p.number(); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	}
+	flush := func() {
+		if len(cur) > 1 {
+			paths = append(paths, cur)
+		}
+		cur = nil
+	}
+
+	for {
+		p.skipSeparators()
+		if p.i >= len(p.s) {
+			break
+		}
+		if c := p.s[p.i]; strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0 {
+			cmd = c
+			p.i++
+		} else if cmd == 0 || !p.hasNumber() {
+			return nil, fmt.Errorf("svg: invalid path command at %d in %q", p.i, d)
+		}
+
+		rel := cmd >= 'a'
+		origin := svgPoint{}
+		if rel {
+			origin = pos
+		}
+		at := func(x, y float64) svgPoint { return svgPoint{origin.x + x, origin.y + y} }
+
+		switch cmd | 0x20 { // lower case
+		case 'm':
+			a, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			pos = at(a[0], a[1])
+			start = pos
+			cur = []svgPoint{pos}
+			// Following pairs are line segments
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'l', 'h', 'v':
+			var next svgPoint
+			switch cmd | 0x20 {
+			case 'l':
+				a, err := nums(2)
+				if err != nil {
+					return nil, err
+				}
+				next = at(a[0], a[1])
+			case 'h':
+				a, err := nums(1)
+				if err != nil {
+					return nil, err
+				}
+				next = svgPoint{origin.x + a[0], pos.y}
+			case 'v':
+				a, err := nums(1)
+				if err != nil {
+					return nil, err
+				}
+				next = svgPoint{pos.x, origin.y + a[0]}
+			}
+			if cur == nil {
+				cur = []svgPoint{pos}
+			}
+			pos = next
+			cur = append(cur, pos)
+		case 'c', 's':
+			var c1 svgPoint
+			var a []float64
+			var err error
+			if cmd|0x20 == 'c' {
+				if a, err = nums(6); err != nil {
+					return nil, err
+				}
+				c1, a = at(a[0], a[1]), a[2:]
+			} else {
+				if a, err = nums(4); err != nil {
+					return nil, err
+				}
+				c1 = pos
+				if prev|0x20 == 'c' || prev|0x20 == 's' {
+					c1 = svgPoint{2*pos.x - ctrl.x, 2*pos.y - ctrl.y}
+				}
+			}
+			c2, end := at(a[0], a[1]), at(a[2], a[3])
+			if cur == nil {
+				cur = []svgPoint{pos}
+			}
+			for i := 1; i <= svgCurveSegments; i++ {
+				t := float64(i) / svgCurveSegments
+				u := 1 - t
+				cur = append(cur, svgPoint{
+					u*u*u*pos.x + 3*u*u*t*c1.x + 3*u*t*t*c2.x + t*t*t*end.x,
+					u*u*u*pos.y + 3*u*u*t*c1.y + 3*u*t*t*c2.y + t*t*t*end.y,
+				})
+			}
+			pos, ctrl = end, c2
+		case 'q', 't':
+			var c svgPoint
+			var a []float64
+			var err error
+			if cmd|0x20 == 'q' {
+				if a, err = nums(4); err != nil {
+					return nil, err
+				}
+				c, a = at(a[0], a[1]), a[2:]
+			} else {
+				if a, err = nums(2); err != nil {
+					return nil, err
+				}
+				c = pos
+				if prev|0x20 == 'q' || prev|0x20 == 't' {
+					c = svgPoint{2*pos.x - ctrl.x, 2*pos.y - ctrl.y}
+				}
+			}
+			end := at(a[0], a[1])
+			if cur == nil {
+				cur = []svgPoint{pos}
+			}
+			for i := 1; i <= svgCurveSegments; i++ {
+				t := float64(i) / svgCurveSegments
+				u := 1 - t
+				cur = append(cur, svgPoint{
+					u*u*pos.x + 2*u*t*c.x + t*t*end.x,
+					u*u*pos.y + 2*u*t*c.y + t*t*end.y,
+				})
+			}
+			pos, ctrl = end, c
+		case 'a':
+			r, err := nums(3)
+			if err != nil {
+				return nil, err
+			}
+			large, err := p.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := p.flag()
+			if err != nil {
+				return nil, err
+			}
+			a, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			end := at(a[0], a[1])
+			if cur == nil {
+				cur = []svgPoint{pos}
+			}
+			cur = svgArc(cur, pos, end, r[0], r[1], r[2], large, sweep)
+			pos = end
+		case 'z':
+			// The closing segment is part of the stroke
+			if len(cur) > 1 {
+				paths = append(paths, append(cur, start))
+			}
+			cur = nil
+			pos = start
+		}
+		prev = cmd
+	}
+	flush()
+
+	return paths, nil
+}
+
+// Helper to append points along an arc in endpoint parameterization, as in
+// the SVG implementation notes
+func svgArc(pts []svgPoint, from, to svgPoint, rx, ry, angle float64, large, sweep bool) []svgPoint {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || from == to {
+		return append(pts, to)
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	dx, dy := (from.x-to.x)/2, (from.y-to.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// Scale up radii that are too small to reach the end point
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	f := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		f = -f
+	}
+	cx1, cy1 := f*rx*y1/ry, -f*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (from.x+to.x)/2
+	cy := sin*cx1 + cos*cy1 + (from.y+to.y)/2
+
+	a0 := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	a1 := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx)
+	da := a1 - a0
+	if sweep && da < 0 {
+		da += 2 * math.Pi
+	} else if !sweep && da > 0 {
+		da -= 2 * math.Pi
+	}
+
+	n := max(2, int(math.Ceil(math.Abs(da)/(math.Pi/32))))
+	for i := 1; i <= n; i++ {
+		s, c := math.Sincos(a0 + da*float64(i)/float64(n))
+		pts = append(pts, svgPoint{cx + cos*rx*c - sin*ry*s, cy + sin*rx*c + cos*ry*s})
+	}
+
+	return pts
+}
+
+// Helper to fill and stroke subpaths onto dst
+func drawSVGPaths(dst *image.RGBA, paths [][]svgPoint, s svgStyle, fill bool) {
+	device := make([][]svgPoint, len(paths))
+	for i, path := range paths {
+		device[i] = make([]svgPoint, len(path))
+		for j, pt := range path {
+			device[i][j] = s.ctm.apply(pt)
+		}
+	}
+
+	paint := func(polys [][]svgPoint, c color.NRGBA, evenOdd bool) {
+		c.A = uint8(float64(c.A)*s.opacity + 0.5)
+		if c.A == 0 || len(polys) == 0 {
+			return
+		}
+		mask := rasterizePolygons(polys, evenOdd, dst.Bounds())
+		draw.DrawMask(dst, dst.Bounds(), image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+	}
+
+	if fill {
+		paint(device, s.fill, s.evenOdd)
+	}
+	if s.stroke.A > 0 && s.strokeWidth > 0 {
+		// Stroke widths scale with the transform
+		w := s.strokeWidth * math.Sqrt(math.Abs(s.ctm[0]*s.ctm[3]-s.ctm[1]*s.ctm[2]))
+		paint(strokePolygons(device, w), s.stroke, false)
+	}
+}
+
+// Helper to outline polylines with round joins and caps, as polygons of the
+// same orientation so their union is filled with the nonzero rule
+func strokePolygons(paths [][]svgPoint, width float64) [][]svgPoint {
+	r := width / 2
+	var polys [][]svgPoint
+	for _, path := range paths {
+		for i, a := range path {
+			polys = append(polys, svgEllipseArc(nil, a.x, a.y, r, r, 0, 2*math.Pi))
+			if i == 0 {
+				continue
+			}
+			b := path[i-1]
+			l := math.Hypot(a.x-b.x, a.y-b.y)
+			if l == 0 {
+				continue
+			}
+			nx, ny := -(a.y-b.y)/l*r, (a.x-b.x)/l*r
+			polys = append(polys, []svgPoint{{b.x + nx, b.y + ny}, {a.x + nx, a.y + ny}, {a.x - nx, a.y - ny}, {b.x - nx, b.y - ny}})
+		}
+	}
+
+	for _, poly := range polys {
+		area := 0.0
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			area += a.x*b.y - b.x*a.y
+		}
+		if area < 0 {
+			slices.Reverse(poly)
+		}
+	}
+
+	return polys
+}
+
+// svgSubsamples is the number of scanlines sampled per row of pixels.
+const svgSubsamples = 4
+
+// Helper to compute the antialiased coverage of closed polygons
+func rasterizePolygons(polys [][]svgPoint, evenOdd bool, bounds image.Rectangle) *image.Alpha {
+	type edge struct {
+		x0, y0, x1, y1 float64
+		dir            int
+	}
+	var edges []edge
+	for _, poly := range polys {
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			switch {
+			case a.y < b.y:
+				edges = append(edges, edge{a.x, a.y, b.x, b.y, 1})
+			case a.y > b.y:
+				edges = append(edges, edge{b.x, b.y, a.x, a.y, -1})
+			}
+		}
+	}
+
+	inside := func(w int) bool {
+		if evenOdd {
+			return w%2 != 0
+		}
+		return w != 0
+	}
+
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	mask := image.NewAlpha(bounds)
+	w := bounds.Dx()
+	cover := make([]float64, w)
+	var crossings []crossing
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		clear(cover)
+		for s := 0; s < svgSubsamples; s++ {
+			sy := float64(y) + (float64(s)+0.5)/svgSubsamples
+			crossings = crossings[:0]
+			for _, e := range edges {
+				if sy >= e.y0 && sy < e.y1 {
+					crossings = append(crossings, crossing{e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0), e.dir})
+				}
+			}
+			slices.SortFunc(crossings, func(a, b crossing) int {
+				switch {
+				case a.x < b.x:
+					return -1
+				case a.x > b.x:
+					return 1
+				}
+				return 0
+			})
+
+			wind, from := 0, 0.0
+			for _, c := range crossings {
+				was := inside(wind)
+				wind += c.dir
+				switch is := inside(wind); {
+				case !was && is:
+					from = c.x
+				case was && !is:
+					addCoverage(cover, from-float64(bounds.Min.X), c.x-float64(bounds.Min.X))
+				}
+			}
+		}
+
+		off := mask.PixOffset(bounds.Min.X, y)
+		for x, c := range cover {
+			mask.Pix[off+x] = clampUint8(c / svgSubsamples * 255)
+		}
+	}
+
+	return mask
+}
+
+// Helper to add the horizontal coverage of the span a-b to the pixels of a row
+func addCoverage(cover []float64, a, b float64) {
+	a, b = math.Max(a, 0), math.Min(b, float64(len(cover)))
+	for x := int(a); x < len(cover) && float64(x) < b; x++ {
+		cover[x] += math.Min(b, float64(x+1)) - math.Max(a, float64(x))
+	}
+}
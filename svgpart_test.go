@@ -0,0 +1,174 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Helper to rasterize an SVG document, failing the test on error
+func mustRasterizeSVG(t *testing.T, doc string, size int) *image.RGBA {
+	t.Helper()
+
+	img, err := rasterizeSVG(strings.NewReader(doc), size)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return img
+}
+
+func TestRasterizeSVGShapes(t *testing.T) {
+	img := mustRasterizeSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10">
+		<rect x="1" y="1" width="4" height="4" fill="#ff0000"/>
+		<circle cx="7.5" cy="7.5" r="2" fill="blue"/>
+	</svg>`, 20)
+
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{4, 4, color.RGBA{R: 0xff, A: 0xff}},
+		{1, 1, color.RGBA{}},
+		{15, 15, color.RGBA{B: 0xff, A: 0xff}},
+		{19, 0, color.RGBA{}},
+	}
+
+	for _, test := range tests {
+		if got := img.RGBAAt(test.x, test.y); got != test.want {
+			t.Errorf("Expected %v at %d,%d, got %v", test.want, test.x, test.y, got)
+		}
+	}
+
+	// The edge of the circle is antialiased
+	if a := img.RGBAAt(15, 11).A; a == 0 || a == 0xff {
+		t.Errorf("Expected partial coverage at the edge of the circle, got %d", a)
+	}
+}
+
+func TestRasterizeSVGPath(t *testing.T) {
+	// A square with a square hole, drawn with relative commands, a curve and
+	// an arc that don't change the covered area much
+	img := mustRasterizeSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+		<path fill-rule="evenodd" d="M10,10 h80 v80 H10 z m20 20 l40 0 0 40 -40 0 z"/>
+		<path fill="#0f0" d="M40 45 Q50 35 60 45 T80 45 C80 50 80 50 80 55 A5 5 0 0 1 70 55 Z"/>
+	</svg>`, 100)
+
+	if a := img.RGBAAt(20, 20).A; a != 0xff {
+		t.Errorf("Expected the square to be filled, got alpha %d", a)
+	}
+	if a := img.RGBAAt(35, 65).A; a != 0 {
+		t.Errorf("Expected the hole to be empty, got alpha %d", a)
+	}
+	if c := img.RGBAAt(74, 50); c != (color.RGBA{G: 0xff, A: 0xff}) {
+		t.Errorf("Expected the curved shape to be filled, got %v", c)
+	}
+}
+
+func TestRasterizeSVGStrokeAndTransform(t *testing.T) {
+	img := mustRasterizeSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 50 50">
+		<g transform="translate(25 0) scale(2)" style="stroke: black; stroke-width: 2; fill: none">
+			<line x1="0" y1="5" x2="0" y2="20"/>
+		</g>
+	</svg>`, 100)
+
+	// The line runs at x = 25 user units, 8 pixels wide after both scales
+	for _, test := range []struct {
+		x    int
+		want uint8
+	}{{45, 0}, {46, 0xff}, {53, 0xff}, {54, 0}} {
+		if a := img.RGBAAt(test.x, 50).A; a != test.want {
+			t.Errorf("Expected alpha %d at x %d, got %d", test.want, test.x, a)
+		}
+	}
+}
+
+func TestParseSVGTransform(t *testing.T) {
+	m, err := parseSVGTransform("translate(10, 20) rotate(90) scale(2 3)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p := m.apply(svgPoint{1, 1})
+	if math.Abs(p.x-7) > 1e-9 || math.Abs(p.y-22) > 1e-9 {
+		t.Errorf("Expected 7,22, got %v", p)
+	}
+
+	if _, err := parseSVGTransform("wobble(3)"); err == nil {
+		t.Error("Expected an error for an unknown transform")
+	}
+}
+
+func TestParseSVGColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  color.NRGBA
+		valid bool
+	}{
+		{"#abc", color.NRGBA{R: 0xaa, G: 0xbb, B: 0xcc, A: 0xff}, true},
+		{"#102030", color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}, true},
+		{"rgb(1, 2, 3)", color.NRGBA{R: 1, G: 2, B: 3, A: 0xff}, true},
+		{"none", color.NRGBA{}, true},
+		{"white", color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, true},
+		{"url(#gradient)", color.NRGBA{}, false},
+		{"#12345", color.NRGBA{}, false},
+	}
+
+	for _, test := range tests {
+		got, err := parseSVGColor(test.value)
+		if (err == nil) != test.valid {
+			t.Errorf("Expected %q to be valid: %v, got %v", test.value, test.valid, err)
+		} else if got != test.want {
+			t.Errorf("Expected %v for %q, got %v", test.want, test.value, got)
+		}
+	}
+}
+
+func TestRasterizeSVGErrors(t *testing.T) {
+	tests := []string{
+		`<svg`,
+		`<html></html>`,
+		`<svg><path d="M0 0 X10 10"/></svg>`,
+		`<svg><rect width="10" height="10" fill="url(#g)"/></svg>`,
+	}
+
+	for _, test := range tests {
+		if _, err := rasterizeSVG(strings.NewReader(test), 10); err == nil {
+			t.Errorf("Expected an error for %s", test)
+		}
+	}
+}
+
+func TestGeneratorSVGParts(t *testing.T) {
+	fsys := testPack(t, 1)
+	delete(fsys, "body_1.png")
+	fsys["body_1.svg"] = &fstest.MapFile{Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 120 120">
+		<circle cx="60" cy="60" r="40" fill="#808080" stroke="black" stroke-width="2"/>
+	</svg>`)}
+
+	g, err := NewGeneratorFromFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := ValidatePack(fsys); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// The body is rasterized at every resolution
+	for _, scale := range partScales {
+		img, err := g.pack().load("body_1.png", scale)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if size := img.Bounds().Dx(); size != nativeSize*scale {
+			t.Errorf("Expected a %d pixel body, got %d", nativeSize*scale, size)
+		}
+	}
+
+	if _, err := g.Generate([]byte("svg-parts"), WithSize(480)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
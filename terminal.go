@@ -0,0 +1,222 @@
+package monsterid
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EncodeANSI writes the monster for hash to w as truecolor ANSI text for
+// terminals, a half block character per column of pixels and pair of rows.
+// Every pixel of the image is a column, so small sizes such as WithSize(32)
+// fit a terminal. Mostly transparent pixels are left to the terminal
+// background.
+func EncodeANSI(w io.Writer, hash []byte, opts ...Option) error {
+	img, err := terminalImage(hash, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeANSI(w, img)
+}
+
+// RenderText returns the monster for hash as colored Unicode block art width
+// characters wide, as written by EncodeANSI, for CLIs, MOTDs and logs where
+// images can't be shown. A width of zero or less keeps the size of the
+// options. It panics if the image cannot be generated, like New.
+func RenderText(hash []byte, width int, opts ...Option) string {
+	if width > 0 {
+		opts = append(slices.Clip(opts), WithSize(width))
+	}
+	var sb strings.Builder
+	if err := EncodeANSI(&sb, hash, opts...); err != nil {
+		panic(err)
+	}
+
+	return sb.String()
+}
+
+// EncodeSixel writes the monster for hash to w as sixel graphics, which
+// terminals such as xterm -ti vt340, mlterm, foot and WezTerm show as an
+// image at full resolution. The image is quantized to 255 colors and mostly
+// transparent pixels are left to the terminal background.
+func EncodeSixel(w io.Writer, hash []byte, opts ...Option) error {
+	img, err := terminalImage(hash, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeSixel(w, img)
+}
+
+// Helper to create the non-premultiplied image of the monster for hash to
+// write to a terminal
+func terminalImage(hash []byte, opts []Option) (*image.NRGBA, error) {
+	o := buildOptions(opts)
+	o.Output, o.Colors = OutputRGBA, 0
+	img, err := NewContext(context.Background(), hash, o)
+	if err != nil {
+		return nil, err
+	}
+	defer ReleaseImage(img)
+
+	return toNRGBA(img), nil
+}
+
+// Helper to check if a pixel is opaque enough to draw in a terminal, which
+// can't blend
+func terminalOpaque(c color.NRGBA) bool {
+	return c.A >= 0x80
+}
+
+// Helper to write an image as lines of half blocks, the upper half in the
+// foreground color of the upper pixel and the lower half in the background
+// color of the lower pixel
+func writeANSI(w io.Writer, img *image.NRGBA) error {
+	bw := bufio.NewWriter(w)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		var fg, bg *color.NRGBA // colors set on the terminal, the default if nil
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top := img.NRGBAAt(x, y)
+			bottom := img.NRGBAAt(x, y+1) // transparent below the last row
+			upper, lower := terminalOpaque(top), y+1 < b.Max.Y && terminalOpaque(bottom)
+
+			switch {
+			case !upper && !lower:
+				if fg != nil || bg != nil {
+					bw.WriteString("\x1b[0m")
+					fg, bg = nil, nil
+				}
+				bw.WriteByte(' ')
+			case upper && lower:
+				setColor(bw, &fg, top, 38)
+				setColor(bw, &bg, bottom, 48)
+				bw.WriteString("▀")
+			default:
+				// One half in the foreground over the default background
+				c, block := top, "▀"
+				if lower {
+					c, block = bottom, "▄"
+				}
+				if bg != nil {
+					bw.WriteString("\x1b[49m")
+					bg = nil
+				}
+				setColor(bw, &fg, c, 38)
+				bw.WriteString(block)
+			}
+		}
+		if fg != nil || bg != nil {
+			bw.WriteString("\x1b[0m")
+		}
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// Helper to set the foreground (38) or background (48) color of the terminal
+// to c, unless it already is
+func setColor(w *bufio.Writer, current **color.NRGBA, c color.NRGBA, layer int) {
+	c.A = 0xff
+	if *current != nil && **current == c {
+		return
+	}
+	fmt.Fprintf(w, "\x1b[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+	*current = &c
+}
+
+// Helper to write an image as sixels, bands of six rows drawn a color at a
+// time with run-length encoding
+func writeSixel(w io.Writer, img *image.NRGBA) error {
+	// Quantize keeps fully transparent pixels at index 0
+	b := img.Bounds()
+	opaque := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := img.NRGBAAt(x, y); terminalOpaque(c) {
+				c.A = 0xff
+				opaque.SetRGBA(x, y, color.RGBA(c))
+			}
+		}
+	}
+	p := Quantize(opaque, 256)
+
+	bw := bufio.NewWriter(w)
+	// Transparent pixels keep the background, at a 1:1 aspect ratio
+	fmt.Fprintf(bw, "\x1bP0;1;0q\"1;1;%d;%d", b.Dx(), b.Dy())
+	for i, c := range p.Palette[1:] {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(bw, "#%d;2;%d;%d;%d", i+1, sixelPercent(r), sixelPercent(g), sixelPercent(bl))
+	}
+
+	sixels := make([]byte, b.Dx())
+	for y := b.Min.Y; y < b.Max.Y; y += 6 {
+		var used [256]bool
+		for dy := 0; dy < 6 && y+dy < b.Max.Y; dy++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				used[p.ColorIndexAt(x, y+dy)] = true
+			}
+		}
+
+		first := true
+		for i := 1; i < len(p.Palette); i++ {
+			if !used[i] {
+				continue
+			}
+			for x := b.Min.X; x < b.Max.X; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && y+dy < b.Max.Y; dy++ {
+					if int(p.ColorIndexAt(x, y+dy)) == i {
+						bits |= 1 << dy
+					}
+				}
+				sixels[x-b.Min.X] = '?' + bits
+			}
+			if !first {
+				// Back to the start of the band for the next color
+				bw.WriteByte('$')
+			}
+			first = false
+			bw.WriteByte('#')
+			bw.WriteString(strconv.Itoa(i))
+			writeSixelRuns(bw, sixels)
+		}
+		bw.WriteByte('-')
+	}
+	bw.WriteString("\x1b\\")
+
+	return bw.Flush()
+}
+
+// Helper to write sixels, repeating runs of the same sixel with !
+func writeSixelRuns(w *bufio.Writer, sixels []byte) {
+	for i := 0; i < len(sixels); {
+		n := 1
+		for i+n < len(sixels) && sixels[i+n] == sixels[i] {
+			n++
+		}
+		if n > 3 {
+			w.WriteByte('!')
+			w.WriteString(strconv.Itoa(n))
+			w.WriteByte(sixels[i])
+		} else {
+			for range n {
+				w.WriteByte(sixels[i])
+			}
+		}
+		i += n
+	}
+}
+
+// Helper to convert a 16-bit color channel to the percent of sixel colors
+func sixelPercent(v uint32) int {
+	return int((v*100 + 0x7fff) / 0xffff)
+}
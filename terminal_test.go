@@ -0,0 +1,250 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEncodeANSI(t *testing.T) {
+	tests := []struct {
+		size int
+		opts []Option
+	}{
+		{16, nil},
+		{15, []Option{WithTransparentBackground()}},
+		{12, []Option{WithShape(ShapeCircle), WithGreyscale()}},
+	}
+
+	for _, test := range tests {
+		opts := append([]Option{WithSize(test.size)}, test.opts...)
+		buf := new(bytes.Buffer)
+		if err := EncodeANSI(buf, []byte("alice"), opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != (test.size+1)/2 {
+			t.Errorf("Expected %d lines for size %d, got %d", (test.size+1)/2, test.size, lines)
+		}
+
+		want, err := terminalImage([]byte("alice"), opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := decodeANSI(t, buf.String(), test.size)
+		for y := 0; y < test.size; y++ {
+			for x := 0; x < test.size; x++ {
+				c := want.NRGBAAt(x, y)
+				if c.A = 0xff; !terminalOpaque(want.NRGBAAt(x, y)) {
+					c = color.NRGBA{}
+				}
+				if g := got.NRGBAAt(x, y); g != c {
+					t.Fatalf("Expected %v at %d,%d for size %d, got %v", c, x, y, test.size, g)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeSixel(t *testing.T) {
+	for _, opts := range [][]Option{{WithSize(20)}, {WithSize(13), WithShape(ShapeCircle)}} {
+		buf := new(bytes.Buffer)
+		if err := EncodeSixel(buf, []byte("alice"), opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data := buf.String()
+		if !strings.HasPrefix(data, "\x1bP0;1;0q") || !strings.HasSuffix(data, "\x1b\\") {
+			t.Fatalf("Expected a sixel sequence, got %.20q", data)
+		}
+
+		want, err := terminalImage([]byte("alice"), opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := decodeSixel(t, data)
+		if got.Bounds() != want.Bounds() {
+			t.Fatalf("Expected %v, got %v", want.Bounds(), got.Bounds())
+		}
+		b := want.Bounds()
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				w, g := want.NRGBAAt(x, y), got.NRGBAAt(x, y)
+				if terminalOpaque(w) != (g.A != 0) {
+					t.Fatalf("Expected opacity %v at %d,%d, got %v", terminalOpaque(w), x, y, g.A != 0)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeSixelRuns(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 2))
+	for x := 0; x < 8; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{R: 0xff, A: 0xff})
+	}
+	buf := new(bytes.Buffer)
+	if err := writeSixel(buf, img); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "\x1bP0;1;0q\"1;1;8;2#1;2;100;0;0#1!8@-\x1b\\"; buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+// Helper to decode the half blocks written by EncodeANSI, leaving the
+// default colors transparent
+func decodeANSI(t *testing.T, s string, size int) *image.NRGBA {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, size, size+1))
+	var fg, bg color.NRGBA
+	x, y := 0, 0
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, "\x1b["):
+			end := strings.IndexByte(s, 'm')
+			params := strings.Split(s[2:end], ";")
+			s = s[end+1:]
+			switch params[0] {
+			case "0":
+				fg, bg = color.NRGBA{}, color.NRGBA{}
+			case "49":
+				bg = color.NRGBA{}
+			case "38", "48":
+				var c [3]uint8
+				for i := range c {
+					v, err := strconv.Atoi(params[2+i])
+					if err != nil {
+						t.Fatalf("Invalid color %q", params)
+					}
+					c[i] = uint8(v)
+				}
+				if params[0] == "38" {
+					fg = color.NRGBA{c[0], c[1], c[2], 0xff}
+				} else {
+					bg = color.NRGBA{c[0], c[1], c[2], 0xff}
+				}
+			}
+			continue
+		case strings.HasPrefix(s, "\n"):
+			x, y = 0, y+2
+			s = s[1:]
+			continue
+		case strings.HasPrefix(s, "▀"):
+			img.SetNRGBA(x, y, fg)
+			img.SetNRGBA(x, y+1, bg)
+			s = s[len("▀"):]
+		case strings.HasPrefix(s, "▄"):
+			img.SetNRGBA(x, y, bg)
+			img.SetNRGBA(x, y+1, fg)
+			s = s[len("▄"):]
+		case strings.HasPrefix(s, " "):
+			img.SetNRGBA(x, y, bg)
+			img.SetNRGBA(x, y+1, bg)
+			s = s[1:]
+		default:
+			t.Fatalf("Unexpected text %.10q", s)
+		}
+		x++
+	}
+
+	return img
+}
+
+// Helper to decode the sixels written by EncodeSixel
+func decodeSixel(t *testing.T, s string) *image.NRGBA {
+	t.Helper()
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "\x1bP0;1;0q"), "\x1b\\")
+
+	// Helper to read a number
+	number := func() int {
+		n := 0
+		for len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+			n = n*10 + int(s[0]-'0')
+			s = s[1:]
+		}
+		return n
+	}
+
+	var img *image.NRGBA
+	palette := make(map[int]color.NRGBA)
+	current, x, y := 0, 0, 0
+	for len(s) > 0 {
+		c := s[0]
+		s = s[1:]
+		switch {
+		case c == '"':
+			number()
+			s = s[1:]
+			number()
+			s = s[1:]
+			w := number()
+			s = s[1:]
+			img = image.NewNRGBA(image.Rect(0, 0, w, number()))
+		case c == '#':
+			current = number()
+			if strings.HasPrefix(s, ";2;") {
+				s = s[3:]
+				var rgb [3]uint8
+				for i := range rgb {
+					if i > 0 {
+						s = s[1:]
+					}
+					rgb[i] = uint8(number() * 255 / 100)
+				}
+				palette[current] = color.NRGBA{rgb[0], rgb[1], rgb[2], 0xff}
+			}
+		case c == '$':
+			x = 0
+		case c == '-':
+			x, y = 0, y+6
+		case c == '!' || (c >= '?' && c <= '~'):
+			n := 1
+			if c == '!' {
+				n = number()
+				c, s = s[0], s[1:]
+			}
+			for range n {
+				for dy := 0; dy < 6; dy++ {
+					if (c-'?')&(1<<dy) != 0 {
+						img.SetNRGBA(x, y+dy, palette[current])
+					}
+				}
+				x++
+			}
+		default:
+			t.Fatalf("Unexpected sixel %q", c)
+		}
+	}
+
+	return img
+}
+
+func TestRenderText(t *testing.T) {
+	text := RenderText([]byte("alice"), 20, WithTheme(ThemeRobot))
+	buf := new(bytes.Buffer)
+	if err := EncodeANSI(buf, []byte("alice"), WithTheme(ThemeRobot), WithSize(20)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != buf.String() {
+		t.Error("Expected the block art of EncodeANSI")
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		plain := line
+		for strings.Contains(plain, "\x1b[") {
+			start := strings.Index(plain, "\x1b[")
+			plain = plain[:start] + plain[start+strings.IndexByte(plain[start:], 'm')+1:]
+		}
+		if n := utf8.RuneCountInString(plain); n != 20 {
+			t.Fatalf("Expected lines of 20 characters, got %d in %q", n, line)
+		}
+	}
+
+	// The size of the options is kept without a width
+	if RenderText([]byte("alice"), 0, WithSize(20), WithTheme(ThemeRobot)) != text {
+		t.Error("Expected a width of zero to keep the size of the options")
+	}
+}
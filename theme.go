@@ -0,0 +1,91 @@
+package monsterid
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"sync"
+)
+
+// Theme selects one of the built-in sets of part artwork. The parts of a hash
+// are selected from the theme's own parts, so every theme gives a different
+// but stable monster for the same hash.
+type Theme string
+
+const (
+	ThemeClassic Theme = "classic" // the original hand-drawn monsters
+	ThemeRobot   Theme = "robot"   // boxy machines with antennas and LED eyes
+	ThemeCute    Theme = "cute"    // round creatures with big shiny eyes
+)
+
+// themePacks lazily read the manifests of the themes in parts/<theme>/.
+var themePacks = map[Theme]func() (pack, error){
+	ThemeClassic: sync.OnceValues(func() (pack, error) { return pack{load: loadPart, counts: classicCounts()}, nil }),
+	ThemeRobot:   sync.OnceValues(func() (pack, error) { return readThemePack(ThemeRobot) }),
+	ThemeCute:    sync.OnceValues(func() (pack, error) { return readThemePack(ThemeCute) }),
+}
+
+// Themes returns the built-in themes.
+func Themes() []Theme {
+	return []Theme{ThemeClassic, ThemeRobot, ThemeCute}
+}
+
+// Helper to get the theme, ThemeClassic unless set
+func (o Options) theme() Theme {
+	if o.Theme == "" {
+		return ThemeClassic
+	}
+
+	return o.Theme
+}
+
+// Helper to get the parts of the theme. Unknown themes and themes with an
+// invalid manifest select parts like the classic theme, but fail to load them.
+func (t Theme) pack() pack {
+	read, ok := themePacks[t]
+	if !ok {
+		return failingPack(fmt.Errorf("unknown theme %q", t))
+	}
+	p, err := read()
+	if err != nil {
+		return failingPack(err)
+	}
+
+	return p
+}
+
+// Helper to get a pack selecting parts like the classic theme that fails to
+// load them with err
+func failingPack(err error) pack {
+	p, _ := themePacks[ThemeClassic]()
+	p.load = func(string, int) (*image.RGBA, error) { return nil, err }
+
+	return p
+}
+
+// Helper to get the number of parts of the classic theme. They are fixed
+// rather than counted from the files, so new artwork never changes existing
+// monsters.
+func classicCounts() partCounts {
+	return partCounts{"legs": 5, "hair": 5, "arms": 5, "body": 15, "eyes": 15, "mouth": 10}
+}
+
+// Helper to read the manifest of an embedded theme
+func readThemePack(t Theme) (pack, error) {
+	dir := path.Join("parts", string(t))
+	sub, err := fs.Sub(parts, dir)
+	if err != nil {
+		return pack{}, err
+	}
+
+	p, err := readPack(sub)
+	if err != nil {
+		return pack{}, err
+	}
+	p.load = func(fileName string, scale int) (*image.RGBA, error) {
+		return loadEmbeddedPart(dir, fileName, scale)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,159 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestThemes(t *testing.T) {
+	for _, theme := range Themes() {
+		counts := theme.pack().counts
+		for i := 0; i < 20; i++ {
+			hash := []byte(fmt.Sprintf("theme-%d", i))
+
+			d := Describe(hash, WithTheme(theme))
+			for _, part := range bodyParts {
+				if n := getPartNumber(&d, part); n < 1 || n > counts.count(part) {
+					t.Fatalf("Theme %s selected %s part %d out of %d", theme, part, n, counts.count(part))
+				}
+			}
+
+			if _, err := NewWithError(hash, WithTheme(theme)); err != nil {
+				t.Fatalf("Failed to render theme %s: %v", theme, err)
+			}
+		}
+	}
+}
+
+func TestThemeChangesImage(t *testing.T) {
+	hash := []byte("theme-image")
+
+	classic := New(hash).(*image.RGBA)
+	if def := New(hash, WithTheme(ThemeClassic)).(*image.RGBA); !bytes.Equal(classic.Pix, def.Pix) {
+		t.Error("ThemeClassic differs from the default theme")
+	}
+
+	for _, theme := range []Theme{ThemeRobot, ThemeCute} {
+		img := New(hash, WithTheme(theme)).(*image.RGBA)
+		if bytes.Equal(classic.Pix, img.Pix) {
+			t.Errorf("Theme %s rendered the classic monster", theme)
+		}
+		if again := New(hash, WithTheme(theme)).(*image.RGBA); !bytes.Equal(img.Pix, again.Pix) {
+			t.Errorf("Theme %s is not deterministic", theme)
+		}
+	}
+}
+
+func TestUnknownTheme(t *testing.T) {
+	_, err := NewWithError([]byte("theme-unknown"), WithTheme("plush"))
+	if err == nil || !strings.Contains(err.Error(), "plush") {
+		t.Errorf("Expected an unknown theme error, got %v", err)
+	}
+
+	// Unknown themes still select parts like the classic theme
+	hash := []byte("theme-unknown")
+	got, want := Describe(hash, WithTheme("plush")), Describe(hash)
+	if got.Theme != "plush" {
+		t.Errorf("Expected theme plush, got %q", got.Theme)
+	}
+	if got.Theme = ""; got != want {
+		t.Errorf("Expected the classic selection %+v, got %+v", want, got)
+	}
+}
+
+func TestThemeDescriptorRoundTrip(t *testing.T) {
+	for _, theme := range Themes() {
+		hash := []byte("theme-round-trip")
+		want := Describe(hash, WithTheme(theme))
+
+		buf := new(bytes.Buffer)
+		if err := Render(buf, hash, FormatPNG, WithTheme(theme), WithMetadata()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := ParsePNG(buf)
+		if err != nil {
+			t.Fatalf("Theme %s: unexpected error: %v", theme, err)
+		}
+		if got != want {
+			t.Errorf("Theme %s: expected %+v from the PNG, got %+v", theme, want, got)
+		}
+
+		data, _ := want.MarshalBinary()
+		if err := got.UnmarshalBinary(data); err != nil || got != want {
+			t.Errorf("Theme %s: expected %+v from binary, got %+v: %v", theme, want, got, err)
+		}
+		data, _ = json.Marshal(want)
+		if err := json.Unmarshal(data, &got); err != nil || got != want {
+			t.Errorf("Theme %s: expected %+v from JSON, got %+v: %v", theme, want, got, err)
+		}
+
+		img, err := FromParts(got)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(img.(*image.RGBA).Pix, New(hash, WithTheme(theme)).(*image.RGBA).Pix) {
+			t.Errorf("Theme %s: expected FromParts to draw the parts of the theme", theme)
+		}
+	}
+
+	// Parts are checked against the theme, which has fewer eyes than classic
+	d := Describe([]byte("theme-round-trip"), WithTheme(ThemeRobot))
+	d.Eyes = ThemeRobot.pack().counts.count("eyes") + 1
+	if data, _ := json.Marshal(d); json.Unmarshal(data, new(Descriptor)) == nil {
+		t.Errorf("Expected an error for eyes %d of theme %s", d.Eyes, d.Theme)
+	}
+	d.Theme = "plush"
+	if data, _ := json.Marshal(d); json.Unmarshal(data, new(Descriptor)) == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+func TestThemeManifests(t *testing.T) {
+	for _, theme := range []Theme{ThemeRobot, ThemeCute} {
+		sub, _ := fs.Sub(parts, path.Join("parts", string(theme)))
+		files, err := countPartFiles(sub)
+		if err != nil {
+			t.Fatalf("Failed to count the parts of %s: %v", theme, err)
+		}
+
+		for _, part := range bodyParts {
+			if theme.pack().counts.count(part) != files.count(part) {
+				t.Errorf("Manifest of %s declares %d %s parts, found %d", theme, theme.pack().counts.count(part), part, files.count(part))
+			}
+		}
+	}
+}
+
+func TestThemeID(t *testing.T) {
+	hash := []byte("theme-id")
+	if id := ID(hash, WithTheme(ThemeRobot)); !strings.HasPrefix(id, "robot-v1-") {
+		t.Errorf("Expected a robot ID, got %q", id)
+	}
+	if id := ID(hash, WithTheme(ThemeClassic)); id != ID(hash) {
+		t.Errorf("Expected the classic ID without prefix, got %q", id)
+	}
+}
+
+func TestThemeHighResolution(t *testing.T) {
+	hash := []byte("theme-hi-res")
+
+	// The robot theme has @4x parts, so a large avatar isn't upscaled
+	img := New(hash, WithTheme(ThemeRobot), WithSize(480), WithArtistic(false)).(*image.RGBA)
+	upscaled := scaleImage(New(hash, WithTheme(ThemeRobot), WithArtistic(false)).(*image.RGBA), 480, catmullRom)
+	if bytes.Equal(img.Pix, upscaled.Pix) {
+		t.Error("Expected the @4x parts to be drawn at 480 pixels")
+	}
+
+	if _, err := loadScaledPart(parts, "parts/robot", "body_1.png", 4); err != nil {
+		t.Errorf("Failed to load a @4x part: %v", err)
+	}
+	if img, err := loadPart("body_1.png", 4); err != nil || img.Bounds().Dx() != nativeSize {
+		t.Errorf("Expected classic parts to fall back to the native size, got %v", err)
+	}
+}
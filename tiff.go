@@ -0,0 +1,117 @@
+package monsterid
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+// TIFF creates a monsterid image based on the provided hash and returns it
+// encoded as TIFF.
+func TIFF(hash []byte, opts ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := EncodeTIFF(buf, hash, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeTIFF creates a monsterid image based on the provided hash and writes
+// it to w as an uncompressed baseline TIFF with an unassociated alpha channel.
+func EncodeTIFF(w io.Writer, hash []byte, opts ...Option) error {
+	return Render(w, hash, FormatTIFF, opts...)
+}
+
+// TIFF tag numbers and field types used by writeTIFF.
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffXResolution     = 282
+	tiffYResolution     = 283
+	tiffPlanarConfig    = 284
+	tiffResolutionUnit  = 296
+	tiffExtraSamples    = 338
+
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// tiffEntry is a single IFD entry.
+type tiffEntry struct {
+	Tag   uint16
+	Type  uint16
+	Count uint32
+	Value uint32 // value, little-endian shorts fit as is, or offset of the value
+}
+
+// Helper to write img as a little-endian, single strip RGBA TIFF
+func writeTIFF(w io.Writer, img *image.NRGBA) error {
+	b := img.Bounds()
+	width, height := uint32(b.Dx()), uint32(b.Dy())
+	pixSize := width * height * 4
+
+	// Layout: header, pixel data, IFD, then the out-of-line values
+	const headerSize = 8
+	const numEntries = 14
+	ifdOffset := headerSize + pixSize
+	ifdSize := uint32(2 + numEntries*12 + 4)
+	bitsOffset := ifdOffset + ifdSize
+	resOffset := bitsOffset + 4*2
+
+	entries := [numEntries]tiffEntry{
+		{tiffImageWidth, tiffLong, 1, width},
+		{tiffImageLength, tiffLong, 1, height},
+		{tiffBitsPerSample, tiffShort, 4, bitsOffset},
+		{tiffCompression, tiffShort, 1, 1}, // none
+		{tiffPhotometric, tiffShort, 1, 2}, // RGB
+		{tiffStripOffsets, tiffLong, 1, headerSize},
+		{tiffSamplesPerPixel, tiffShort, 1, 4},
+		{tiffRowsPerStrip, tiffLong, 1, height},
+		{tiffStripByteCounts, tiffLong, 1, pixSize},
+		{tiffXResolution, tiffRational, 1, resOffset},
+		{tiffYResolution, tiffRational, 1, resOffset},
+		{tiffPlanarConfig, tiffShort, 1, 1},   // chunky
+		{tiffResolutionUnit, tiffShort, 1, 2}, // inch
+		{tiffExtraSamples, tiffShort, 1, 2},   // unassociated alpha
+	}
+
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+	if _, err := bw.Write([]byte{'I', 'I', 42, 0}); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, le, ifdOffset); err != nil {
+		return err
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		off := img.PixOffset(b.Min.X, y)
+		if _, err := bw.Write(img.Pix[off : off+b.Dx()*4]); err != nil {
+			return err
+		}
+	}
+
+	values := []any{
+		uint16(numEntries), entries, uint32(0), // no next IFD
+		[4]uint16{8, 8, 8, 8},
+		[2]uint32{72, 1}, // 72 DPI
+	}
+	for _, v := range values {
+		if err := binary.Write(bw, le, v); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,55 @@
+package monsterid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"testing"
+)
+
+func TestTIFF(t *testing.T) {
+	hash := []byte("tiff-test")
+
+	data, err := TIFF(hash, WithSize(40))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte{'I', 'I', 42, 0}) {
+		t.Fatalf("Missing TIFF header: %v", data[:4])
+	}
+
+	// Walk the IFD and collect the tag values
+	le := binary.LittleEndian
+	ifd := le.Uint32(data[4:])
+	n := int(le.Uint16(data[ifd:]))
+	tags := make(map[uint16]uint32, n)
+	last := uint16(0)
+	for i := 0; i < n; i++ {
+		e := data[int(ifd)+2+i*12:]
+		tag := le.Uint16(e)
+		if tag <= last {
+			t.Errorf("Tag %d is not in ascending order", tag)
+		}
+		last = tag
+		tags[tag] = le.Uint32(e[8:])
+	}
+
+	if tags[tiffImageWidth] != 40 || tags[tiffImageLength] != 40 {
+		t.Errorf("Dimensions are %dx%d, want 40x40", tags[tiffImageWidth], tags[tiffImageLength])
+	}
+
+	// The strip holds the same pixels as the NRGBA render
+	want := toNRGBA(New(hash, WithSize(40)))
+	off, size := tags[tiffStripOffsets], tags[tiffStripByteCounts]
+	if !bytes.Equal(data[off:off+size], want.Pix) {
+		t.Error("Pixel data differs from New")
+	}
+}
+
+func TestToNRGBA(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	if toNRGBA(src) != src {
+		t.Error("NRGBA image is copied instead of returned as is")
+	}
+}
@@ -0,0 +1,61 @@
+package monsterid
+
+import (
+	"image"
+	"image/color"
+)
+
+// Tone restricts the colors of the rendered monster.
+type Tone int
+
+const (
+	ToneNone      Tone = iota // full color
+	ToneGreyscale             // shades of grey
+	ToneSepia                 // brownish tones of an old photograph
+	ToneDuotone               // blend between the two Options.Duotone colors
+)
+
+// Helper to get the effective tone, honoring the Greyscale option
+func (o Options) tone() Tone {
+	if o.Tone == ToneNone && o.Greyscale {
+		return ToneGreyscale
+	}
+
+	return o.Tone
+}
+
+// Helper to map every pixel of the composited monster to the tone
+func toneImage(img *image.RGBA, tone Tone, duotone [2]color.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		a := uint32(img.Pix[i+3])
+		if a == 0 {
+			continue
+		}
+
+		// Work on non-premultiplied values
+		r := float64(uint32(img.Pix[i+0])*255/a) / 255
+		g := float64(uint32(img.Pix[i+1])*255/a) / 255
+		b := float64(uint32(img.Pix[i+2])*255/a) / 255
+
+		switch tone {
+		case ToneSepia:
+			r, g, b = 0.393*r+0.769*g+0.189*b, 0.349*r+0.686*g+0.168*b, 0.272*r+0.534*g+0.131*b
+		case ToneDuotone:
+			l := 0.299*r + 0.587*g + 0.114*b
+			r = lerp(float64(duotone[0].R), float64(duotone[1].R), l) / 255
+			g = lerp(float64(duotone[0].G), float64(duotone[1].G), l) / 255
+			b = lerp(float64(duotone[0].B), float64(duotone[1].B), l) / 255
+		default:
+			continue
+		}
+
+		img.Pix[i+0] = clampUint8(r * float64(a))
+		img.Pix[i+1] = clampUint8(g * float64(a))
+		img.Pix[i+2] = clampUint8(b * float64(a))
+	}
+}
+
+// Helper for linear interpolation
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
@@ -0,0 +1,73 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSepiaTone(t *testing.T) {
+	img := New([]byte("sepia-test"), WithSepia(), WithTransparentBackground()).(*image.RGBA)
+
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			// Sepia keeps red >= green >= blue, allowing for rounding
+			if int(c.R)+2 < int(c.G) || int(c.G)+2 < int(c.B) {
+				t.Fatalf("Pixel (%d,%d) is not sepia: %v", x, y, c)
+			}
+		}
+	}
+}
+
+func TestDuotoneTone(t *testing.T) {
+	shadow := color.RGBA{R: 0, G: 0, B: 128, A: 255}
+	highlight := color.RGBA{R: 255, G: 255, B: 128, A: 255}
+	img := New([]byte("duotone-test"), WithDuotone(shadow, highlight), WithTransparentBackground()).(*image.RGBA)
+
+	for y := 0; y < nativeSize; y++ {
+		for x := 0; x < nativeSize; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			// Every pixel lies on the line between shadow and highlight
+			if c.B < 126 || c.B > 130 || absDiff(c.R, c.G) > 2 {
+				t.Fatalf("Pixel (%d,%d) is not between the duotone colors: %v", x, y, c)
+			}
+		}
+	}
+}
+
+func TestToneKeepsBackground(t *testing.T) {
+	bg := color.RGBA{R: 0, G: 200, B: 0, A: 255}
+
+	for _, tone := range []Tone{ToneSepia, ToneDuotone} {
+		img := New([]byte("tone-background"), WithTone(tone), WithBackground(bg)).(*image.RGBA)
+		if c := img.RGBAAt(0, 0); c != bg {
+			t.Errorf("Tone %d changed the background to %v", tone, c)
+		}
+	}
+}
+
+func TestGreyscaleOptionMatchesTone(t *testing.T) {
+	hash := []byte("greyscale-tone")
+
+	img1 := New(hash, WithGreyscale()).(*image.RGBA)
+	img2 := New(hash, WithTone(ToneGreyscale)).(*image.RGBA)
+
+	if !bytes.Equal(img1.Pix, img2.Pix) {
+		t.Error("Greyscale option and greyscale tone produced different images")
+	}
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,36 @@
+package monsterid
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Helper to crop img to the bounding box of its non-transparent pixels,
+// centering the result on a transparent square canvas if square is set
+func trimImage(img *image.RGBA, square bool) *image.RGBA {
+	b := img.Bounds()
+	box := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.Pix[img.PixOffset(x, y)+3] != 0 {
+				box = box.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if box.Empty() {
+		return img
+	}
+
+	w, h := box.Dx(), box.Dy()
+	canvas := image.Rect(0, 0, w, h)
+	if square {
+		side := max(w, h)
+		canvas = image.Rect(0, 0, side, side)
+	}
+
+	dst := image.NewRGBA(canvas)
+	at := image.Pt((canvas.Dx()-w)/2, (canvas.Dy()-h)/2)
+	draw.Draw(dst, image.Rectangle{Min: at, Max: at.Add(box.Size())}, img, box.Min, draw.Src)
+
+	return dst
+}
@@ -0,0 +1,49 @@
+package monsterid
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTrimTransparent(t *testing.T) {
+	img := New([]byte("trim-test"), WithTransparentBackground(), WithTrim(false)).(*image.RGBA)
+	b := img.Bounds()
+
+	if b.Dx() >= nativeSize && b.Dy() >= nativeSize {
+		t.Fatalf("Expected a cropped image, got %v", b)
+	}
+	if b.Min != (image.Point{}) {
+		t.Errorf("Expected the cropped image to start at the origin, got %v", b.Min)
+	}
+
+	// Every edge touches the monster
+	edges := map[string]bool{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y).A == 0 {
+				continue
+			}
+			edges["top"] = edges["top"] || y == b.Min.Y
+			edges["bottom"] = edges["bottom"] || y == b.Max.Y-1
+			edges["left"] = edges["left"] || x == b.Min.X
+			edges["right"] = edges["right"] || x == b.Max.X-1
+		}
+	}
+	if len(edges) != 4 || !edges["top"] || !edges["bottom"] || !edges["left"] || !edges["right"] {
+		t.Errorf("Cropped image has empty edges: %v", edges)
+	}
+}
+
+func TestTrimSquare(t *testing.T) {
+	img := New([]byte("trim-square"), WithTransparentBackground(), WithTrim(true))
+	if b := img.Bounds(); b.Dx() != b.Dy() {
+		t.Errorf("Expected a square image, got %v", b)
+	}
+}
+
+func TestTrimOpaqueBackground(t *testing.T) {
+	img := New([]byte("trim-opaque"), WithTrim(false))
+	if b := img.Bounds(); b.Dx() != nativeSize || b.Dy() != nativeSize {
+		t.Errorf("Opaque background should not be trimmed, got %v", b)
+	}
+}
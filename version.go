@@ -0,0 +1,61 @@
+package monsterid
+
+import (
+	"fmt"
+	"image"
+)
+
+// Version identifies a revision of the generation algorithm. The image for a
+// given hash and options never changes within a version, so existing avatars
+// stay the same until a caller opts into a newer version.
+type Version int
+
+const (
+	V1 Version = iota + 1 // original algorithm
+	V2                    // adds hash-derived horizontal mirroring
+	V3                    // seeds from 128 bits of the hash rather than 64
+
+	LatestVersion = V3
+)
+
+// Helper to get the algorithm version, V1 unless set
+func (o Options) version() Version {
+	if o.AlgorithmVersion == 0 {
+		return V1
+	}
+
+	return o.AlgorithmVersion
+}
+
+// Helper to check that the algorithm version is one of V1 to LatestVersion
+func (o Options) checkVersion() error {
+	if v := o.version(); v < V1 || v > LatestVersion {
+		return fmt.Errorf("monsterid: unknown algorithm version %d", v)
+	}
+
+	return nil
+}
+
+// NewV1 is like New but always uses the V1 algorithm, regardless of
+// Options.AlgorithmVersion, so the image never changes for a hash.
+func NewV1(hash []byte, opts ...Option) image.Image {
+	return New(hash, withVersion(opts, V1)...)
+}
+
+// NewV2 is like New but always uses the V2 algorithm, regardless of
+// Options.AlgorithmVersion, so the image never changes for a hash.
+func NewV2(hash []byte, opts ...Option) image.Image {
+	return New(hash, withVersion(opts, V2)...)
+}
+
+// NewV3 is like New but always uses the V3 algorithm, regardless of
+// Options.AlgorithmVersion, so the image never changes for a hash.
+func NewV3(hash []byte, opts ...Option) image.Image {
+	return New(hash, withVersion(opts, V3)...)
+}
+
+// Helper to append an algorithm version to opts without modifying the
+// caller's slice
+func withVersion(opts []Option, v Version) []Option {
+	return append(opts[:len(opts):len(opts)], WithAlgorithmVersion(v))
+}
@@ -0,0 +1,158 @@
+package monsterid
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"testing"
+)
+
+func TestV2KeepsV1Selection(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		hash := []byte{byte(i)}
+
+		v1 := Describe(hash)
+		v2 := Describe(hash, WithAlgorithmVersion(V2))
+		if v1.Mirrored {
+			t.Fatal("V1 selected a mirrored monster")
+		}
+
+		v2.Mirrored = false
+		if v1 != v2 {
+			t.Fatalf("V2 changed the V1 selection: %+v vs %+v", v1, v2)
+		}
+	}
+}
+
+func TestV2MirrorsSomeMonsters(t *testing.T) {
+	mirrored := 0
+	for i := 0; i < 100; i++ {
+		if Describe([]byte{byte(i)}, WithAlgorithmVersion(V2)).Mirrored {
+			mirrored++
+		}
+	}
+
+	if mirrored < 30 || mirrored > 70 {
+		t.Errorf("Expected about half of the monsters to be mirrored, got %d of 100", mirrored)
+	}
+}
+
+func TestPCGSeed(t *testing.T) {
+	hash := []byte("alice")
+	seed := hashSeed(hash, Options{})
+	for _, v := range []Version{V1, V2} {
+		if s1, s2 := pcgSeed(hash, Options{AlgorithmVersion: v}); s1 != seed || s2 != (seed>>1)|1 {
+			t.Errorf("Expected V%d to seed from the 64-bit seed, got %d, %d", v, s1, s2)
+		}
+	}
+
+	tests := []struct {
+		o      Options
+		s1, s2 uint64
+	}{
+		// The first 128 bits of SHA-256
+		{Options{AlgorithmVersion: V3}, 12610094898633693227, 12205783648577986330},
+		// splitmix64 of the seed of the HashFunc
+		{Options{AlgorithmVersion: V3, HashFunc: func([]byte) uint64 { return 1 }}, 10451216379200822465, 13757245211066428519},
+	}
+	for _, test := range tests {
+		if s1, s2 := pcgSeed(hash, test.o); s1 != test.s1 || s2 != test.s2 {
+			t.Errorf("Expected %d, %d, got %d, %d", test.s1, test.s2, s1, s2)
+		}
+	}
+}
+
+func TestV3Selection(t *testing.T) {
+	changed := 0
+	for i := 0; i < 100; i++ {
+		hash := []byte{byte(i)}
+		v3 := Describe(hash, WithAlgorithmVersion(V3))
+		if v3 != Describe(hash, WithAlgorithmVersion(V3)) {
+			t.Fatalf("Expected the same V3 selection for %d", i)
+		}
+		if v3 != Describe(hash, WithAlgorithmVersion(V2)) {
+			changed++
+		}
+	}
+
+	if changed < 90 {
+		t.Errorf("Expected V3 to reseed nearly every monster, changed %d of 100", changed)
+	}
+}
+
+func TestMirroredRendering(t *testing.T) {
+	d := Describe([]byte("mirror-test"))
+
+	img, err := FromParts(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	d.Mirrored = true
+	mirrored, err := FromParts(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mirrorImage(img.(*image.RGBA))
+	if !bytes.Equal(img.(*image.RGBA).Pix, mirrored.(*image.RGBA).Pix) {
+		t.Error("Mirrored monster is not the horizontal flip of the original")
+	}
+}
+
+func TestVersionedEntryPoints(t *testing.T) {
+	hash := []byte("versioned")
+
+	tests := []struct {
+		new     func([]byte, ...Option) image.Image
+		version Version
+	}{
+		{NewV1, V1},
+		{NewV2, V2},
+		{NewV3, V3},
+	}
+
+	for _, test := range tests {
+		want := New(hash, WithAlgorithmVersion(test.version)).(*image.RGBA)
+
+		// An explicit version in the options is overridden
+		opts := []Option{WithAlgorithmVersion(LatestVersion + 1)}
+		got := test.new(hash, opts...).(*image.RGBA)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("Entry point for V%d differs from New with WithAlgorithmVersion", test.version)
+		}
+		if len(opts) != 1 {
+			t.Error("Entry point modified the options")
+		}
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	hash := []byte("version-unknown")
+	for _, v := range []Version{-1, LatestVersion + 1} {
+		opt := WithAlgorithmVersion(v)
+		if _, err := NewContext(context.Background(), hash, opt); err == nil {
+			t.Errorf("Expected an error from NewContext for version %d", v)
+		}
+		if err := Render(io.Discard, hash, FormatPNG, opt); err == nil {
+			t.Errorf("Expected an error from Render for version %d", v)
+		}
+		if _, err := DescribeWithError(hash, opt); err == nil {
+			t.Errorf("Expected an error from DescribeWithError for version %d", v)
+		}
+	}
+
+	for _, v := range []Version{0, V1, LatestVersion} {
+		if _, err := DescribeWithError(hash, WithAlgorithmVersion(v)); err != nil {
+			t.Errorf("Unexpected error for version %d: %v", v, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Describe to panic for an unknown version")
+		}
+	}()
+	Describe(hash, WithAlgorithmVersion(LatestVersion+1))
+}
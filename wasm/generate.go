@@ -0,0 +1,124 @@
+// Command wasm exposes monsterid to JavaScript when built for WebAssembly, so
+// front-ends render monsters client-side with the same output as the server:
+//
+//	GOOS=js GOARCH=wasm go build -o monsterid.wasm ./wasm
+//
+// Once run with the wasm_exec.js of the Go distribution, it sets
+// generatePNG(hash, optionsJSON) on the global object, which returns the PNG
+// as a Uint8Array, or an Error. The options are a JSON object such as
+// {"size": 128, "theme": "robot", "shape": "circle"}, described by
+// jsonOptions.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/weavatar/monsterid"
+)
+
+// jsonOptions are the options accepted as JSON from JavaScript. Zero values
+// keep the defaults.
+type jsonOptions struct {
+	Size        int    `json:"size"`        // width and height in pixels, up to monsterid.DefaultMaxSize
+	Theme       string `json:"theme"`       // built-in theme, such as robot
+	Style       string `json:"style"`       // built-in style, such as identicon
+	Name        string `json:"name"`        // name whose initials the initials style draws
+	Background  string `json:"background"`  // hex RRGGBB or RRGGBBAA
+	Transparent bool   `json:"transparent"` // no background
+	Shape       string `json:"shape"`       // square, circle or rounded
+	Greyscale   bool   `json:"greyscale"`   // shades of grey
+	Version     int    `json:"version"`     // algorithm version, 1 if zero
+}
+
+// shapes are the values of jsonOptions.Shape.
+var shapes = map[string]monsterid.Shape{
+	"square":  monsterid.ShapeSquare,
+	"circle":  monsterid.ShapeCircle,
+	"rounded": monsterid.ShapeRounded,
+}
+
+// Helper to parse the JSON options, rejecting unknown fields so typos don't
+// go unnoticed
+func parseOptions(data string) ([]monsterid.Option, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+	var jo jsonOptions
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&jo); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	var opts []monsterid.Option
+	if jo.Size < 0 {
+		return nil, fmt.Errorf("invalid size %d", jo.Size)
+	}
+	if jo.Size > monsterid.DefaultMaxSize {
+		return nil, fmt.Errorf("size %d is larger than %d", jo.Size, monsterid.DefaultMaxSize)
+	}
+	if jo.Size > 0 {
+		opts = append(opts, monsterid.WithSize(jo.Size))
+	}
+	if jo.Theme != "" {
+		if !slices.Contains(monsterid.Themes(), monsterid.Theme(jo.Theme)) {
+			return nil, fmt.Errorf("unknown theme %q", jo.Theme)
+		}
+		opts = append(opts, monsterid.WithTheme(monsterid.Theme(jo.Theme)))
+	}
+	if jo.Style != "" {
+		if !slices.Contains(monsterid.Styles(), monsterid.Style(jo.Style)) {
+			return nil, fmt.Errorf("unknown style %q", jo.Style)
+		}
+		opts = append(opts, monsterid.WithStyle(monsterid.Style(jo.Style)))
+	}
+	if jo.Name != "" {
+		opts = append(opts, monsterid.WithInitials(jo.Name))
+	}
+	if jo.Background != "" {
+		c, err := monsterid.ParseColor(jo.Background)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, monsterid.WithBackground(c))
+	}
+	if jo.Transparent {
+		opts = append(opts, monsterid.WithTransparentBackground())
+	}
+	if jo.Shape != "" {
+		shape, ok := shapes[jo.Shape]
+		if !ok {
+			return nil, fmt.Errorf("unknown shape %q", jo.Shape)
+		}
+		opts = append(opts, monsterid.WithShape(shape))
+	}
+	if jo.Greyscale {
+		opts = append(opts, monsterid.WithGreyscale())
+	}
+	if jo.Version != 0 {
+		if jo.Version < int(monsterid.V1) || jo.Version > int(monsterid.LatestVersion) {
+			return nil, fmt.Errorf("unknown version %d", jo.Version)
+		}
+		opts = append(opts, monsterid.WithAlgorithmVersion(monsterid.Version(jo.Version)))
+	}
+
+	return opts, nil
+}
+
+// Helper to render the PNG of the monster for hash with the JSON options
+func generatePNG(hash, optionsJSON string) ([]byte, error) {
+	opts, err := parseOptions(optionsJSON)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := monsterid.Render(buf, []byte(hash), monsterid.FormatPNG, opts...); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/weavatar/monsterid"
+)
+
+func TestGeneratePNG(t *testing.T) {
+	tests := []struct {
+		options string
+		opts    []monsterid.Option
+	}{
+		{"", nil},
+		{"{}", nil},
+		{`{"size": 64, "theme": "robot"}`, []monsterid.Option{monsterid.WithSize(64), monsterid.WithTheme(monsterid.ThemeRobot)}},
+		{`{"background": "#ff000080", "shape": "circle", "greyscale": true}`, []monsterid.Option{
+			monsterid.WithBackground(color.RGBA{R: 0x80, A: 0x80}), monsterid.WithShape(monsterid.ShapeCircle), monsterid.WithGreyscale()}},
+		{`{"style": "identicon"}`, []monsterid.Option{monsterid.WithStyle(monsterid.StyleIdenticon)}},
+		{`{"style": "initials", "name": "Ada Lovelace"}`, []monsterid.Option{monsterid.WithStyle(monsterid.StyleInitials), monsterid.WithInitials("Ada Lovelace")}},
+		{`{"transparent": true, "version": 2, "size": 32}`, []monsterid.Option{
+			monsterid.WithSize(32), monsterid.WithTransparentBackground(), monsterid.WithAlgorithmVersion(monsterid.V2)}},
+	}
+
+	for _, test := range tests {
+		got, err := generatePNG("alice", test.options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := new(bytes.Buffer)
+		if err := monsterid.Render(want, []byte("alice"), monsterid.FormatPNG, test.opts...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, want.Bytes()) {
+			t.Errorf("Expected the PNG of the server for %s", test.options)
+		}
+	}
+}
+
+func TestGeneratePNGErrors(t *testing.T) {
+	for _, options := range []string{
+		"size",
+		`{"colour": "red"}`,
+		`{"size": -1}`,
+		`{"size": 1025}`,
+		`{"size": 1000000000}`,
+		`{"theme": "space"}`,
+		`{"style": "cubist"}`,
+		`{"background": "red"}`,
+		`{"shape": "star"}`,
+		`{"version": 9}`,
+	} {
+		if _, err := generatePNG("alice", options); err == nil {
+			t.Errorf("Expected an error for %s", options)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "wasm: build with GOOS=js GOARCH=wasm to use from JavaScript")
+	os.Exit(2)
+}
@@ -0,0 +1,35 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("generatePNG", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return jsError("generatePNG needs a hash string")
+		}
+		options := ""
+		if len(args) > 1 && args[1].Type() == js.TypeString {
+			options = args[1].String()
+		}
+
+		data, err := generatePNG(args[0].String(), options)
+		if err != nil {
+			return jsError(err.Error())
+		}
+		arr := js.Global().Get("Uint8Array").New(len(data))
+		js.CopyBytesToJS(arr, data)
+		return arr
+	}))
+
+	// Keep the functions callable
+	select {}
+}
+
+// Helper to create a JavaScript Error
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New("monsterid: " + msg)
+}
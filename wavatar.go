@@ -0,0 +1,32 @@
+package monsterid
+
+import (
+	"image"
+	"image/draw"
+	"math/rand/v2"
+)
+
+// wavatarDir is the embedded directory of the wavatar parts, drawn by
+// gen_parts.go.
+const wavatarDir = "parts/wavatar"
+
+// wavatarParts are the categories of wavatar parts in drawing order.
+var wavatarParts = []figurePart{
+	{"face", 10, true},
+	{"eyes", 10, false},
+	{"brows", 8, false},
+	{"mouth", 10, false},
+}
+
+// Helper to draw a Wavatar-style face: a round backdrop in a light color, a
+// face shape tinted in a second color, and eyes, brows and a mouth picked
+// from the embedded parts
+func drawWavatar(img *image.RGBA, r *rand.Rand, o Options) error {
+	backdrop := figureColor(r, o, 0.85)
+	face := figureColor(r, o, 0.6)
+
+	size := img.Rect.Dx()
+	draw.DrawMask(img, img.Rect, &image.Uniform{C: backdrop}, image.Point{}, shapeMask(ShapeCircle, size, 0), image.Point{}, draw.Over)
+
+	return drawFigureParts(img, r, o, wavatarDir, wavatarParts, face)
+}
@@ -0,0 +1,45 @@
+package monsterid
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWavatar(t *testing.T) {
+	opts := []Option{WithStyle(StyleWavatar), WithTransparentBackground()}
+	img := New([]byte("alice"), opts...).(*image.RGBA)
+	if !bytes.Equal(img.Pix, New([]byte("alice"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected the same wavatar for the same hash")
+	}
+	if bytes.Equal(img.Pix, New([]byte("bob"), opts...).(*image.RGBA).Pix) {
+		t.Error("Expected wavatars to differ by hash")
+	}
+
+	// The backdrop is round, so the corners stay transparent
+	if img.RGBAAt(0, 0).A != 0 {
+		t.Errorf("Expected a transparent corner, got %v", img.RGBAAt(0, 0))
+	}
+	if c := img.RGBAAt(nativeSize/2, 4); c.A != 0xff {
+		t.Errorf("Expected the backdrop at the top, got %v", c)
+	}
+
+	for _, size := range []int{48, 240, 600} {
+		img := New([]byte("alice"), append(opts, WithSize(size))...)
+		if img.Bounds().Dx() != size {
+			t.Errorf("Expected a %d pixel wavatar, got %v", size, img.Bounds())
+		}
+	}
+}
+
+func TestWavatarGreyscale(t *testing.T) {
+	for _, hash := range []string{"alice", "bob", "carol", "dave"} {
+		img := New([]byte(hash), WithStyle(StyleWavatar), WithGreyscale()).(*image.RGBA)
+		for i := 0; i < len(img.Pix); i += 4 {
+			if c := (color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}); c.R != c.G || c.G != c.B {
+				t.Fatalf("Expected a grey wavatar for %s, got %v", hash, c)
+			}
+		}
+	}
+}